@@ -0,0 +1,169 @@
+// cmd/catalog-lint/main.go
+//
+// catalog-lint проверяет specs компонентов каталога по схеме domain.SpecTypes
+// и печатает каждую строку, которая ей не соответствует. Код выхода 1 —
+// найдены ошибки.
+//
+//	catalog-lint                       # каталог из БД (DB_CONN_STR)
+//	catalog-lint -file components.json # JSON-массив в формате импорта
+//	catalog-lint -strict               # ещё и значения не в каноническом виде
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"StartupPCConfigurator/internal/domain"
+
+	_ "github.com/lib/pq"
+)
+
+// row — компонент в объёме, нужном для проверки
+type row struct {
+	ID       int             `json:"id"`
+	Name     string          `json:"name"`
+	Category string          `json:"category"`
+	Specs    json.RawMessage `json:"specs"`
+}
+
+func main() {
+	dsn := flag.String("dsn", os.Getenv("DB_CONN_STR"), "PostgreSQL connection string")
+	file := flag.String("file", "", "lint a JSON array of components instead of the database")
+	category := flag.String("category", "", "lint only this category")
+	withDeleted := flag.Bool("deleted", false, "include soft-deleted components")
+	strict := flag.Bool("strict", false, "also report specs that are valid but not in canonical form")
+	flag.Parse()
+
+	var (
+		rows []row
+		err  error
+	)
+	if *file != "" {
+		rows, err = loadFile(*file)
+	} else {
+		if *dsn == "" {
+			log.Fatal("DB_CONN_STR не задан (или передайте -dsn / -file)")
+		}
+		rows, err = loadDB(*dsn, *withDeleted)
+	}
+	if err != nil {
+		log.Fatalf("load components: %v", err)
+	}
+
+	checked, bad, notes := 0, 0, 0
+	for _, r := range rows {
+		cat := strings.ToLower(r.Category)
+		if *category != "" && cat != strings.ToLower(*category) {
+			continue
+		}
+		checked++
+		for _, e := range lintRow(cat, r.Specs) {
+			fmt.Printf("ERROR %s\t%s\t%s\n", rowLabel(r), cat, e)
+			bad++
+		}
+		if *strict {
+			for _, n := range nonCanonical(cat, r.Specs) {
+				fmt.Printf("NOTE  %s\t%s\t%s\n", rowLabel(r), cat, n)
+				notes++
+			}
+		}
+	}
+
+	fmt.Printf("checked %d components: %d errors", checked, bad)
+	if *strict {
+		fmt.Printf(", %d non-canonical values", notes)
+	}
+	fmt.Println()
+	if bad > 0 {
+		os.Exit(1)
+	}
+}
+
+func lintRow(category string, specs json.RawMessage) []domain.SpecError {
+	if !domain.IsValidCategory(category) {
+		return []domain.SpecError{{Key: "category", Message: fmt.Sprintf("unknown category %q", category)}}
+	}
+	_, errs := domain.DecodeSpecs(domain.ComponentCategory(category), specs)
+	return errs
+}
+
+// nonCanonical описывает значения, которые NormalizeSpecs записал бы иначе
+// (например, "65 W" вместо 65 или "am4" вместо "AM4")
+func nonCanonical(category string, raw json.RawMessage) []string {
+	var specs map[string]interface{}
+	if err := json.Unmarshal(raw, &specs); err != nil {
+		return nil
+	}
+	norm, errs := domain.NormalizeSpecs(domain.ComponentCategory(category), specs)
+	failed := map[string]bool{}
+	for _, e := range errs {
+		failed[e.Key] = true
+	}
+	var out []string
+	for key, val := range specs {
+		if failed[key] || reflect.DeepEqual(val, norm[key]) {
+			continue
+		}
+		was, _ := json.Marshal(val)
+		want, _ := json.Marshal(norm[key])
+		out = append(out, fmt.Sprintf("%s: %s → %s", key, was, want))
+	}
+	sort.Strings(out)
+	return out
+}
+
+func rowLabel(r row) string {
+	if r.ID == 0 {
+		return fmt.Sprintf("%q", r.Name)
+	}
+	return fmt.Sprintf("#%d %q", r.ID, r.Name)
+}
+
+func loadFile(path string) ([]row, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var rows []row
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func loadDB(dsn string, withDeleted bool) ([]row, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	query := `SELECT id, name, category, specs FROM components`
+	if !withDeleted {
+		query += ` WHERE deleted_at IS NULL`
+	}
+	query += ` ORDER BY category, id`
+
+	rs, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rs.Close()
+
+	var rows []row
+	for rs.Next() {
+		var r row
+		if err := rs.Scan(&r.ID, &r.Name, &r.Category, &r.Specs); err != nil {
+			return nil, err
+		}
+		rows = append(rows, r)
+	}
+	return rows, rs.Err()
+}
@@ -10,6 +10,7 @@ import (
 	"StartupPCConfigurator/internal/config/handlers"
 	"StartupPCConfigurator/internal/config/repository"
 	"StartupPCConfigurator/internal/config/usecase"
+	"StartupPCConfigurator/internal/config/usecase/rules"
 	"StartupPCConfigurator/pkg/middleware"
 
 	"github.com/gin-gonic/gin"
@@ -37,12 +38,28 @@ func main() {
 	}
 	defer db.Close()
 
+	// 1.1 Правила совместимости: по умолчанию встроенные, можно подменить файлом
+	if path := os.Getenv("COMPAT_RULES_PATH"); path != "" {
+		rs, err := rules.LoadCompatRules(path)
+		if err != nil {
+			log.Fatalf("Failed to load compat rules from %s: %v", path, err)
+		}
+		rules.CompatRules = rs
+		log.Printf("Loaded %d compat rules from %s", len(rs), path)
+	}
+
 	// 2. Создаём репозиторий
 	repo := repository.NewConfigRepository(db)
 
 	// 3. Создаём уровень бизнес-логики (usecase)
 	service := usecase.NewConfigService(repo)
 
+	// 3.1 Правила сценариев живут в БД; при первом запуске туда пишутся встроенные.
+	// Если БД недоступна — работаем на встроенных и перечитаем позже.
+	if err := service.InitScenarios(); err != nil {
+		log.Printf("Failed to load scenario rules, using built-in: %v", err)
+	}
+
 	// 4. Создаём Gin-роутер
 	r := gin.Default()
 
@@ -57,18 +74,54 @@ func main() {
 
 	// 6. Публичные ручки
 	r.GET("/components", h.GetComponents)
+	r.GET("/components/:id/images", h.GetComponentImages)
 	r.POST("/compatible", h.GetCompatibleComponentsMulti)
 	r.GET("/usecases", h.ListUseCases)
 	r.GET("/usecase/:name", h.GetUseCaseBuild)
+	r.POST("/usecase/:name/generate", h.GenerateUseCaseConfigs)
+	r.POST("/generate", h.GenerateConfigs)
+	r.POST("/optimize", h.OptimizeBuild)
 	r.GET("/brands", h.GetBrands)
+	r.POST("/check", h.CheckConfig)
+	r.GET("/shared/:slug", h.GetSharedConfig)
 
 	// 7. Защищённые ручки
 	api := r.Group("/", auth)
 	{
 		api.POST("/newconfig", h.CreateConfig)
 		api.GET("/userconf", h.GetUserConfigs)
+		api.GET("/userconf/:configId/check", h.CheckUserConfig)
 		api.PUT("/newconfig/:configId", h.UpdateConfig)
 		api.DELETE("/newconfig/:configId", h.DeleteConfig)
+
+		api.POST("/userconf/:configId/shares", h.CreateShare)
+		api.GET("/userconf/:configId/shares", h.ListShares)
+		api.DELETE("/shares/:slug", h.RevokeShare)
+		api.POST("/shared/:slug/fork", h.ForkSharedConfig)
+
+		api.GET("/userconf/:configId/revisions", h.ListRevisions)
+		api.GET("/userconf/:configId/revisions/:rev", h.GetRevision)
+		api.POST("/userconf/:configId/revisions/:rev/restore", h.RestoreRevision)
+		api.GET("/userconf/:configId/diff", h.DiffRevisions)
+	}
+
+	// 8. Администрирование (только суперпользователи)
+	admin := r.Group("/admin", auth, middleware.RequireSuperuser(service.IsSuperuser))
+	{
+		admin.GET("/scenarios", h.ListScenarios)
+		admin.POST("/scenarios", h.CreateScenario)
+		admin.PUT("/scenarios/:name", h.UpdateScenario)
+		admin.GET("/scenarios/:name/versions", h.GetScenarioVersions)
+		admin.POST("/scenarios/:name/rollback", h.RollbackScenario)
+
+		admin.POST("/components", h.CreateComponent)
+		admin.POST("/components/import", h.ImportComponents)
+		admin.PUT("/components/:id", h.UpdateComponent)
+		admin.DELETE("/components/:id", h.DeleteComponent)
+
+		admin.GET("/spec-proposals", h.ListSpecProposals)
+		admin.POST("/spec-proposals/:id/approve", h.ApproveSpecProposal)
+		admin.POST("/spec-proposals/:id/reject", h.RejectSpecProposal)
 	}
 
 	// 7. Запуск сервера на порте (например, 8081)
@@ -58,6 +58,8 @@ func main() {
 	r.POST("/config/compatible", reverseProxyPath(configURL, "/compatible"))
 	r.GET("/config/usecases", reverseProxyPath(configURL, "/usecases"))
 	r.POST("/config/generate", reverseProxyPath(configURL, "/generate"))
+	r.POST("/config/optimize", reverseProxyPath(configURL, "/optimize"))
+	r.POST("/config/check", reverseProxyPath(configURL, "/check"))
 
 	r.GET("/config/usecase/:name", func(c *gin.Context) {
 		c.Request.URL.Path = "/usecase/" + c.Param("name")
@@ -68,14 +70,45 @@ func main() {
 		proxyKeepPath(configURL)(c)
 	})
 	r.GET("/config/brands", reverseProxyPath(configURL, "/brands"))
+	r.GET("/config/shared/:slug", func(c *gin.Context) {
+		c.Request.URL.Path = "/shared/" + c.Param("slug")
+		proxyKeepPath(configURL)(c)
+	})
+	r.GET("/config/components/:id/images", func(c *gin.Context) {
+		c.Request.URL.Path = "/components/" + c.Param("id") + "/images"
+		proxyKeepPath(configURL)(c)
+	})
 
 	// ---------- CONFIG – защищённые (JWT) ----------------------------------
 	cfgSec := r.Group("/config", middleware.AuthMiddleware(jwtSecret))
 	{
 		cfgSec.POST("/newconfig", proxyStripPrefix(configURL, "/config"))
 		cfgSec.GET("/userconf", proxyStripPrefix(configURL, "/config"))
+		cfgSec.GET("/userconf/:configId/check", proxyStripPrefix(configURL, "/config"))
 		cfgSec.PUT("/newconfig/:configId", proxyStripPrefix(configURL, "/config"))
 		cfgSec.DELETE("/newconfig/:configId", proxyStripPrefix(configURL, "/config"))
+		cfgSec.POST("/userconf/:configId/shares", proxyStripPrefix(configURL, "/config"))
+		cfgSec.GET("/userconf/:configId/shares", proxyStripPrefix(configURL, "/config"))
+		cfgSec.DELETE("/shares/:slug", proxyStripPrefix(configURL, "/config"))
+		cfgSec.POST("/shared/:slug/fork", proxyStripPrefix(configURL, "/config"))
+		cfgSec.GET("/userconf/:configId/revisions", proxyStripPrefix(configURL, "/config"))
+		cfgSec.GET("/userconf/:configId/revisions/:rev", proxyStripPrefix(configURL, "/config"))
+		cfgSec.POST("/userconf/:configId/revisions/:rev/restore", proxyStripPrefix(configURL, "/config"))
+		cfgSec.GET("/userconf/:configId/diff", proxyStripPrefix(configURL, "/config"))
+
+		// админка сценариев; права суперпользователя проверяет config-service
+		cfgSec.GET("/admin/scenarios", proxyStripPrefix(configURL, "/config"))
+		cfgSec.POST("/admin/scenarios", proxyStripPrefix(configURL, "/config"))
+		cfgSec.PUT("/admin/scenarios/:name", proxyStripPrefix(configURL, "/config"))
+		cfgSec.GET("/admin/scenarios/:name/versions", proxyStripPrefix(configURL, "/config"))
+		cfgSec.POST("/admin/scenarios/:name/rollback", proxyStripPrefix(configURL, "/config"))
+		cfgSec.POST("/admin/components", proxyStripPrefix(configURL, "/config"))
+		cfgSec.POST("/admin/components/import", proxyStripPrefix(configURL, "/config"))
+		cfgSec.PUT("/admin/components/:id", proxyStripPrefix(configURL, "/config"))
+		cfgSec.DELETE("/admin/components/:id", proxyStripPrefix(configURL, "/config"))
+		cfgSec.GET("/admin/spec-proposals", proxyStripPrefix(configURL, "/config"))
+		cfgSec.POST("/admin/spec-proposals/:id/approve", proxyStripPrefix(configURL, "/config"))
+		cfgSec.POST("/admin/spec-proposals/:id/reject", proxyStripPrefix(configURL, "/config"))
 	}
 
 	// ---------- AGGREGATOR – защищённые ------------------------------------
@@ -86,6 +119,8 @@ func main() {
 	{
 		offers.GET("", proxyKeepPath(agrURL))
 		offers.POST("/import", proxyKeepPath(agrURL))
+		offers.GET("/imports", proxyKeepPath(agrURL))
+		offers.GET("/imports/:id", proxyKeepPath(agrURL))
 	}
 
 	// **НОВЫЙ БЛОК**: /subscriptions → Notifications-service
@@ -102,12 +137,25 @@ func main() {
 		subs.DELETE("/:componentId", proxyKeepPath(notifURL))
 	}
 
+	// /configuration-watches → Notifications-service: слежение за стоимостью сборки
+	watches := r.Group("/configuration-watches", middleware.AuthMiddleware(jwtSecret))
+	{
+		watches.POST("", proxyKeepPath(notifURL))
+		watches.GET("", proxyKeepPath(notifURL))
+		watches.DELETE("/:configId", proxyKeepPath(notifURL))
+	}
+
+	// ---------- NOTIFICATIONS – ссылки отписки из писем (без JWT, токен в ссылке)
+	r.GET("/unsubscribe", proxyKeepPath(notifURL))
+	r.POST("/unsubscribe", proxyKeepPath(notifURL))
+
 	// ---------- NOTIFICATIONS – защищённые ---------------------------------
 	notifications := r.Group("/notifications", middleware.AuthMiddleware(jwtSecret))
 	{
 		// этот маршрут поймает запрос GET /notifications
 		notifications.Any("", proxyKeepPath(notifURL))
-		// а этот — все вложенные, например /notifications/count и /notifications/{id}/read
+		// а этот — все вложенные, например /notifications/count и /notifications/{id}/read,
+		// и поток /notifications/stream (text/event-stream ReverseProxy отдаёт без буферизации)
 		notifications.Any("/*proxyPath", proxyKeepPath(notifURL))
 	}
 
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"time"
+)
+
+// pollInterval — как часто спрашивать статус задачи
+const pollInterval = 2 * time.Second
+
+// importJob — ответ GET /offers/imports/:id (поля, которые печатаем)
+type importJob struct {
+	ID            int64  `json:"id"`
+	Status        string `json:"status"`
+	RowsTotal     int    `json:"rowsTotal"`
+	RowsProcessed int    `json:"rowsProcessed"`
+	Imported      int    `json:"imported"`
+	Skipped       int    `json:"skipped"`
+	Error         string `json:"error"`
+	Report        *struct {
+		UnknownShops      []string   `json:"unknownShops"`
+		UnknownComponents []string   `json:"unknownComponents"`
+		PriceChanges      []struct{} `json:"priceChanges"`
+		SkippedRows       []struct {
+			Row    int    `json:"row"`
+			Reason string `json:"reason"`
+		} `json:"skippedRows"`
+	} `json:"report"`
+}
+
+func (j importJob) finished() bool { return j.Status == "done" || j.Status == "failed" }
+
+// jobWaiter опрашивает aggregator, пока задачи импорта не завершатся
+type jobWaiter struct {
+	api    string
+	token  string
+	client *http.Client
+}
+
+// waitAll ждёт все задачи и печатает итог по каждой. Возвращает число
+// неуспешных (в том числе не дождавшихся) импортов.
+func (w *jobWaiter) waitAll(msgs []ImportMsg, timeout time.Duration, out io.Writer) int {
+	deadline := time.Now().Add(timeout)
+	failed := 0
+	for _, m := range msgs {
+		name := filepath.Base(m.FilePath)
+		job, err := w.wait(m.Ref, deadline)
+		if err != nil {
+			fmt.Fprintf(out, "%s: %v\n", name, err)
+			failed++
+			continue
+		}
+		printJob(out, name, job)
+		if job.Status != "done" {
+			failed++
+		}
+	}
+	return failed
+}
+
+func (w *jobWaiter) wait(ref string, deadline time.Time) (importJob, error) {
+	var last importJob
+	for {
+		job, found, err := w.get(ref)
+		switch {
+		case err != nil:
+			return last, err
+		case found && job.finished():
+			return job, nil
+		case found:
+			last = job
+		}
+		if time.Now().After(deadline) {
+			if !found {
+				return last, fmt.Errorf("job %s not started before timeout (still queued?)", ref)
+			}
+			return last, fmt.Errorf("job %d still %s after timeout: %d/%d rows", last.ID, last.Status, last.RowsProcessed, last.RowsTotal)
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+// get запрашивает задачу по ref; found = false — aggregator её ещё не взял из очереди
+func (w *jobWaiter) get(ref string) (importJob, bool, error) {
+	req, err := http.NewRequest(http.MethodGet, w.api+"/offers/imports/"+ref, nil)
+	if err != nil {
+		return importJob{}, false, err
+	}
+	if w.token != "" {
+		req.Header.Set("Authorization", "Bearer "+w.token)
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return importJob{}, false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return importJob{}, false, nil
+	default:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return importJob{}, false, fmt.Errorf("GET job %s: %s: %s", ref, resp.Status, body)
+	}
+	var job importJob
+	if err := json.NewDecoder(resp.Body).Decode(&job); err != nil {
+		return importJob{}, false, err
+	}
+	return job, true, nil
+}
+
+func printJob(out io.Writer, name string, j importJob) {
+	fmt.Fprintf(out, "%s: job %d %s, %d rows, %d imported, %d skipped\n",
+		name, j.ID, j.Status, j.RowsTotal, j.Imported, j.Skipped)
+	if j.Error != "" {
+		fmt.Fprintf(out, "  error: %s\n", j.Error)
+	}
+	if r := j.Report; r != nil {
+		if len(r.PriceChanges) > 0 {
+			fmt.Fprintf(out, "  price changes: %d\n", len(r.PriceChanges))
+		}
+		if len(r.UnknownShops) > 0 {
+			fmt.Fprintf(out, "  unknown shops: %v\n", r.UnknownShops)
+		}
+		if len(r.UnknownComponents) > 0 {
+			fmt.Fprintf(out, "  unknown components: %v\n", r.UnknownComponents)
+		}
+		for _, s := range r.SkippedRows {
+			fmt.Fprintf(out, "  row %d: %s\n", s.Row, s.Reason)
+		}
+	}
+}
@@ -2,27 +2,38 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 	"time"
 
 	"database/sql"
 	"github.com/gin-gonic/gin"
 	"github.com/go-redis/redis/v8"
 	_ "github.com/lib/pq"
-	"github.com/streadway/amqp"
 
+	"StartupPCConfigurator/internal/notifications/email"
 	"StartupPCConfigurator/internal/notifications/handlers"
+	"StartupPCConfigurator/internal/notifications/push"
 	"StartupPCConfigurator/internal/notifications/rabbitmq"
 	"StartupPCConfigurator/internal/notifications/repository"
 	"StartupPCConfigurator/internal/notifications/usecase"
 	"StartupPCConfigurator/pkg/middleware"
+	mq "StartupPCConfigurator/pkg/rabbitmq"
 )
 
 func main() {
 	// Logger
 	logger := log.Default()
 
+	// SIGINT/SIGTERM: перестаём брать сообщения, дожидаемся начатых и гасим HTTP
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// === 1. Configuration from env ===
 	dbConnStr := os.Getenv("DB_CONN_STR")
 	for i := 0; i < 3; i++ {
@@ -57,6 +68,23 @@ func main() {
 		httpPort = "8004"
 	}
 
+	// Email: без SMTP_ADDR письма не отправляются, настройки всё равно сохраняются.
+	// Локально — Mailpit: SMTP_ADDR=localhost:1025, письма на http://localhost:8025
+	smtpAddr := os.Getenv("SMTP_ADDR")
+	smtpFrom := os.Getenv("SMTP_FROM")
+	if smtpFrom == "" {
+		smtpFrom = "PC Configurator <noreply@localhost>"
+	}
+	// публичный адрес gateway для ссылок отписки в письмах
+	publicURL := os.Getenv("PUBLIC_URL")
+	if publicURL == "" {
+		publicURL = "http://localhost:8080"
+	}
+	linkSecret := os.Getenv("EMAIL_LINK_SECRET")
+	if linkSecret == "" {
+		linkSecret = jwtSecret
+	}
+
 	// === 2. Connect to Postgres ===
 	db, err := sql.Open("postgres", dbConnStr)
 	if err != nil {
@@ -76,38 +104,61 @@ func main() {
 		logger.Fatalf("Failed to ping Redis: %v", err)
 	}
 
-	// === 4. Connect to RabbitMQ ===
-	var conn *amqp.Connection
-	for i := 0; i < 5; i++ {
-		conn, err = amqp.Dial(rabbitURL)
-		if err == nil {
-			break
-		}
-		logger.Println("RabbitMQ connecting, retrying in 2s...")
-		time.Sleep(2 * time.Second)
-	}
+	// === 4. Connect to RabbitMQ (reconnects automatically) ===
+	conn, err := mq.Dial(ctx, rabbitURL, logger)
 	if err != nil {
 		logger.Fatalf("Failed to connect to RabbitMQ: %v", err)
 	}
 	defer conn.Close()
-	ch, err := conn.Channel()
-	if err != nil {
-		logger.Fatalf("Failed to open RabbitMQ channel: %v", err)
-	}
-	defer ch.Close()
 
 	// === 5. Init Repository, Cache, UseCase, Handler ===
 	notifRepo := repository.NewNotificationRepository(db)
 	notifCache := repository.NewNotificationCache(rdb)
-	notifUC := usecase.NewNotificationUseCase(notifRepo, notifCache, logger)
+	notifEvents := repository.NewNotificationEvents(rdb, logger)
+	notifUC := usecase.NewNotificationUseCase(notifRepo, notifCache, notifEvents, logger)
 	notifHandler := handlers.NewHandler(notifUC)
 
-	// === 6. Start RabbitMQ consumer ===
+	// события для GET /notifications/stream со всех экземпляров через Redis pub/sub;
+	// на остановке hub закрывает потоки, чтобы Shutdown их не ждал
+	hub := push.NewHub(notifEvents, logger)
+	go hub.Run(ctx)
+	streamHandler := handlers.NewStreamHandler(notifUC, hub)
+
+	links := email.NewLinks(linkSecret, publicURL)
+	unsubscribeHandler := handlers.NewUnsubscribeLinkHandler(notifUC, links)
+
+	// === 6. Start RabbitMQ consumers: price.changed, availability.changed ===
+	var consumers sync.WaitGroup
+	consumers.Add(2)
 	go func() {
-		if err := rabbitmq.StartNotificationsConsumer(ch, notifUC, logger); err != nil {
-			logger.Fatalf("Notifications consumer error: %v", err)
-		}
+		defer consumers.Done()
+		rabbitmq.StartNotificationsConsumer(ctx, conn, notifUC, logger)
 	}()
+	go func() {
+		defer consumers.Done()
+		rabbitmq.StartAvailabilityConsumer(ctx, conn, notifUC, logger)
+	}()
+
+	// === 6.1. Email: мгновенные письма и сводки ===
+	if smtpAddr != "" {
+		sender, err := email.NewSMTPSender(email.SMTPConfig{
+			Addr:     smtpAddr,
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     smtpFrom,
+		})
+		if err != nil {
+			logger.Fatalf("Invalid SMTP config: %v", err)
+		}
+		dispatcher := usecase.NewEmailDispatcher(notifRepo, sender, links, logger)
+		consumers.Add(1)
+		go func() {
+			defer consumers.Done()
+			dispatcher.Run(ctx)
+		}()
+	} else {
+		logger.Println("SMTP_ADDR is not set, email notifications are disabled")
+	}
 
 	// === 7. HTTP Server (Gin) ===
 	r := gin.Default()
@@ -119,18 +170,46 @@ func main() {
 		n.GET("/count", notifHandler.UnreadCount)
 		n.GET("", notifHandler.List)
 		n.POST("/:id/read", notifHandler.MarkRead)
+		n.GET("/stream", streamHandler.Stream)
+		n.GET("/email-settings", notifHandler.GetEmailSettings)
+		n.PUT("/email-settings", notifHandler.UpdateEmailSettings)
 	}
 
 	subs := r.Group("/subscriptions")
 	subs.Use(middleware.AuthMiddleware(jwtSecret))
 	{
 		subs.POST("", notifHandler.Subscribe)
+		subs.GET("", notifHandler.ListSubscriptions)
 		subs.DELETE("/:componentId", notifHandler.Unsubscribe)
 		handlers.NewSubHandler(subs, notifUC)
 	}
 
-	logger.Printf("Notifications service listening on :%s", httpPort)
-	if err := r.Run(":" + httpPort); err != nil {
-		logger.Fatalf("Failed to run HTTP server: %v", err)
+	watches := r.Group("/configuration-watches")
+	watches.Use(middleware.AuthMiddleware(jwtSecret))
+	{
+		watches.POST("", notifHandler.WatchConfiguration)
+		watches.GET("", notifHandler.ListConfigurationWatches)
+		watches.DELETE("/:configId", notifHandler.UnwatchConfiguration)
+	}
+
+	// Public: ссылки отписки из писем, авторизация — подписанный токен
+	r.GET("/unsubscribe", unsubscribeHandler.Confirm)
+	r.POST("/unsubscribe", unsubscribeHandler.Unsubscribe)
+
+	srv := &http.Server{Addr: ":" + httpPort, Handler: r}
+	go func() {
+		logger.Printf("Notifications service listening on :%s", httpPort)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Fatalf("Failed to run HTTP server: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	logger.Println("Shutting down...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logger.Printf("HTTP shutdown: %v", err)
 	}
+	consumers.Wait()
 }
@@ -0,0 +1,111 @@
+// cmd/shop-profile-lint/main.go
+//
+// shop-profile-lint проверяет профили парсинга магазинов: разбирает файл
+// профилей, прогоняет селекторы по сохранённым страницам (samples) и
+// сверяет результат с expect. Код выхода 1 — найдены ошибки.
+//
+//	shop-profile-lint                          # встроенный shops.yaml
+//	shop-profile-lint -file my-shops.yaml      # свой файл профилей
+//	shop-profile-lint -db -samples ./testdata  # ещё и shops.parser_profile из БД
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"StartupPCConfigurator/internal/aggregator/parser/generic"
+
+	_ "github.com/lib/pq"
+)
+
+const defaultFile = "internal/aggregator/parser/generic/shops.yaml"
+
+func main() {
+	file := flag.String("file", defaultFile, "YAML/JSON file with shop profiles")
+	withDB := flag.Bool("db", false, "also lint shops.parser_profile from the database")
+	dsn := flag.String("dsn", os.Getenv("DB_CONN_STR"), "PostgreSQL connection string")
+	samples := flag.String("samples", "", "base directory for sample files (default: directory of -file)")
+	flag.Parse()
+
+	baseDir := *samples
+	if baseDir == "" {
+		baseDir = filepath.Dir(*file)
+	}
+
+	profiles, err := generic.LoadProfiles(*file)
+	if err != nil {
+		fmt.Printf("ERROR %s: %v\n", *file, err)
+		os.Exit(1)
+	}
+
+	bad := 0
+	if *withDB {
+		if *dsn == "" {
+			log.Fatal("DB_CONN_STR не задан (или передайте -dsn)")
+		}
+		fromDB, errs := loadDB(*dsn)
+		for _, e := range errs {
+			fmt.Printf("ERROR %v\n", e)
+			bad++
+		}
+		profiles = append(profiles, fromDB...)
+	}
+
+	nSamples := 0
+	for _, p := range profiles {
+		nSamples += len(p.Samples)
+		if len(p.Samples) == 0 {
+			fmt.Printf("NOTE  %s: no samples, selectors are not checked\n", p.Code)
+		}
+		for _, e := range p.CheckSamples(baseDir) {
+			fmt.Printf("ERROR %v\n", e)
+			bad++
+		}
+	}
+
+	fmt.Printf("checked %d profiles, %d samples: %d errors\n", len(profiles), nSamples, bad)
+	if bad > 0 {
+		os.Exit(1)
+	}
+}
+
+// loadDB читает заполненные shops.parser_profile
+func loadDB(dsn string) ([]generic.Profile, []error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, []error{err}
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`SELECT code, parser_profile FROM shops WHERE parser_profile IS NOT NULL ORDER BY code`)
+	if err != nil {
+		return nil, []error{err}
+	}
+	defer rows.Close()
+
+	var (
+		out  []generic.Profile
+		errs []error
+	)
+	for rows.Next() {
+		var code string
+		var data []byte
+		if err := rows.Scan(&code, &data); err != nil {
+			return out, append(errs, err)
+		}
+		p, err := generic.ParseProfile(code, data)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("shops.parser_profile %s: %w", code, err))
+			continue
+		}
+		out = append(out, p)
+	}
+	if err := rows.Err(); err != nil {
+		errs = append(errs, err)
+	}
+	return out, errs
+}
@@ -1,37 +1,99 @@
+// Утилита для отладки парсеров магазинов. Разбирает живую страницу или
+// сохранённую HTML-фикстуру по профилю магазина и сверяет результат с эталоном:
+//
+//	go run ./cmd/test-dns-parser -shop Citilink -url https://www.citilink.ru/product/...
+//	go run ./cmd/test-dns-parser -shop DNS -fixture internal/aggregator/parser/generic/testdata/dns.html \
+//	    -golden internal/aggregator/parser/generic/testdata/dns.golden.json
+//
+// С -update эталон перезаписывается текущим результатом. При расхождении
+// с эталоном утилита завершается с кодом 1. Сохранённые страницы из samples
+// встроенных профилей сверяются с эталонами в go test
+// ./internal/aggregator/parser/generic (там же -update).
 package main
 
 import (
-	"StartupPCConfigurator/internal/aggregator/parser/citilink"
+	"bytes"
 	"context"
+	"encoding/json"
+	"flag"
 	"fmt"
 	"log"
 	"os"
+	"strings"
 	"time"
+
+	"StartupPCConfigurator/internal/aggregator/parser/fetch"
+	"StartupPCConfigurator/internal/aggregator/parser/generic"
 )
 
+const defaultURL = "https://www.citilink.ru/product/videokarta-palit-pci-e-4-0-rtx4060-infinity-2-nv-rtx4060-8gb-128bit-gd-2010430/"
+
 func main() {
-	logger := log.New(os.Stdout, "[DNS Test] ", log.LstdFlags)
-	parser := citilink.NewCitilinkParser(logger)
+	shop := flag.String("shop", "Citilink", "код магазина (shops.code)")
+	profilesPath := flag.String("profiles", "", "файл профилей вместо встроенного shops.yaml")
+	url := flag.String("url", defaultURL, "страница товара")
+	fixture := flag.String("fixture", "", "HTML-файл вместо загрузки страницы")
+	golden := flag.String("golden", "", "JSON-эталон для сравнения")
+	update := flag.Bool("update", false, "перезаписать эталон текущим результатом")
+	flag.Parse()
+
+	logger := log.New(os.Stderr, "[Parser Test] ", log.LstdFlags)
 
-	url := "https://www.citilink.ru/product/videokarta-palit-pci-e-4-0-rtx4060-infinity-2-nv-rtx4060-8gb-128bit-gd-2010430/"
+	profiles := generic.DefaultProfiles
+	if *profilesPath != "" {
+		ps, err := generic.LoadProfiles(*profilesPath)
+		if err != nil {
+			logger.Fatalf("load profiles: %v", err)
+		}
+		profiles = ps
+	}
+	var profile *generic.Profile
+	for i := range profiles {
+		if strings.EqualFold(profiles[i].Code, *shop) {
+			profile = &profiles[i]
+		}
+	}
+	if profile == nil {
+		logger.Fatalf("no profile for shop %q", *shop)
+	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 90*time.Second)
+	// nil — бэкенд из профиля (браузер: CHROME_PATH, CHROME_HEADLESS)
+	var fetcher fetch.Fetcher
+	if *fixture != "" {
+		fetcher = fetch.FileFetcher{Path: *fixture}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
 	defer cancel()
 
-	// <-- CALL THE LOW‑LEVEL PARSER DIRECTLY -->
-	prod, err := parser.ParseProductPage(ctx, url)
+	prod, err := generic.NewParser(*profile, fetcher, logger).ParseProductPage(ctx, *url)
 	if err != nil {
 		logger.Fatalf("ParseProductPage error: %v", err)
 	}
 
-	// NOW prod *has* Name, Price, Description, Availability, etc.
-	fmt.Printf("Name:            %s\n", prod.Name)
-	fmt.Printf("Price:           %s\n", prod.Price)
-	fmt.Printf("Availability:    %s\n", prod.Availability)
-	fmt.Printf("Category:        %s\n", prod.Category)
-	fmt.Printf("Main Image:      %s\n", prod.MainImage)
-	fmt.Printf("Description:     %s\n", prod.Description)
-	for i, kv := range prod.Characteristics {
-		fmt.Printf("Spec %d: %s = %s\n", i+1, kv.Key, kv.Value)
+	got, err := json.MarshalIndent(prod, "", "  ")
+	if err != nil {
+		logger.Fatalf("marshal error: %v", err)
+	}
+	got = append(got, '\n')
+
+	switch {
+	case *golden == "":
+		os.Stdout.Write(got)
+	case *update:
+		if err := os.WriteFile(*golden, got, 0o644); err != nil {
+			logger.Fatalf("write golden: %v", err)
+		}
+		logger.Printf("golden updated: %s", *golden)
+	default:
+		want, err := os.ReadFile(*golden)
+		if err != nil {
+			logger.Fatalf("read golden: %v", err)
+		}
+		if !bytes.Equal(got, want) {
+			fmt.Fprintf(os.Stderr, "result differs from %s\n--- got:\n%s", *golden, got)
+			os.Exit(1)
+		}
+		logger.Printf("OK: result matches %s", *golden)
 	}
 }
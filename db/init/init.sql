@@ -242,17 +242,17 @@ INSERT INTO components(name, category, brand, specs) VALUES
 -- 4.2.7 Cases
 INSERT INTO components(name, category, brand, specs) VALUES
   ('NZXT H510','case','NZXT','{"form_factor":"ATX","gpu_max_length":325,"cooler_max_height":165,"max_motherboard_form_factors":["ATX","Micro-ATX","Mini-ITX"],
-                                   "max_psu_length":200,"psu_form_factor":"ATX","drive_bays_2_5":2,"drive_bays_3_5":2}'),
+                                   "max_psu_length":200,"psu_form_factor":"ATX","drive_bays_2_5":2,"drive_bays_3_5":2,"fan_mounts":4}'),
   ('Fractal Design North','case','Fractal Design','{"form_factor":"ATX","gpu_max_length":355,"cooler_max_height":170,"max_motherboard_form_factors":["ATX","Micro-ATX","Mini-ITX"],
-                                           "max_psu_length":250,"psu_form_factor":"ATX","drive_bays_2_5":3,"drive_bays_3_5":2}'),
+                                           "max_psu_length":250,"psu_form_factor":"ATX","drive_bays_2_5":3,"drive_bays_3_5":2,"fan_mounts":6}'),
   ('Lian Li A4-H2O','case','Lian Li','{"form_factor":"Mini-ITX","gpu_max_length":322,"cooler_max_height":55,
-                                     "max_motherboard_form_factors":["Mini-ITX"],"max_psu_length":130,"psu_form_factor":"SFX","drive_bays_2_5":2,"drive_bays_3_5":0}'),
+                                     "max_motherboard_form_factors":["Mini-ITX"],"max_psu_length":130,"psu_form_factor":"SFX","drive_bays_2_5":2,"drive_bays_3_5":0,"fan_mounts":2}'),
   ('Cooler Master NR200P','case','Cooler Master','{"form_factor":"Mini-ITX","gpu_max_length":330,"cooler_max_height":155,"max_motherboard_form_factors":["Mini-ITX"],
-                                            "max_psu_length":160,"psu_form_factor":"SFX","drive_bays_2_5":3,"drive_bays_3_5":1}'),
+                                            "max_psu_length":160,"psu_form_factor":"SFX","drive_bays_2_5":3,"drive_bays_3_5":1,"fan_mounts":7}'),
   ('Phanteks Eclipse P400A','case','Phanteks','{"form_factor":"ATX","gpu_max_length":420,"cooler_max_height":160,"max_motherboard_form_factors":["ATX","Micro-ATX","Mini-ITX"],
-                                       "max_psu_length":270,"psu_form_factor":"ATX","drive_bays_2_5":2,"drive_bays_3_5":2}'),
+                                       "max_psu_length":270,"psu_form_factor":"ATX","drive_bays_2_5":2,"drive_bays_3_5":2,"fan_mounts":7}'),
   ('Phanteks Eclipse G500A','case','Phanteks','{"form_factor":"ATX","gpu_max_length":435,"cooler_max_height":185,"max_motherboard_form_factors":["ATX","Micro-ATX","Mini-ITX"],
-                                       "max_psu_length":270,"psu_form_factor":"ATX","drive_bays_2_5":4,"drive_bays_3_5":3}');
+                                       "max_psu_length":270,"psu_form_factor":"ATX","drive_bays_2_5":4,"drive_bays_3_5":3,"fan_mounts":9}');
 
 -- 4.2.8 Coolers
 INSERT INTO components(name, category, brand, specs) VALUES
@@ -264,6 +264,12 @@ INSERT INTO components(name, category, brand, specs) VALUES
   ('Scythe Fuma 2 Rev.B','cooler','Scythe','{"socket":"AM4","height_mm":155}'),
   ('ARCTIC Freezer 34 eSports DUO','cooler','ARCTIC','{"socket":"LGA1700","height_mm":157}');
 
+-- 4.2.9 Case fans
+INSERT INTO components(name, category, brand, specs) VALUES
+  ('ARCTIC P12 PWM PST','case_fan','ARCTIC','{"size_mm":120,"rpm":1800,"power_draw":2}'),
+  ('Noctua NF-A12x25 PWM','case_fan','Noctua','{"size_mm":120,"rpm":2000,"power_draw":2}'),
+  ('be quiet! Silent Wings 4 140mm','case_fan','be quiet!','{"size_mm":140,"rpm":1100,"power_draw":2}');
+
 
 -- 4.2.x  GPU c 10 GB VRAM (для минимального ранга design)
 INSERT INTO components (name, category, brand, specs) VALUES
@@ -705,3 +711,263 @@ CREATE INDEX IF NOT EXISTS idx_components_capacity
 -- GIN по-прежнему нужен для редких произвольных запросов:
 CREATE INDEX IF NOT EXISTS idx_components_specs_gin ON components USING gIN (specs);
 
+-- Количество одинаковых компонентов в сборке (планки RAM, диски, вентиляторы)
+ALTER TABLE configuration_components
+  ADD COLUMN IF NOT EXISTS quantity INT NOT NULL DEFAULT 1 CHECK (quantity > 0);
+
+-- Версионируемые правила сценариев (пороги ScenarioRule и веса ранжирования).
+-- Активна ровно одна версия на сценарий; откат — переключение is_active.
+-- Пустая таблица заполняется встроенными правилами при старте config-service.
+CREATE TABLE IF NOT EXISTS scenario_rules (
+    id SERIAL PRIMARY KEY,
+    usecase_id INT NOT NULL
+        REFERENCES usecases(id) ON DELETE CASCADE,
+    version INT NOT NULL,
+    rule JSONB NOT NULL,
+    weights JSONB NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT FALSE,
+    comment TEXT,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (usecase_id, version)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_scenario_rules_active
+  ON scenario_rules(usecase_id) WHERE is_active;
+
+-- Мягкое удаление компонентов: удалённые скрыты из каталога,
+-- но остаются в уже сохранённых сборках
+ALTER TABLE components
+  ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_components_alive
+  ON components(category) WHERE deleted_at IS NULL;
+
+-- Публичные ссылки на сборки (только чтение). slug — случайный,
+-- ссылку можно ограничить по времени и отозвать
+CREATE TABLE IF NOT EXISTS configuration_shares (
+    id SERIAL PRIMARY KEY,
+    config_id INT NOT NULL
+        REFERENCES configurations(id) ON DELETE CASCADE,
+    slug TEXT NOT NULL UNIQUE,
+    created_by UUID NOT NULL
+        REFERENCES users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_configuration_shares_config
+  ON configuration_shares(config_id);
+
+-- История сборок: каждое создание и правка — неизменяемая ревизия.
+-- components — снимок позиций [{id, category, name, brand, quantity}]
+CREATE TABLE IF NOT EXISTS configuration_revisions (
+    id SERIAL PRIMARY KEY,
+    config_id INT NOT NULL
+        REFERENCES configurations(id) ON DELETE CASCADE,
+    revision INT NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    components JSONB NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (config_id, revision)
+);
+
+-- Сборки, сохранённые до появления истории, получают ревизию 1
+INSERT INTO configuration_revisions (config_id, revision, name, components, created_by, created_at)
+SELECT c.id, 1, c.name,
+       COALESCE(
+         (SELECT jsonb_agg(jsonb_build_object(
+                   'id', comp.id, 'category', comp.category, 'name', comp.name,
+                   'brand', comp.brand, 'quantity', cc.quantity) ORDER BY cc.id)
+            FROM configuration_components cc
+            JOIN components comp ON comp.id = cc.component_id
+           WHERE cc.config_id = c.id),
+         '[]'::jsonb),
+       c.user_id, c.updated_at
+  FROM configurations c
+ WHERE NOT EXISTS (SELECT 1 FROM configuration_revisions r WHERE r.config_id = c.id);
+
+-- Профиль парсинга магазина (селекторы, очистка цены). NULL — профиль
+-- из встроенного shops.yaml агрегатора
+ALTER TABLE shops ADD COLUMN IF NOT EXISTS parser_profile JSONB;
+
+-- Наличие, приведённое к перечислению (in_stock | limited | preorder |
+-- out_of_stock | unknown), и число магазинов из текста «в наличии в N магазинах».
+-- Цены магазинов в рублях: прежний дефолт USD был ошибкой
+ALTER TABLE offers ADD COLUMN IF NOT EXISTS availability_status TEXT NOT NULL DEFAULT 'unknown';
+ALTER TABLE offers ADD COLUMN IF NOT EXISTS store_count INT;
+ALTER TABLE offers ALTER COLUMN currency SET DEFAULT 'RUB';
+ALTER TABLE price_history ALTER COLUMN currency SET DEFAULT 'RUB';
+
+-- Страницы товаров, которые обходит парсер магазина. component_id NULL —
+-- товар ещё не сопоставлен с каталогом: по нему предлагается новый компонент
+CREATE TABLE IF NOT EXISTS shop_components (
+    id SERIAL PRIMARY KEY,
+    shop_id INT NOT NULL
+        REFERENCES shops(id) ON DELETE CASCADE,
+    component_id INT
+        REFERENCES components(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (shop_id, url)
+);
+
+-- Характеристики со страниц магазинов, ждущие проверки администратором.
+-- changes — [{key, current, proposed}], conflicts — [{key, shopCode, value, proposalId}],
+-- unmapped — строки магазина, для которых нет правила сопоставления
+CREATE TABLE IF NOT EXISTS spec_proposals (
+    id BIGSERIAL PRIMARY KEY,
+    component_id INT
+        REFERENCES components(id) ON DELETE CASCADE,
+    shop_id INT NOT NULL
+        REFERENCES shops(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    category VARCHAR(100) NOT NULL,
+    name TEXT NOT NULL DEFAULT '',
+    changes JSONB NOT NULL DEFAULT '[]',
+    conflicts JSONB NOT NULL DEFAULT '[]',
+    unmapped JSONB NOT NULL DEFAULT '[]',
+    status TEXT NOT NULL DEFAULT 'pending',   -- pending | approved | rejected
+    reviewed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- одно открытое предложение на страницу магазина
+CREATE UNIQUE INDEX IF NOT EXISTS spec_proposals_pending_idx
+  ON spec_proposals(shop_id, url) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS spec_proposals_component_idx
+  ON spec_proposals(component_id) WHERE status = 'pending';
+
+-- Фото товаров со страниц магазинов
+CREATE TABLE IF NOT EXISTS component_images (
+    id SERIAL PRIMARY KEY,
+    component_id INT NOT NULL
+        REFERENCES components(id) ON DELETE CASCADE,
+    shop_id INT NOT NULL
+        REFERENCES shops(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    is_main BOOLEAN NOT NULL DEFAULT FALSE,
+    position INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (component_id, url)
+);
+
+-- Раскладка прайс-листа магазина для POST /offers/import:
+-- {"sheet": "Прайс", "columns": {"component": "Артикул", "price": "Цена", ...}}
+ALTER TABLE shops ADD COLUMN IF NOT EXISTS price_list_mapping JSONB;
+
+-- Импорты прайс-листов тоже пишутся в update_jobs (kind = 'price_import').
+-- ref — id, который importer выдаёт до постановки файла в очередь,
+-- чтобы потом дождаться результата
+ALTER TABLE update_jobs
+  ADD COLUMN IF NOT EXISTS kind TEXT NOT NULL DEFAULT 'shop_update',
+  ADD COLUMN IF NOT EXISTS ref TEXT,
+  ADD COLUMN IF NOT EXISTS file_name TEXT,
+  ADD COLUMN IF NOT EXISTS shop_code TEXT,
+  ADD COLUMN IF NOT EXISTS dry_run BOOLEAN NOT NULL DEFAULT FALSE,
+  ADD COLUMN IF NOT EXISTS rows_total INT NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS rows_processed INT NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS rows_imported INT NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS rows_skipped INT NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS report JSONB,
+  ADD COLUMN IF NOT EXISTS created_at TIMESTAMP NOT NULL DEFAULT NOW();
+CREATE UNIQUE INDEX IF NOT EXISTS update_jobs_ref_idx ON update_jobs(ref);
+CREATE INDEX IF NOT EXISTS update_jobs_kind_idx ON update_jobs(kind, created_at DESC);
+
+-- Transactional outbox: события пишутся в той же транзакции, что offers и
+-- price_history, relay агрегатора публикует их в aggregator-ex и ставит sent_at
+CREATE TABLE IF NOT EXISTS outbox (
+    id BIGSERIAL PRIMARY KEY,
+    event_id UUID NOT NULL UNIQUE,
+    routing_key TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    sent_at TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS outbox_pending_idx ON outbox(id) WHERE sent_at IS NULL;
+-- Событие, которое брокер раз за разом отвергает, relay откладывает (failed_at),
+-- чтобы не держать очередь. Вернуть в очередь: UPDATE outbox SET failed_at = NULL, attempts = 0 WHERE id = ...
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS failed_at TIMESTAMP;
+DROP INDEX IF EXISTS outbox_pending_idx;
+CREATE INDEX IF NOT EXISTS outbox_queue_idx ON outbox(id) WHERE sent_at IS NULL AND failed_at IS NULL;
+
+-- Обработанные события: получатель отсекает повторную доставку по event_id
+CREATE TABLE IF NOT EXISTS processed_events (
+    consumer TEXT NOT NULL,
+    event_id UUID NOT NULL,
+    processed_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (consumer, event_id)
+);
+
+-- Условия подписки на цену: целевая цена, минимальное изменение в процентах,
+-- направление (drop — только снижение, any — любое) и, при желании, один магазин
+ALTER TABLE subscriptions
+  ADD COLUMN IF NOT EXISTS target_price NUMERIC,
+  ADD COLUMN IF NOT EXISTS min_drop_percent NUMERIC,
+  ADD COLUMN IF NOT EXISTS direction TEXT NOT NULL DEFAULT 'drop',
+  ADD COLUMN IF NOT EXISTS shop_id INT REFERENCES shops(id) ON DELETE CASCADE;
+
+-- Уведомления о наличии хранятся рядом с ценовыми: type различает их,
+-- availability — новый статус наличия
+ALTER TABLE notifications
+  ADD COLUMN IF NOT EXISTS type TEXT NOT NULL DEFAULT 'price_changed',
+  ADD COLUMN IF NOT EXISTS availability TEXT;
+
+-- Подписка на наличие: stock_alert = 'back_in_stock' — сообщить, когда товар
+-- снова появится, 'any' — и когда закончится; price_alerts — нужны ли ценовые
+ALTER TABLE subscriptions
+  ADD COLUMN IF NOT EXISTS price_alerts BOOLEAN NOT NULL DEFAULT TRUE,
+  ADD COLUMN IF NOT EXISTS stock_alert TEXT NOT NULL DEFAULT '';
+
+-- Слежение за общей стоимостью сохранённой сборки. baseline_* — стоимость и
+-- цены позиций, от которых считается снижение (сдвигаются после уведомления
+-- и при росте); last_total — стоимость при последнем пересчёте, для бюджета
+CREATE TABLE IF NOT EXISTS configuration_watches (
+    id SERIAL PRIMARY KEY,
+    user_id UUID NOT NULL
+        REFERENCES users(id) ON DELETE CASCADE,
+    config_id INT NOT NULL
+        REFERENCES configurations(id) ON DELETE CASCADE,
+    budget NUMERIC,
+    min_drop_percent NUMERIC,
+    last_total NUMERIC NOT NULL DEFAULT 0,
+    baseline_total NUMERIC NOT NULL DEFAULT 0,
+    baseline_prices JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, config_id)
+);
+CREATE INDEX IF NOT EXISTS configuration_watches_config_idx ON configuration_watches(config_id);
+
+-- Уведомления по сборке: config_id и разбивка по позициям в details
+ALTER TABLE notifications
+  ADD COLUMN IF NOT EXISTS config_id INT,
+  ADD COLUMN IF NOT EXISTS details JSONB;
+
+-- Email-канал уведомлений. mode: off | instant | daily | weekly.
+-- В письма попадают уведомления не старше since (момент включения) и ещё
+-- не отправленные (notifications.emailed_at IS NULL)
+CREATE TABLE IF NOT EXISTS email_settings (
+    user_id UUID PRIMARY KEY
+        REFERENCES users(id) ON DELETE CASCADE,
+    mode TEXT NOT NULL DEFAULT 'off',
+    since TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_digest_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE notifications
+  ADD COLUMN IF NOT EXISTS emailed_at TIMESTAMP;
+CREATE INDEX IF NOT EXISTS notifications_email_pending_idx
+    ON notifications(user_id, created_at) WHERE emailed_at IS NULL;
+
+-- Реплика, рассылающая письма пользователю, держит его до claimed_until:
+-- блокировка строк на время SMTP-отправки не нужна
+ALTER TABLE email_settings
+  ADD COLUMN IF NOT EXISTS claimed_until TIMESTAMP;
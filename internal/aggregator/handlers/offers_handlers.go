@@ -1,7 +1,10 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 
@@ -61,13 +64,38 @@ func (h *OffersHandler) GetOffers(c *gin.Context) {
 	c.JSON(http.StatusOK, offers)
 }
 
-// POST /offers/import
+// UploadPriceList обрабатывает POST /offers/import: файл CSV или XLSX в поле
+// file, раскладка колонок — JSON в поле mapping (необязательно)
 func (h *OffersHandler) UploadPriceList(c *gin.Context) {
 	fileHeader, err := c.FormFile("file")
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
 		return
 	}
+
+	opts := usecase.ImportOptions{
+		Filename: fileHeader.Filename,
+		Format:   c.Query("format"),
+		ShopCode: c.Query("shop"),
+		Sheet:    c.Query("sheet"),
+	}
+	if raw := c.Query("dryRun"); raw != "" {
+		dry, err := strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid dryRun"})
+			return
+		}
+		opts.DryRun = dry
+	}
+	if raw := c.PostForm("mapping"); raw != "" {
+		var m usecase.PriceListMapping
+		if err := json.Unmarshal([]byte(raw), &m); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid mapping: " + err.Error()})
+			return
+		}
+		opts.Mapping = &m
+	}
+
 	f, err := fileHeader.Open()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "cannot open file"})
@@ -75,10 +103,58 @@ func (h *OffersHandler) UploadPriceList(c *gin.Context) {
 	}
 	defer f.Close()
 
-	// вызываем usecase
-	if err := h.usecase.ImportPriceList(c.Request.Context(), f); err != nil {
+	job, err := h.usecase.RunImportJob(c.Request.Context(), f, opts, "")
+	if errors.Is(err, usecase.ErrBadPriceList) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "jobId": job.ID})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "jobId": job.ID})
+		return
+	}
+	if job.Report == nil {
+		job.Report = &domain.PriceImportReport{JobID: job.ID}
+	}
+	c.JSON(http.StatusOK, job.Report)
+}
+
+// ListImportJobs обрабатывает GET /offers/imports?status=&limit=
+func (h *OffersHandler) ListImportJobs(c *gin.Context) {
+	status := c.Query("status")
+	switch status {
+	case "", domain.JobRunning, domain.JobDone, domain.JobFailed:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
+		return
+	}
+	limit := 50
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 || n > 500 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 500"})
+			return
+		}
+		limit = n
+	}
+
+	jobs, err := h.usecase.ListImportJobs(c.Request.Context(), status, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"imports": jobs})
+}
+
+// GetImportJob обрабатывает GET /offers/imports/:id — id задачи или ref от importer'а
+func (h *OffersHandler) GetImportJob(c *gin.Context) {
+	job, err := h.usecase.GetImportJob(c.Request.Context(), c.Param("id"))
+	if errors.Is(err, domain.ErrJobNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	c.Status(http.StatusNoContent)
+	c.JSON(http.StatusOK, job)
 }
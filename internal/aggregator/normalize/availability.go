@@ -0,0 +1,89 @@
+package normalize
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"StartupPCConfigurator/internal/domain"
+)
+
+// Availability — наличие товара: статус и, если магазин его пишет, число
+// магазинов, где товар есть
+type Availability struct {
+	Status     domain.AvailabilityStatus
+	StoreCount *int
+	Text       string // исходный текст
+}
+
+// availabilityRules проверяются по порядку: «нет в наличии» содержит
+// «в наличии», поэтому отрицания идут первыми
+var availabilityRules = []struct {
+	status  domain.AvailabilityStatus
+	phrases []string
+}{
+	{domain.AvailabilityOutOfStock, []string{
+		"нет в наличии", "товара нет", "отсутству", "нет на складе", "закончился",
+		"распродан", "снят с продажи", "не продается", "out of stock", "sold out", "unavailable",
+	}},
+	{domain.AvailabilityPreorder, []string{
+		"предзаказ", "под заказ", "ожидается", "ожидаем", "в пути", "поступит",
+		"pre-order", "preorder", "backorder",
+	}},
+	{domain.AvailabilityLimited, []string{
+		"мало", "заканчивается", "осталось", "последний", "ограничен", "limited", "few left",
+	}},
+	{domain.AvailabilityInStock, []string{
+		"в наличии", "есть", "много", "на складе", "in stock", "available",
+	}},
+}
+
+// storesRe — «в 3 магазинах», «в 12 магазинах», «3 stores»
+var storesRe = regexp.MustCompile(`(\d+)\s*(?:магазин|пункт|shop|store)`)
+
+// ParseAvailability переводит текст наличия в статус. Число без слов
+// («5» из прайс-листа) — остаток на складе: 0 — нет, больше — есть.
+func ParseAvailability(text string) Availability {
+	a := Availability{Status: domain.AvailabilityUnknown, Text: strings.TrimSpace(text)}
+	s := strings.ReplaceAll(strings.ToLower(a.Text), "ё", "е")
+	if s == "" {
+		return a
+	}
+
+	if m := storesRe.FindStringSubmatch(s); m != nil {
+		if n, err := strconv.Atoi(m[1]); err == nil {
+			a.StoreCount = &n
+		}
+	}
+
+	if n, err := strconv.Atoi(s); err == nil {
+		if n > 0 {
+			a.Status = domain.AvailabilityInStock
+		} else {
+			a.Status = domain.AvailabilityOutOfStock
+		}
+		return a
+	}
+	switch s {
+	case "да", "yes", "true", "+":
+		a.Status = domain.AvailabilityInStock
+		return a
+	case "нет", "no", "false", "-":
+		a.Status = domain.AvailabilityOutOfStock
+		return a
+	}
+
+	for _, rule := range availabilityRules {
+		for _, p := range rule.phrases {
+			if strings.Contains(s, p) {
+				a.Status = rule.status
+				return a
+			}
+		}
+	}
+	// «доступен в 3 магазинах» — статуса нет, но магазины названы
+	if a.StoreCount != nil && *a.StoreCount > 0 {
+		a.Status = domain.AvailabilityInStock
+	}
+	return a
+}
@@ -0,0 +1,124 @@
+// Package normalize приводит данные магазинов к единому виду: текст цены —
+// к сумме и ISO-коду валюты, текст наличия — к domain.AvailabilityStatus.
+// Используется и парсерами страниц, и импортом прайс-листов.
+package normalize
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency — валюта, если в тексте цены её нет: магазины российские
+const DefaultCurrency = "RUB"
+
+// Price — сумма и ISO 4217 код валюты
+type Price struct {
+	Amount   float64
+	Currency string
+}
+
+var ErrNoPrice = errors.New("no price in text")
+
+// currencyAliases — символы и слова, по которым узнаём валюту (в нижнем регистре)
+var currencyAliases = []struct {
+	alias string
+	code  string
+}{
+	{"₽", "RUB"}, {"руб", "RUB"}, {"rub", "RUB"}, {"rur", "RUB"}, {"р.", "RUB"},
+	{"$", "USD"}, {"usd", "USD"}, {"долл", "USD"},
+	{"€", "EUR"}, {"eur", "EUR"}, {"евро", "EUR"},
+	{"₸", "KZT"}, {"kzt", "KZT"}, {"тг", "KZT"},
+	{"byn", "BYN"}, {"бел", "BYN"},
+	{"¥", "CNY"}, {"cny", "CNY"}, {"юан", "CNY"},
+}
+
+// numberRe — первое число в тексте вместе с разделителями разрядов:
+// «от 12 499 ₽ 13 999 ₽» → «12 499 ». Пробелы бывают обычные,
+// неразрывные (U+00A0), узкие (U+2009, U+202F); апостроф — швейцарский стиль
+var numberRe = regexp.MustCompile(`\d[\d \x{00A0}\x{2009}\x{202F}'.,]*`)
+
+var spaceReplacer = strings.NewReplacer(" ", "", "\u00a0", "", "\u2009", "", "\u202f", "", "'", "")
+
+// Currency приводит код или символ валюты к ISO 4217: «₽», «руб.», «RUR» → «RUB».
+// ok = false — валюта не распознана.
+func Currency(s string) (code string, ok bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return "", false
+	}
+	for _, a := range currencyAliases {
+		if strings.Contains(s, a.alias) {
+			return a.code, true
+		}
+	}
+	if len(s) == 3 && isLatin(s) {
+		return strings.ToUpper(s), true // ISO-код, которого нет в списке
+	}
+	return "", false
+}
+
+// ParsePrice разбирает цену вида «12 499 ₽», «1 299,90 руб.», «$1,299.00».
+// Если валюты в тексте нет — берётся defaultCurrency (пусто — DefaultCurrency).
+func ParsePrice(text, defaultCurrency string) (Price, error) {
+	num := numberRe.FindString(text)
+	if num == "" {
+		return Price{}, fmt.Errorf("%w: %q", ErrNoPrice, text)
+	}
+	amount, err := parseAmount(spaceReplacer.Replace(strings.TrimRight(num, " .,")))
+	if err != nil {
+		return Price{}, fmt.Errorf("price %q: %w", text, err)
+	}
+	if amount <= 0 {
+		return Price{}, fmt.Errorf("price %q: must be positive", text)
+	}
+
+	rest := strings.Replace(text, num, " ", 1)
+	currency, ok := Currency(rest)
+	if !ok {
+		if currency, ok = Currency(defaultCurrency); !ok {
+			currency = DefaultCurrency
+		}
+	}
+	return Price{Amount: amount, Currency: currency}, nil
+}
+
+// parseAmount определяет десятичный разделитель. Если есть и точка, и
+// запятая — десятичный тот, что правее. Один разделитель, за которым ровно
+// три цифры, считается разделителем разрядов («12.499», «1,299»).
+func parseAmount(s string) (float64, error) {
+	lastDot, lastComma := strings.LastIndex(s, "."), strings.LastIndex(s, ",")
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		if lastComma > lastDot {
+			s = strings.ReplaceAll(s, ".", "")
+			s = strings.Replace(s, ",", ".", 1)
+		} else {
+			s = strings.ReplaceAll(s, ",", "")
+		}
+	case lastComma >= 0:
+		s = decimalOrGroup(s, ",")
+	case lastDot >= 0:
+		s = decimalOrGroup(s, ".")
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+func decimalOrGroup(s, sep string) string {
+	parts := strings.Split(s, sep)
+	if len(parts) > 2 || len(parts[1]) == 3 {
+		return strings.Join(parts, "")
+	}
+	return parts[0] + "." + parts[1]
+}
+
+func isLatin(s string) bool {
+	for _, r := range s {
+		if r < 'a' || r > 'z' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,141 @@
+package fetch
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+)
+
+// BrowserOptions — настройки браузерного бэкенда
+type BrowserOptions struct {
+	ExecPath string        // путь к Chrome; пусто — chromedp ищет сам
+	Headless bool          // false — видимое окно (удобно для отладки)
+	Timeout  time.Duration // на всю загрузку страницы; 0 — 90 секунд
+	Delay    time.Duration // случайная пауза от Delay до 2×Delay перед загрузкой (имитация человека)
+	Logger   *log.Logger
+}
+
+// BrowserOptionsFromEnv читает CHROME_PATH и CHROME_HEADLESS (по умолчанию true)
+func BrowserOptionsFromEnv(logger *log.Logger) BrowserOptions {
+	opts := BrowserOptions{
+		ExecPath: os.Getenv("CHROME_PATH"),
+		Headless: true,
+		Logger:   logger,
+	}
+	if v, err := strconv.ParseBool(os.Getenv("CHROME_HEADLESS")); err == nil {
+		opts.Headless = v
+	}
+	return opts
+}
+
+// BrowserFetcher — бэкенд для страниц, которые дорисовываются на JS.
+// Ждёт появления waitSelector и отдаёт итоговый DOM.
+type BrowserFetcher struct {
+	opts         BrowserOptions
+	waitSelector string
+}
+
+// NewBrowserFetcher создаёт браузерный бэкенд; waitSelector — элемент,
+// появление которого означает, что страница готова
+func NewBrowserFetcher(opts BrowserOptions, waitSelector string) *BrowserFetcher {
+	if opts.Timeout == 0 {
+		opts.Timeout = 90 * time.Second
+	}
+	if opts.Logger == nil {
+		opts.Logger = log.Default()
+	}
+	return &BrowserFetcher{opts: opts, waitSelector: waitSelector}
+}
+
+func (f *BrowserFetcher) Fetch(parent context.Context, url string) (string, error) {
+	if f.opts.Delay > 0 {
+		pause := f.opts.Delay + time.Duration(rand.Int63n(int64(f.opts.Delay)))
+		select {
+		case <-time.After(pause):
+		case <-parent.Done():
+			return "", parent.Err()
+		}
+	}
+
+	ctx, cancel, err := f.newStealthContext(parent)
+	if err != nil {
+		return "", err
+	}
+	defer cancel()
+
+	var html string
+	actions := []chromedp.Action{chromedp.Navigate(url)}
+	if f.waitSelector != "" {
+		actions = append(actions, chromedp.WaitVisible(f.waitSelector, chromedp.ByQuery))
+	}
+	actions = append(actions, chromedp.OuterHTML("html", &html))
+	if err := chromedp.Run(ctx, actions...); err != nil {
+		return "", fmt.Errorf("chromedp run error: %w", err)
+	}
+	return html, nil
+}
+
+// newStealthContext запускает браузер, который не выдаёт себя за автоматизацию
+func (f *BrowserFetcher) newStealthContext(parent context.Context) (context.Context, context.CancelFunc, error) {
+	// 1. Создаём allocator (браузер + флаги)
+	allocOpts := append(chromedp.DefaultExecAllocatorOptions[:],
+		chromedp.Flag("headless", f.opts.Headless),
+		chromedp.Flag("disable-gpu", true),
+		chromedp.UserAgent(defaultUserAgent),
+	)
+	if f.opts.ExecPath != "" {
+		allocOpts = append(allocOpts, chromedp.ExecPath(f.opts.ExecPath))
+	}
+	allocCtx, cancelAlloc := chromedp.NewExecAllocator(parent, allocOpts...)
+
+	// 2. Новый контекст с CDP‑слушателями
+	ctx, cancelCtx := chromedp.NewContext(allocCtx, chromedp.WithLogf(f.opts.Logger.Printf))
+
+	// 3. Устанавливаем таймаут на всё
+	ctx, cancelTimeout := context.WithTimeout(ctx, f.opts.Timeout)
+
+	cancel := func() {
+		cancelTimeout()
+		cancelCtx()
+		cancelAlloc()
+	}
+
+	stealth := chromedp.ActionFunc(func(ctx context.Context) error {
+		// включаем сеть, ставим доп. заголовки
+		if err := network.Enable().Do(ctx); err != nil {
+			return err
+		}
+		if err := network.SetExtraHTTPHeaders(network.Headers{
+			"Accept-Language": "ru-RU,ru;q=0.9",
+			"Accept":          "text/html,application/xhtml+xml,application/xml;q=0.9",
+		}).Do(ctx); err != nil {
+			return err
+		}
+		// инжектим скрипт перед любой загрузкой страницы
+		js := `(() => {
+            Object.defineProperty(navigator, 'webdriver', { get: () => false });
+            window.chrome = { runtime: {} };
+            Object.defineProperty(navigator, 'languages', {
+              get: () => ['ru-RU', 'ru', 'en-US', 'en']
+            });
+            Object.defineProperty(navigator, 'plugins', {
+              get: () => [1, 2, 3, 4, 5]
+            });
+        })();`
+		_, err := page.AddScriptToEvaluateOnNewDocument(js).Do(ctx)
+		return err
+	})
+	if err := chromedp.Run(ctx, stealth); err != nil {
+		cancel()
+		return nil, nil, fmt.Errorf("failed to install stealth scripts: %w", err)
+	}
+	return ctx, cancel, nil
+}
@@ -0,0 +1,82 @@
+// Package fetch загружает HTML страниц магазинов. Парсеры магазинов получают
+// Fetcher снаружи и сами в сеть не ходят: серверные страницы читаются обычным
+// HTTP-запросом, страницы с рендерингом на JS — через браузер, а сохранённые
+// фикстуры — с диска.
+package fetch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Fetcher возвращает HTML страницы по URL
+type Fetcher interface {
+	Fetch(ctx context.Context, url string) (string, error)
+}
+
+// defaultUserAgent — магазины отдают урезанную страницу «неизвестным» клиентам
+const defaultUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) " +
+	"AppleWebKit/537.36 (KHTML, like Gecko) " +
+	"Chrome/135.0.0.0 Safari/537.36"
+
+// maxPageSize — защита от бесконечного ответа
+const maxPageSize = 10 << 20
+
+// HTTPFetcher — бэкенд для страниц, которые рендерятся на сервере
+type HTTPFetcher struct {
+	client    *http.Client
+	userAgent string
+}
+
+// NewHTTPFetcher создаёт HTTP-бэкенд; timeout = 0 — 30 секунд
+func NewHTTPFetcher(timeout time.Duration) *HTTPFetcher {
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+	return &HTTPFetcher{
+		client:    &http.Client{Timeout: timeout},
+		userAgent: defaultUserAgent,
+	}
+}
+
+func (f *HTTPFetcher) Fetch(ctx context.Context, url string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", f.userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9")
+	req.Header.Set("Accept-Language", "ru-RU,ru;q=0.9")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fetch %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fetch %s: unexpected status %s", url, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxPageSize))
+	if err != nil {
+		return "", fmt.Errorf("fetch %s: %w", url, err)
+	}
+	return string(body), nil
+}
+
+// FileFetcher отдаёт сохранённую HTML-фикстуру вместо страницы — для
+// проверки селекторов без сети. URL игнорируется.
+type FileFetcher struct {
+	Path string
+}
+
+func (f FileFetcher) Fetch(_ context.Context, _ string) (string, error) {
+	data, err := os.ReadFile(f.Path)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,252 @@
+package generic
+
+import (
+	"context"
+	"fmt"
+	"log"
+	neturl "net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"StartupPCConfigurator/internal/aggregator/normalize"
+	"StartupPCConfigurator/internal/aggregator/parser/fetch"
+	"StartupPCConfigurator/internal/aggregator/usecase"
+	"StartupPCConfigurator/internal/domain"
+)
+
+// Item — данные карточки товара
+type Item struct {
+	Name               string
+	Price              string // после price_cleanup
+	PriceText          string // как на странице
+	Amount             float64
+	Currency           string
+	Description        string
+	Availability       string
+	AvailabilityStatus domain.AvailabilityStatus
+	StoreCount         *int
+	Category           string
+	MainImage          string
+	Images             []string
+	Characteristics    []KV
+}
+
+type KV struct {
+	Key   string
+	Value string
+}
+
+// Parser — парсер магазина по профилю; реализует usecase.Parser
+type Parser struct {
+	profile Profile
+	fetcher fetch.Fetcher
+	logger  *log.Logger
+}
+
+// NewParser создаёт парсер; fetcher == nil — бэкенд из профиля
+// (браузер настраивается из окружения: CHROME_PATH, CHROME_HEADLESS)
+func NewParser(p Profile, fetcher fetch.Fetcher, logger *log.Logger) *Parser {
+	if logger == nil {
+		logger = log.Default()
+	}
+	if fetcher == nil {
+		fetcher = NewFetcher(p, fetch.BrowserOptionsFromEnv(logger))
+	}
+	return &Parser{profile: p, fetcher: fetcher, logger: logger}
+}
+
+// NewFetcher возвращает бэкенд загрузки, указанный в профиле
+func NewFetcher(p Profile, opts fetch.BrowserOptions) fetch.Fetcher {
+	if p.Backend != BackendBrowser {
+		return fetch.NewHTTPFetcher(0)
+	}
+	if p.Delay > 0 {
+		opts.Delay = p.Delay
+	}
+	return fetch.NewBrowserFetcher(opts, p.WaitSelector)
+}
+
+// Profile — профиль, по которому работает парсер
+func (ps *Parser) Profile() Profile { return ps.profile }
+
+func (ps *Parser) ParseProductPage(ctx context.Context, url string) (*Item, error) {
+	ps.logger.Printf("Parsing %s page: %s", ps.profile.Code, url)
+	html, err := ps.fetcher.Fetch(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	item, err := ps.profile.ParseHTML(html)
+	if err != nil {
+		return nil, err
+	}
+	item.resolveImages(url)
+	return item, nil
+}
+
+// Parse — обвязка под usecase.Parser
+func (ps *Parser) Parse(ctx context.Context, url string) (*usecase.ParsedItem, error) {
+	item, err := ps.ParseProductPage(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	if item.Price == "" {
+		return nil, fmt.Errorf("%s: price not found on %s", ps.profile.Code, url)
+	}
+	specs := make([]domain.SpecRow, 0, len(item.Characteristics))
+	for _, kv := range item.Characteristics {
+		specs = append(specs, domain.SpecRow{Name: kv.Key, Value: kv.Value})
+	}
+	return &usecase.ParsedItem{
+		Price:        item.Price,
+		Availability: item.Availability,
+		URL:          url,
+		Name:         item.Name,
+		Category:     item.Category,
+		MainImage:    item.MainImage,
+		Images:       item.Images,
+		Specs:        specs,
+	}, nil
+}
+
+// ParseHTML извлекает данные карточки по селекторам профиля. Сеть не нужна,
+// поэтому так же проверяются сохранённые страницы из samples.
+func (p *Profile) ParseHTML(html string) (*Item, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return nil, fmt.Errorf("goquery parse error: %w", err)
+	}
+	sel := p.Selectors
+	first := func(s *goquery.Selection, selector string) string {
+		if selector == "" {
+			return ""
+		}
+		return strings.TrimSpace(s.Find(selector).First().Text())
+	}
+
+	item := &Item{}
+	item.Name = first(doc.Selection, sel.Title)
+	item.PriceText = first(doc.Selection, sel.Price)
+	item.Price = p.CleanPrice(item.PriceText)
+	if price, err := normalize.ParsePrice(item.Price, normalize.DefaultCurrency); err == nil {
+		item.Amount, item.Currency = price.Amount, price.Currency
+	}
+	item.Description = first(doc.Selection, sel.Description)
+	item.Availability = first(doc.Selection, sel.Availability)
+	if item.Availability == "" {
+		item.Availability = p.AvailabilityDefault
+	}
+	avail := normalize.ParseAvailability(item.Availability)
+	item.AvailabilityStatus, item.StoreCount = avail.Status, avail.StoreCount
+
+	// категория — последний непустой элемент хлебных крошек
+	if sel.Breadcrumbs != "" {
+		doc.Find(sel.Breadcrumbs).Each(func(i int, s *goquery.Selection) {
+			if t := strings.TrimSpace(s.Text()); t != "" {
+				item.Category = t
+			}
+		})
+	}
+
+	// ссылка на картинку — из image_attr (data-src у ленивой загрузки),
+	// если его нет — из src
+	imageSrc := func(s *goquery.Selection) string {
+		if src, ok := s.Attr(sel.ImageAttr); ok && strings.TrimSpace(src) != "" {
+			return strings.TrimSpace(src)
+		}
+		src, _ := s.Attr("src")
+		return strings.TrimSpace(src)
+	}
+	if sel.MainImage != "" {
+		item.MainImage = imageSrc(doc.Find(sel.MainImage).First())
+	}
+	if sel.Images != "" {
+		doc.Find(sel.Images).Each(func(i int, s *goquery.Selection) {
+			if src := imageSrc(s); src != "" {
+				item.Images = append(item.Images, src)
+			}
+		})
+	}
+
+	if sel.SpecRow != "" {
+		doc.Find(sel.SpecRow).Each(func(i int, s *goquery.Selection) {
+			k := first(s, sel.SpecName)
+			if k != "" {
+				item.Characteristics = append(item.Characteristics, KV{Key: k, Value: first(s, sel.SpecValue)})
+			}
+		})
+	}
+	return item, nil
+}
+
+// resolveImages делает ссылки на картинки абсолютными: относительные
+// ("/img/1.jpg") и без схемы ("//cdn/1.jpg") считаются от адреса страницы
+func (it *Item) resolveImages(pageURL string) {
+	base, err := neturl.Parse(pageURL)
+	if err != nil {
+		return
+	}
+	resolve := func(src string) string {
+		ref, err := neturl.Parse(src)
+		if err != nil {
+			return src
+		}
+		return base.ResolveReference(ref).String()
+	}
+	if it.MainImage != "" {
+		it.MainImage = resolve(it.MainImage)
+	}
+	for i, src := range it.Images {
+		it.Images[i] = resolve(src)
+	}
+}
+
+// CheckSamples разбирает сохранённые страницы профиля и сверяет результат
+// с expect. baseDir — каталог, от которого считаются пути sample.file.
+func (p *Profile) CheckSamples(baseDir string) []error {
+	var errs []error
+	for _, s := range p.Samples {
+		fail := func(format string, args ...interface{}) {
+			errs = append(errs, fmt.Errorf("%s: %s: %s", p.Code, s.File, fmt.Sprintf(format, args...)))
+		}
+		data, err := os.ReadFile(filepath.Join(baseDir, s.File))
+		if err != nil {
+			fail("%v", err)
+			continue
+		}
+		item, err := p.ParseHTML(string(data))
+		if err != nil {
+			fail("%v", err)
+			continue
+		}
+
+		if item.Name == "" {
+			fail("title not found (%s)", p.Selectors.Title)
+		}
+		if _, err := normalize.ParsePrice(item.Price, normalize.DefaultCurrency); err != nil {
+			fail("%v (raw %q)", err, item.PriceText)
+		}
+		if item.AvailabilityStatus == domain.AvailabilityUnknown {
+			fail("availability %q is not recognized", item.Availability)
+		}
+		check := func(field, got, want string) {
+			if want != "" && got != want {
+				fail("%s = %q, want %q", field, got, want)
+			}
+		}
+		check("title", item.Name, s.Expect.Title)
+		if s.Expect.Price != 0 && item.Amount != s.Expect.Price {
+			fail("price = %v, want %v", item.Amount, s.Expect.Price)
+		}
+		check("currency", item.Currency, s.Expect.Currency)
+		check("availability", item.Availability, s.Expect.Availability)
+		check("availability_status", string(item.AvailabilityStatus), string(s.Expect.AvailabilityStatus))
+		check("category", item.Category, s.Expect.Category)
+		if len(item.Characteristics) < s.Expect.MinSpecs {
+			fail("%d spec rows, want at least %d", len(item.Characteristics), s.Expect.MinSpecs)
+		}
+	}
+	return errs
+}
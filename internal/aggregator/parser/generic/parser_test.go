@@ -0,0 +1,70 @@
+package generic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"StartupPCConfigurator/internal/aggregator/parser/fetch"
+)
+
+// go test ./internal/aggregator/parser/generic -update — перезаписать эталоны
+var update = flag.Bool("update", false, "перезаписать testdata/*.golden.json текущим результатом")
+
+// pageURL — адрес страницы в тестах: от него считаются относительные ссылки
+const pageURL = "https://shop.example/product/1/"
+
+// TestGolden разбирает сохранённые страницы из samples встроенных профилей и
+// сравнивает результат с testdata/<страница>.golden.json
+func TestGolden(t *testing.T) {
+	logger := log.New(io.Discard, "", 0)
+	for _, p := range DefaultProfiles {
+		for _, s := range p.Samples {
+			t.Run(p.Code+"/"+filepath.Base(s.File), func(t *testing.T) {
+				golden := strings.TrimSuffix(s.File, filepath.Ext(s.File)) + ".golden.json"
+
+				parser := NewParser(p, fetch.FileFetcher{Path: s.File}, logger)
+				item, err := parser.ParseProductPage(context.Background(), pageURL)
+				if err != nil {
+					t.Fatalf("ParseProductPage: %v", err)
+				}
+				got, err := json.MarshalIndent(item, "", "  ")
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, '\n')
+
+				if *update {
+					if err := os.WriteFile(golden, got, 0o644); err != nil {
+						t.Fatal(err)
+					}
+					return
+				}
+				want, err := os.ReadFile(golden)
+				if err != nil {
+					t.Fatalf("read golden (go test -update creates it): %v", err)
+				}
+				if !bytes.Equal(got, want) {
+					t.Errorf("result differs from %s\n--- got:\n%s--- want:\n%s", golden, got, want)
+				}
+			})
+		}
+	}
+}
+
+// TestSamples проверяет ожидания samples из shops.yaml
+func TestSamples(t *testing.T) {
+	for i := range DefaultProfiles {
+		p := &DefaultProfiles[i]
+		for _, err := range p.CheckSamples(".") {
+			t.Errorf("%s: %v", p.Code, err)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+// Package generic — парсер карточки товара, который настраивается профилем
+// магазина: CSS-селекторами и правилами очистки цены. Чтобы подключить новый
+// магазин, достаточно описать профиль в shops.yaml или в shops.parser_profile.
+package generic
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"StartupPCConfigurator/internal/domain"
+)
+
+// Бэкенды загрузки страницы
+const (
+	BackendHTTP    = "http"    // страница рендерится на сервере
+	BackendBrowser = "browser" // нужен Chrome: данные дорисовываются на JS
+)
+
+// Profile — профиль парсинга одного магазина
+type Profile struct {
+	Code                string        `yaml:"code" json:"code"` // shops.code
+	Backend             string        `yaml:"backend,omitempty" json:"backend,omitempty"`
+	WaitSelector        string        `yaml:"wait_selector,omitempty" json:"wait_selector,omitempty"` // для browser; по умолчанию selectors.price
+	Delay               time.Duration `yaml:"delay,omitempty" json:"delay,omitempty"`                 // пауза перед загрузкой (антибан)
+	Selectors           Selectors     `yaml:"selectors" json:"selectors"`
+	PriceCleanup        []Replace     `yaml:"price_cleanup,omitempty" json:"price_cleanup,omitempty"`
+	AvailabilityDefault string        `yaml:"availability_default,omitempty" json:"availability_default,omitempty"` // если статус не найден
+	Samples             []Sample      `yaml:"samples,omitempty" json:"samples,omitempty"`
+}
+
+// Selectors — CSS-селекторы карточки товара. Из нескольких совпадений
+// берётся первое; для breadcrumbs — последнее непустое.
+type Selectors struct {
+	Title        string `yaml:"title" json:"title"`
+	Price        string `yaml:"price" json:"price"`
+	Description  string `yaml:"description,omitempty" json:"description,omitempty"`
+	Availability string `yaml:"availability,omitempty" json:"availability,omitempty"`
+	Breadcrumbs  string `yaml:"breadcrumbs,omitempty" json:"breadcrumbs,omitempty"`
+	MainImage    string `yaml:"main_image,omitempty" json:"main_image,omitempty"`
+	Images       string `yaml:"images,omitempty" json:"images,omitempty"`
+	ImageAttr    string `yaml:"image_attr,omitempty" json:"image_attr,omitempty"` // атрибут со ссылкой; по умолчанию src
+	SpecRow      string `yaml:"spec_row,omitempty" json:"spec_row,omitempty"`
+	SpecName     string `yaml:"spec_name,omitempty" json:"spec_name,omitempty"`   // внутри spec_row
+	SpecValue    string `yaml:"spec_value,omitempty" json:"spec_value,omitempty"` // внутри spec_row
+}
+
+// Replace — regexp-замена для очистки текста цены. Обычно не нужна:
+// пробелы, запятые и символы валют разбирает normalize.ParsePrice; замены
+// нужны для мусора вроде «цена по карте» или старой цены в том же блоке
+type Replace struct {
+	Pattern string `yaml:"pattern" json:"pattern"`
+	Replace string `yaml:"replace" json:"replace"`
+
+	re *regexp.Regexp
+}
+
+// Sample — сохранённая страница и то, что парсер должен из неё извлечь.
+// Пустые поля Expect не проверяются.
+type Sample struct {
+	File   string       `yaml:"file" json:"file"` // относительно файла профилей
+	Expect SampleExpect `yaml:"expect" json:"expect"`
+}
+
+type SampleExpect struct {
+	Title              string                    `yaml:"title,omitempty" json:"title,omitempty"`
+	Price              float64                   `yaml:"price,omitempty" json:"price,omitempty"` // после нормализации
+	Currency           string                    `yaml:"currency,omitempty" json:"currency,omitempty"`
+	Availability       string                    `yaml:"availability,omitempty" json:"availability,omitempty"`
+	AvailabilityStatus domain.AvailabilityStatus `yaml:"availability_status,omitempty" json:"availability_status,omitempty"`
+	Category           string                    `yaml:"category,omitempty" json:"category,omitempty"`
+	MinSpecs           int                       `yaml:"min_specs,omitempty" json:"min_specs,omitempty"`
+}
+
+//go:embed shops.yaml
+var defaultProfiles []byte
+
+// DefaultProfiles — профили из встроенного shops.yaml
+var DefaultProfiles = mustParseProfiles(defaultProfiles)
+
+func mustParseProfiles(data []byte) []Profile {
+	ps, err := ParseProfiles(data)
+	if err != nil {
+		panic(fmt.Sprintf("встроенные профили магазинов: %v", err))
+	}
+	return ps
+}
+
+// LoadProfiles читает список профилей из YAML- или JSON-файла
+func LoadProfiles(path string) ([]Profile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseProfiles(data)
+}
+
+// ParseProfiles разбирает и валидирует список профилей
+func ParseProfiles(data []byte) ([]Profile, error) {
+	var ps []Profile
+	if err := yaml.Unmarshal(data, &ps); err != nil {
+		return nil, err
+	}
+	codes := map[string]bool{}
+	for i := range ps {
+		if err := ps[i].Validate(); err != nil {
+			return nil, fmt.Errorf("profile #%d (%s): %w", i, ps[i].Code, err)
+		}
+		code := strings.ToLower(ps[i].Code)
+		if codes[code] {
+			return nil, fmt.Errorf("duplicate profile code %q", ps[i].Code)
+		}
+		codes[code] = true
+	}
+	return ps, nil
+}
+
+// ParseProfile разбирает профиль из shops.parser_profile; code — shops.code
+func ParseProfile(code string, data []byte) (Profile, error) {
+	var p Profile
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return p, err
+	}
+	p.Code = code
+	return p, p.Validate()
+}
+
+// Validate проверяет профиль, компилирует регулярки и проставляет умолчания
+func (p *Profile) Validate() error {
+	p.Code = strings.TrimSpace(p.Code)
+	if p.Code == "" {
+		return fmt.Errorf("code is required")
+	}
+	if p.Backend == "" {
+		p.Backend = BackendHTTP
+	}
+	switch p.Backend {
+	case BackendHTTP, BackendBrowser:
+	default:
+		return fmt.Errorf("unknown backend %q", p.Backend)
+	}
+	if p.Selectors.Title == "" || p.Selectors.Price == "" {
+		return fmt.Errorf("selectors.title and selectors.price are required")
+	}
+	if p.Selectors.SpecRow != "" && (p.Selectors.SpecName == "" || p.Selectors.SpecValue == "") {
+		return fmt.Errorf("selectors.spec_row needs spec_name and spec_value")
+	}
+	if p.Selectors.ImageAttr == "" {
+		p.Selectors.ImageAttr = "src"
+	}
+	if p.Backend == BackendBrowser && p.WaitSelector == "" {
+		p.WaitSelector = p.Selectors.Price
+	}
+	for i := range p.PriceCleanup {
+		re, err := regexp.Compile(p.PriceCleanup[i].Pattern)
+		if err != nil {
+			return fmt.Errorf("price_cleanup #%d: %w", i, err)
+		}
+		p.PriceCleanup[i].re = re
+	}
+	for _, s := range p.Samples {
+		if s.File == "" {
+			return fmt.Errorf("sample needs a file")
+		}
+	}
+	return nil
+}
+
+// CleanPrice прогоняет текст цены через price_cleanup
+func (p *Profile) CleanPrice(text string) string {
+	for _, r := range p.PriceCleanup {
+		text = r.re.ReplaceAllString(text, r.Replace)
+	}
+	return strings.TrimSpace(text)
+}
@@ -2,12 +2,11 @@ package rabbitmq
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"log"
 
-	"github.com/streadway/amqp"
-
 	"StartupPCConfigurator/internal/aggregator/usecase"
+	mq "StartupPCConfigurator/pkg/rabbitmq"
 )
 
 // Предположим, у вас в usecase определена структура:
@@ -18,27 +17,27 @@ import (
 // Если она в другом пакете — поправьте импорт.
 type ShopUpdateMsg = usecase.ShopUpdateMsg
 
-// StartAggregatorConsumer слушает очередь "shop_update" и вызывает ProcessShopUpdate
+// StartAggregatorConsumer слушает очередь "shop_update" и вызывает ProcessShopUpdate.
+// Работает, пока не отменён ctx, переживая переподключения к брокеру;
+// упавшие задачи повторяются, затем уходят в shop_update.dlq.
 func StartAggregatorConsumer(
-	ch *amqp.Channel,
+	ctx context.Context,
+	conn *mq.Conn,
 	updUC usecase.UpdateUseCase, // <— теперь UpdateUseCase
 	logger *log.Logger,
-) error {
-	q, err := ch.QueueDeclare("shop_update", true, false, false, false, nil)
-	if err != nil {
-		return err
-	}
-
-	msgs, err := ch.Consume(q.Name, "", true, false, false, false, nil)
-	if err != nil {
+) {
+	cfg := mq.ConsumerConfig{Queue: "shop_update", Logger: logger}
+	mq.Run(ctx, conn, cfg, mq.JSON(func(ctx context.Context, msg ShopUpdateMsg) error {
+		err := updUC.ProcessShopUpdate(ctx, msg.JobID, msg.ShopID)
+		if err == nil {
+			return nil
+		}
+		logger.Printf("shop_update job %d failed: %v", msg.JobID, err)
+		// без парсера магазина повтор ничего не даст
+		var noParser *usecase.NoParserError
+		if errors.As(err, &noParser) {
+			return mq.Permanent(err)
+		}
 		return err
-	}
-
-	logger.Println("Shop‑update consumer running...")
-	for d := range msgs {
-		var msg usecase.ShopUpdateMsg
-		json.Unmarshal(d.Body, &msg)
-		updUC.ProcessShopUpdate(context.Background(), msg.JobID, msg.ShopID)
-	}
-	return nil
+	}))
 }
@@ -2,39 +2,45 @@ package rabbitmq
 
 import (
 	"StartupPCConfigurator/internal/aggregator/usecase"
+	mq "StartupPCConfigurator/pkg/rabbitmq"
 	"context"
-	"encoding/json"
-	"github.com/streadway/amqp"
+	"errors"
+	"fmt"
 	"log"
 	"os"
 )
 
 type ImportMsg struct {
 	FilePath string `json:"filePath"`
+	ShopCode string `json:"shopCode,omitempty"` // если в файле нет колонки магазина
+	Ref      string `json:"ref,omitempty"`      // id задачи от importer'а, по нему ждут результат
 }
 
-func StartImportConsumer(ch *amqp.Channel, uc usecase.OffersUseCase, logger *log.Logger) error {
-	q, _ := ch.QueueDeclare("price_list_import", true, false, false, false, nil)
-	msgs, _ := ch.Consume(q.Name, "", true, false, false, false, nil)
-
-	for d := range msgs {
-		var m ImportMsg
-		if err := json.Unmarshal(d.Body, &m); err != nil {
-			logger.Printf("import: invalid message: %v", err)
-			continue
-		}
+// StartImportConsumer слушает очередь "price_list_import". Битый прайс-лист или
+// неизвестный магазин сразу уходят в price_list_import.dlq, прочие ошибки повторяются.
+func StartImportConsumer(ctx context.Context, conn *mq.Conn, uc usecase.OffersUseCase, logger *log.Logger) {
+	cfg := mq.ConsumerConfig{Queue: "price_list_import", Logger: logger}
+	mq.Run(ctx, conn, cfg, mq.JSON(func(ctx context.Context, m ImportMsg) error {
 		// Открываем файл по пути:
 		f, err := os.Open(m.FilePath)
 		if err != nil {
-			logger.Printf("import: cannot open %s: %v", m.FilePath, err)
-			continue
+			return mq.Permanent(fmt.Errorf("import: cannot open %s: %w", m.FilePath, err))
 		}
-		if err := uc.ImportPriceList(context.Background(), f); err != nil {
-			logger.Printf("import: failed for %s: %v", m.FilePath, err)
-		} else {
-			logger.Printf("import: success for %s", m.FilePath)
+		defer f.Close()
+
+		job, err := uc.RunImportJob(ctx, f, usecase.ImportOptions{
+			Filename: m.FilePath,
+			ShopCode: m.ShopCode,
+		}, m.Ref)
+		if err != nil {
+			err = fmt.Errorf("import job %d: failed for %s: %w", job.ID, m.FilePath, err)
+			if errors.Is(err, usecase.ErrBadPriceList) {
+				return mq.Permanent(err)
+			}
+			return err
 		}
-		f.Close()
-	}
-	return nil
+		logger.Printf("import job %d: %s: %d imported, %d skipped",
+			job.ID, m.FilePath, job.Imported, job.Skipped)
+		return nil
+	}))
 }
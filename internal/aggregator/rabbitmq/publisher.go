@@ -2,91 +2,49 @@
 package rabbitmq
 
 import (
-	"encoding/json"
-	"log"
+	"context"
+	"errors"
+	"fmt"
 
 	"github.com/streadway/amqp"
+
+	"StartupPCConfigurator/internal/aggregator/usecase"
+	"StartupPCConfigurator/internal/domain"
+	mq "StartupPCConfigurator/pkg/rabbitmq"
 )
 
-// AggregatorPublisher — пример структуры, которая умеет отправлять сообщения
+// AggregatorPublisher отправляет события outbox в обменник агрегатора.
+// Публикует с подтверждением брокера и переживает переподключения.
 type AggregatorPublisher struct {
-	ch     *amqp.Channel
-	logger *log.Logger
+	pub    *mq.Publisher
 	exName string // имя обменника, если используете Exchange
 }
 
-type Publisher interface {
-	PublishPriceChanged(componentID string, shopID int64, price float64) error
-}
-
 // NewAggregatorPublisher — конструктор
-func NewAggregatorPublisher(ch *amqp.Channel, logger *log.Logger, exName string) *AggregatorPublisher {
+func NewAggregatorPublisher(pub *mq.Publisher, exName string) *AggregatorPublisher {
 	return &AggregatorPublisher{
-		ch:     ch,
-		logger: logger,
+		pub:    pub,
 		exName: exName,
 	}
 }
 
-// PublishPriceUpdated — отправить событие "price_updated" в систему
-func (p *AggregatorPublisher) PublishPriceUpdated(shopID string, componentID string) error {
-	event := map[string]string{
-		"event":       "price_updated",
-		"shopId":      shopID,
-		"componentId": componentID,
-	}
-
-	body, err := json.Marshal(event)
-	if err != nil {
-		return err
-	}
-
-	err = p.ch.Publish(
-		p.exName,     // exchange
-		"aggregator", // routing key
-		false,        // mandatory
-		false,        // immediate
+// PublishEvent публикует событие outbox в обменник агрегатора. MessageId —
+// EventID, по нему получатели отсекают повторы. nil — брокер подтвердил приём,
+// nack брокера — usecase.ErrEventRejected.
+func (p *AggregatorPublisher) PublishEvent(ctx context.Context, ev domain.OutboxEvent) error {
+	err := p.pub.Publish(ctx,
+		p.exName,      // exchange
+		ev.RoutingKey, // routing key
 		amqp.Publishing{
-			ContentType: "application/json",
-			Body:        body,
+			ContentType:  "application/json",
+			DeliveryMode: amqp.Persistent,
+			MessageId:    ev.EventID,
+			Timestamp:    ev.CreatedAt,
+			Body:         ev.Payload,
 		},
 	)
-	if err != nil {
-		return err
+	if errors.Is(err, mq.ErrPublishNacked) {
+		return fmt.Errorf("%w: %v", usecase.ErrEventRejected, err)
 	}
-	p.logger.Printf("Sent price_updated event for shop=%s component=%s", shopID, componentID)
-	return nil
-}
-
-func (p *AggregatorPublisher) PublishPriceChanged(
-	componentID string,
-	shopID int64,
-	oldPrice, newPrice float64,
-) error {
-	// Собираем структуру, совпадающую с JSON-схемой
-	evt := struct {
-		ComponentID string  `json:"componentId"`
-		ShopID      int64   `json:"shopId"`
-		OldPrice    float64 `json:"oldPrice"`
-		NewPrice    float64 `json:"newPrice"`
-	}{
-		ComponentID: componentID,
-		ShopID:      shopID,
-		OldPrice:    oldPrice,
-		NewPrice:    newPrice,
-	}
-	body, err := json.Marshal(evt)
-	if err != nil {
-		return err
-	}
-	// Публикуем в ту же очередь / exchange, где слушает Notifications Service
-	return p.ch.Publish(
-		p.exName,        // exchange
-		"price.changed", // routing key
-		false, false,
-		amqp.Publishing{
-			ContentType: "application/json",
-			Body:        body,
-		},
-	)
+	return err
 }
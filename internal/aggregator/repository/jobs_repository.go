@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"StartupPCConfigurator/internal/domain"
+)
+
+const importJobColumns = `id, COALESCE(ref, ''), status, COALESCE(file_name, ''), COALESCE(shop_code, ''),
+       dry_run, rows_total, rows_processed, rows_imported, rows_skipped,
+       COALESCE(message, ''), created_at, started_at, finished_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanImportJob(row rowScanner, extra ...interface{}) (domain.ImportJob, error) {
+	var (
+		j                 domain.ImportJob
+		started, finished sql.NullTime
+	)
+	dest := []interface{}{&j.ID, &j.Ref, &j.Status, &j.FileName, &j.ShopCode,
+		&j.DryRun, &j.RowsTotal, &j.RowsProcessed, &j.Imported, &j.Skipped,
+		&j.Error, &j.CreatedAt, &started, &finished}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return domain.ImportJob{}, err
+	}
+	if started.Valid {
+		j.StartedAt = &started.Time
+	}
+	if finished.Valid {
+		j.FinishedAt = &finished.Time
+	}
+	return j, nil
+}
+
+// CreateImportJob записывает задачу импорта. Задача с тем же ref (повторная
+// доставка сообщения) переиспользуется и начинается заново.
+func (r *repoImpl) CreateImportJob(ctx context.Context, job domain.ImportJob) (int64, error) {
+	const q = `
+INSERT INTO update_jobs (kind, ref, status, file_name, shop_code, dry_run, started_at)
+VALUES ($1, NULLIF($2, ''), $3, $4, NULLIF($5, ''), $6, NOW())
+ON CONFLICT (ref) DO UPDATE
+   SET status = EXCLUDED.status, started_at = NOW(), finished_at = NULL, message = NULL,
+       rows_total = 0, rows_processed = 0, rows_imported = 0, rows_skipped = 0, report = NULL
+RETURNING id`
+	var id int64
+	err := r.db.QueryRowContext(ctx, q, domain.JobKindPriceImport, job.Ref, job.Status,
+		job.FileName, job.ShopCode, job.DryRun).Scan(&id)
+	return id, err
+}
+
+// UpdateImportJobProgress сохраняет, сколько строк уже разобрано
+func (r *repoImpl) UpdateImportJobProgress(ctx context.Context, id int64, processed, total int) error {
+	const q = `UPDATE update_jobs SET rows_processed = $2, rows_total = $3 WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, q, id, processed, total)
+	return err
+}
+
+// FinishImportJob закрывает задачу импорта с итоговым отчётом или ошибкой
+func (r *repoImpl) FinishImportJob(ctx context.Context, id int64, status string, report *domain.PriceImportReport, errMsg string) error {
+	var raw []byte
+	var imported, skipped, total int
+	if report != nil {
+		var err error
+		if raw, err = json.Marshal(report); err != nil {
+			return err
+		}
+		imported, skipped, total = report.Imported, report.Skipped, report.Rows
+	}
+	const q = `
+UPDATE update_jobs
+   SET status = $2, finished_at = NOW(), message = NULLIF($3, ''), report = $4,
+       rows_imported = $5, rows_skipped = $6,
+       rows_total = GREATEST(rows_total, $7), rows_processed = GREATEST(rows_processed, $7)
+ WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, q, id, status, errMsg, raw, imported, skipped, total)
+	return err
+}
+
+// GetImportJob возвращает задачу импорта вместе с отчётом
+func (r *repoImpl) GetImportJob(ctx context.Context, id int64) (domain.ImportJob, error) {
+	return r.getImportJob(ctx, `id = $1`, id)
+}
+
+// GetImportJobByRef ищет задачу по ref, выданному importer'ом
+func (r *repoImpl) GetImportJobByRef(ctx context.Context, ref string) (domain.ImportJob, error) {
+	return r.getImportJob(ctx, `ref = $1`, ref)
+}
+
+func (r *repoImpl) getImportJob(ctx context.Context, where string, arg interface{}) (domain.ImportJob, error) {
+	q := `SELECT ` + importJobColumns + `, report FROM update_jobs WHERE kind = $2 AND ` + where
+	var raw []byte
+	j, err := scanImportJob(r.db.QueryRowContext(ctx, q, arg, domain.JobKindPriceImport), &raw)
+	if err == sql.ErrNoRows {
+		return domain.ImportJob{}, domain.ErrJobNotFound
+	}
+	if err != nil {
+		return domain.ImportJob{}, err
+	}
+	if len(raw) > 0 {
+		j.Report = &domain.PriceImportReport{}
+		if err := json.Unmarshal(raw, j.Report); err != nil {
+			return domain.ImportJob{}, err
+		}
+	}
+	return j, nil
+}
+
+// ListImportJobs возвращает последние задачи импорта, без отчётов
+func (r *repoImpl) ListImportJobs(ctx context.Context, status string, limit int) ([]domain.ImportJob, error) {
+	q := `SELECT ` + importJobColumns + `
+  FROM update_jobs
+ WHERE kind = $1 AND ($2 = '' OR status = $2)
+ ORDER BY created_at DESC, id DESC
+ LIMIT $3`
+	rows, err := r.db.QueryContext(ctx, q, domain.JobKindPriceImport, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []domain.ImportJob{}
+	for rows.Next() {
+		j, err := scanImportJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, j)
+	}
+	return out, rows.Err()
+}
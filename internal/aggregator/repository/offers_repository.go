@@ -1,11 +1,12 @@
 package repository
 
 import (
+	"StartupPCConfigurator/internal/aggregator/normalize"
 	"StartupPCConfigurator/internal/aggregator/usecase"
 	"context"
 	"database/sql"
 
-	_ "github.com/lib/pq" // драйвер PostgreSQL
+	"github.com/lib/pq" // драйвер PostgreSQL
 
 	"StartupPCConfigurator/internal/domain"
 )
@@ -42,6 +43,8 @@ SELECT
     o.price,
     o.currency,
     o.availability,
+    o.availability_status,
+    o.store_count,
     o.url,
     o.fetched_at
 FROM offers o
@@ -68,6 +71,7 @@ WHERE o.component_id = $1
 	var out []domain.Offer
 	for rows.Next() {
 		var of domain.Offer
+		var stores sql.NullInt64
 		of.ComponentID = filter.ComponentID
 		if err := rows.Scan(
 			&of.ComponentID,
@@ -77,11 +81,17 @@ WHERE o.component_id = $1
 			&of.Price,
 			&of.Currency,
 			&of.Availability,
+			&of.AvailabilityStatus,
+			&stores,
 			&of.URL,
 			&of.FetchedAt,
 		); err != nil {
 			return nil, err
 		}
+		if stores.Valid {
+			n := int(stores.Int64)
+			of.StoreCount = &n
+		}
 		out = append(out, of)
 	}
 	return out, rows.Err()
@@ -101,9 +111,10 @@ type ShopComponent struct {
 // ListShopComponents возвращает все componentID+URL для shopID
 func (r *repoImpl) ListShopComponents(ctx context.Context, shopID int64) ([]usecase.ShopComponent, error) {
 	const q = `
-SELECT component_id, url
+SELECT COALESCE(component_id::text, ''), url
 FROM shop_components
 WHERE shop_id = $1
+ORDER BY id
 `
 	rows, err := r.db.QueryContext(ctx, q, shopID)
 	if err != nil {
@@ -122,38 +133,64 @@ WHERE shop_id = $1
 	return res, rows.Err()
 }
 
-// UpsertOffer вставляет или обновляет запись в offers
-func (r *repoImpl) UpsertOffer(ctx context.Context,
+// SaveOffer одной транзакцией обновляет offers, пишет price_history и
+// события об изменении цены и наличия в outbox
+func (r *repoImpl) SaveOffer(ctx context.Context, u usecase.OfferUpdate) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := upsertOffer(ctx, tx, u.ComponentID, u.ShopID, u.Price, u.Availability, u.URL); err != nil {
+		return err
+	}
+	if err := insertPriceHistory(ctx, tx, u.ComponentID, u.ShopID, u.Price); err != nil {
+		return err
+	}
+	for _, ev := range u.Events {
+		if err := insertOutboxEvent(ctx, tx, ev); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// upsertOffer вставляет или обновляет запись в offers
+func upsertOffer(ctx context.Context, tx *sql.Tx,
 	compID string, shopID int64,
-	price float64, availability, url string,
+	price normalize.Price, avail normalize.Availability, url string,
 ) error {
 	const q = `
 INSERT INTO offers
-  (component_id, shop_id, price, currency, availability, url, fetched_at)
-VALUES ($1,$2,$3,$4,$5,$6,NOW())
+  (component_id, shop_id, price, currency, availability, availability_status, store_count, url, fetched_at)
+VALUES ($1,$2,$3,$4,$5,$6,$7,$8,NOW())
 ON CONFLICT (component_id, shop_id) DO UPDATE
-  SET price        = EXCLUDED.price,
-      currency     = EXCLUDED.currency,
-      availability = EXCLUDED.availability,
-      url          = EXCLUDED.url,
-      fetched_at   = EXCLUDED.fetched_at
+  SET price               = EXCLUDED.price,
+      currency            = EXCLUDED.currency,
+      availability        = EXCLUDED.availability,
+      availability_status = EXCLUDED.availability_status,
+      store_count         = EXCLUDED.store_count,
+      url                 = EXCLUDED.url,
+      fetched_at          = EXCLUDED.fetched_at
 `
-	_, err := r.db.ExecContext(ctx, q,
-		compID, shopID, price, "USD", availability, url,
+	_, err := tx.ExecContext(ctx, q,
+		compID, shopID, price.Amount, price.Currency,
+		avail.Text, string(avail.Status), avail.StoreCount, url,
 	)
 	return err
 }
 
-// InsertPriceHistory пишет запись в price_history
-func (r *repoImpl) InsertPriceHistory(ctx context.Context,
-	compID string, shopID int64, price float64,
+// insertPriceHistory пишет запись в price_history
+func insertPriceHistory(ctx context.Context, tx *sql.Tx,
+	compID string, shopID int64, price normalize.Price,
 ) error {
 	const q = `
 INSERT INTO price_history
   (component_id, shop_id, price, currency, captured_at)
 VALUES ($1,$2,$3,$4,NOW())
 `
-	_, err := r.db.ExecContext(ctx, q, compID, shopID, price, "USD")
+	_, err := tx.ExecContext(ctx, q, compID, shopID, price.Amount, price.Currency)
 	return err
 }
 
@@ -200,13 +237,15 @@ func (r *repoImpl) BulkUpsertOffers(
 
 	stmt, err := tx.PrepareContext(ctx, `
 INSERT INTO offers
-  (component_id, shop_id, price, currency, availability, url, fetched_at)
+  (component_id, shop_id, price, currency, availability, availability_status, store_count, url, fetched_at)
 VALUES
-  ($1, (SELECT id FROM shops WHERE code = $2), $3, $4, $5, $6, NOW())
+  ($1, (SELECT id FROM shops WHERE code = $2), $3, $4, $5, $6, $7, $8, NOW())
 ON CONFLICT (component_id, shop_id) DO UPDATE
   SET price = EXCLUDED.price,
       currency = EXCLUDED.currency,
       availability = EXCLUDED.availability,
+      availability_status = EXCLUDED.availability_status,
+      store_count = EXCLUDED.store_count,
       url = EXCLUDED.url,
       fetched_at = EXCLUDED.fetched_at
 `)
@@ -216,13 +255,16 @@ ON CONFLICT (component_id, shop_id) DO UPDATE
 	defer stmt.Close()
 
 	for _, r := range recs {
+		avail := normalize.ParseAvailability(r.Availability)
 		if _, err := stmt.ExecContext(
 			ctx,
 			r.ComponentID,
 			r.ShopCode,
 			r.Price,
 			r.Currency,
-			r.Availability,
+			avail.Text,
+			string(avail.Status),
+			avail.StoreCount,
 			r.URL,
 		); err != nil {
 			return err
@@ -233,38 +275,116 @@ ON CONFLICT (component_id, shop_id) DO UPDATE
 	return tx.Commit()
 }
 
-func (r *repoImpl) GetOfferPrice(ctx context.Context, compID string, shopID int64) (float64, error) {
+// GetOfferState возвращает текущие цену и наличие оффера
+func (r *repoImpl) GetOfferState(ctx context.Context, compID string, shopID int64) (usecase.OfferState, error) {
 	const q = `
-SELECT price
+SELECT price, availability_status
   FROM offers
  WHERE component_id = $1
    AND shop_id      = $2
 `
-	var price float64
-	err := r.db.QueryRowContext(ctx, q, compID, shopID).Scan(&price)
+	var (
+		st     usecase.OfferState
+		status string
+	)
+	err := r.db.QueryRowContext(ctx, q, compID, shopID).Scan(&st.Price, &status)
 	if err == sql.ErrNoRows {
-		return 0, nil
+		return usecase.OfferState{}, nil
 	}
 	if err != nil {
-		return 0, err
+		return usecase.OfferState{}, err
 	}
-	return price, nil
+	st.Exists = true
+	st.Availability = domain.AvailabilityStatus(status)
+	return st, nil
 }
 
+// GetShopIDByCode ищет магазин по коду без учёта регистра: в прайс-листах пишут и "DNS", и "dns"
 func (r *repoImpl) GetShopIDByCode(ctx context.Context, code string) (int64, error) {
-	const q = `SELECT id FROM shops WHERE code = $1`
+	const q = `SELECT id FROM shops WHERE lower(code) = lower($1)`
 	var id int64
-	if err := r.db.QueryRowContext(ctx, q, code).Scan(&id); err != nil {
+	err := r.db.QueryRowContext(ctx, q, code).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, domain.ErrShopNotFound
+	}
+	if err != nil {
 		return 0, err
 	}
 	return id, nil
 }
 
+// GetPriceListMapping возвращает shops.price_list_mapping (nil — не задана)
+func (r *repoImpl) GetPriceListMapping(ctx context.Context, shopID int64) ([]byte, error) {
+	const q = `SELECT price_list_mapping FROM shops WHERE id = $1`
+	var raw []byte
+	err := r.db.QueryRowContext(ctx, q, shopID).Scan(&raw)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrShopNotFound
+	}
+	return raw, err
+}
+
+// ExistingComponentIDs отмечает, какие из id есть в каталоге
+func (r *repoImpl) ExistingComponentIDs(ctx context.Context, ids []string) (map[string]bool, error) {
+	out := make(map[string]bool, len(ids))
+	if len(ids) == 0 {
+		return out, nil
+	}
+	const q = `SELECT id::text FROM components WHERE id::text = ANY($1)`
+	rows, err := r.db.QueryContext(ctx, q, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		out[id] = true
+	}
+	return out, rows.Err()
+}
+
+// GetShopCode возвращает shops.code — по нему выбирается парсер магазина
+func (r *repoImpl) GetShopCode(ctx context.Context, shopID int64) (string, error) {
+	const q = `SELECT code FROM shops WHERE id = $1`
+	var code string
+	if err := r.db.QueryRowContext(ctx, q, shopID).Scan(&code); err != nil {
+		return "", err
+	}
+	return code, nil
+}
+
+// ListShopParserProfiles возвращает заполненные shops.parser_profile по коду магазина
+func (r *repoImpl) ListShopParserProfiles(ctx context.Context) (map[string][]byte, error) {
+	const q = `SELECT code, parser_profile FROM shops WHERE parser_profile IS NOT NULL AND is_active`
+	rows, err := r.db.QueryContext(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	profiles := make(map[string][]byte)
+	for rows.Next() {
+		var code string
+		var profile []byte
+		if err := rows.Scan(&code, &profile); err != nil {
+			return nil, err
+		}
+		profiles[code] = profile
+	}
+	return profiles, rows.Err()
+}
+
+// GetMinPrice — минимальная цена без предложений, которых нет в продаже
+// (out_of_stock, preorder)
 func (r *repoImpl) GetMinPrice(ctx context.Context, componentID string) (float64, string, error) {
 	const q = `
       SELECT MIN(o.price), o.currency
         FROM offers o
        WHERE o.component_id = $1
+         AND o.availability_status NOT IN ('out_of_stock', 'preorder')
        GROUP BY o.currency`
 	var minPrice float64
 	var currency string
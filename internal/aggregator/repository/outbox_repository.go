@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"StartupPCConfigurator/internal/aggregator/usecase"
+	"StartupPCConfigurator/internal/domain"
+)
+
+// insertOutboxEvent пишет событие в outbox в транзакции изменения данных
+func insertOutboxEvent(ctx context.Context, tx *sql.Tx, ev domain.OutboxEvent) error {
+	const q = `INSERT INTO outbox (event_id, routing_key, payload) VALUES ($1, $2, $3)`
+	_, err := tx.ExecContext(ctx, q, ev.EventID, ev.RoutingKey, []byte(ev.Payload))
+	return err
+}
+
+// RelayOutbox блокирует пачку неотправленных событий (другие экземпляры
+// агрегатора её пропустят), отправляет по порядку и ставит sent_at.
+// На первой ошибке отправки останавливается, чтобы не нарушить порядок.
+// Событие, которое брокер отверг (usecase.ErrEventRejected) на maxAttempts-й
+// попытке, откладывается: ставится failed_at, relay его больше не берёт,
+// а вернётся *usecase.OutboxParkedError.
+func (r *repoImpl) RelayOutbox(ctx context.Context, limit, maxAttempts int, send func(domain.OutboxEvent) error) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	const q = `
+SELECT id, event_id, routing_key, payload, attempts, created_at
+  FROM outbox
+ WHERE sent_at IS NULL AND failed_at IS NULL
+ ORDER BY id
+ LIMIT $1
+   FOR UPDATE SKIP LOCKED`
+	rows, err := tx.QueryContext(ctx, q, limit)
+	if err != nil {
+		return 0, err
+	}
+	var events []domain.OutboxEvent
+	for rows.Next() {
+		var (
+			ev      domain.OutboxEvent
+			payload []byte
+		)
+		if err := rows.Scan(&ev.ID, &ev.EventID, &ev.RoutingKey, &payload, &ev.Attempts, &ev.CreatedAt); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ev.Payload = payload
+		events = append(events, ev)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	var sendErr error
+	for _, ev := range events {
+		if err := send(ev); err != nil {
+			sendErr = fmt.Errorf("event %s: %w", ev.EventID, err)
+			break
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE outbox SET sent_at = NOW() WHERE id = $1`, ev.ID); err != nil {
+			return 0, err
+		}
+		sent++
+	}
+	if sendErr != nil {
+		ev := events[sent]
+		park := errors.Is(sendErr, usecase.ErrEventRejected) && ev.Attempts+1 >= maxAttempts
+		if _, err := tx.ExecContext(ctx, `
+UPDATE outbox
+   SET attempts = attempts + 1, last_error = $2,
+       failed_at = CASE WHEN $3::boolean THEN NOW() END
+ WHERE id = $1`,
+			ev.ID, sendErr.Error(), park,
+		); err != nil {
+			return 0, err
+		}
+		if park {
+			sendErr = &usecase.OutboxParkedError{EventID: ev.EventID, RoutingKey: ev.RoutingKey, Attempts: ev.Attempts + 1, Err: sendErr}
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return sent, sendErr
+}
+
+// PurgeOutbox удаляет отправленные события старше olderThan
+func (r *repoImpl) PurgeOutbox(ctx context.Context, olderThan time.Duration) (int64, error) {
+	res, err := r.db.ExecContext(ctx,
+		`DELETE FROM outbox WHERE sent_at IS NOT NULL AND sent_at < $1`, time.Now().Add(-olderThan))
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+
+	"StartupPCConfigurator/internal/domain"
+)
+
+// ---------------------------------------
+// Обогащение каталога: предложения и фото
+// ---------------------------------------
+
+// GetComponentSpecs возвращает категорию и specs компонента каталога
+func (r *repoImpl) GetComponentSpecs(ctx context.Context, compID string) (string, json.RawMessage, error) {
+	const q = `SELECT category, COALESCE(specs, '{}'::jsonb) FROM components WHERE id = $1`
+	var category string
+	var specs []byte
+	if err := r.db.QueryRowContext(ctx, q, compID).Scan(&category, &specs); err != nil {
+		return "", nil, err
+	}
+	return category, specs, nil
+}
+
+// SaveSpecProposal создаёт предложение или обновляет открытое предложение
+// для той же страницы магазина
+func (r *repoImpl) SaveSpecProposal(ctx context.Context, p domain.SpecProposal) (int64, error) {
+	changes, err := json.Marshal(nonNil(p.Changes))
+	if err != nil {
+		return 0, err
+	}
+	unmapped, err := json.Marshal(nonNil(p.Unmapped))
+	if err != nil {
+		return 0, err
+	}
+	const q = `
+INSERT INTO spec_proposals (component_id, shop_id, url, category, name, changes, unmapped)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (shop_id, url) WHERE status = 'pending' DO UPDATE
+  SET component_id = EXCLUDED.component_id,
+      category     = EXCLUDED.category,
+      name         = EXCLUDED.name,
+      changes      = EXCLUDED.changes,
+      unmapped     = EXCLUDED.unmapped,
+      updated_at   = NOW()
+RETURNING id
+`
+	var id int64
+	err = r.db.QueryRowContext(ctx, q,
+		p.ComponentID, p.ShopID, p.URL, p.Category, p.Name, changes, unmapped,
+	).Scan(&id)
+	return id, err
+}
+
+// DeleteSpecProposal удаляет открытое предложение страницы магазина
+func (r *repoImpl) DeleteSpecProposal(ctx context.Context, shopID int64, url string) error {
+	const q = `DELETE FROM spec_proposals WHERE shop_id = $1 AND url = $2 AND status = 'pending'`
+	_, err := r.db.ExecContext(ctx, q, shopID, url)
+	return err
+}
+
+// ListPendingSpecProposals — открытые предложения по компоненту от всех магазинов
+func (r *repoImpl) ListPendingSpecProposals(ctx context.Context, componentID int) ([]domain.SpecProposal, error) {
+	const q = `
+SELECT p.id, p.shop_id, s.code, p.changes
+  FROM spec_proposals p
+  JOIN shops s ON s.id = p.shop_id
+ WHERE p.component_id = $1
+   AND p.status = 'pending'
+ ORDER BY p.id
+`
+	rows, err := r.db.QueryContext(ctx, q, componentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.SpecProposal
+	for rows.Next() {
+		p := domain.SpecProposal{ComponentID: &componentID, Status: domain.ProposalPending}
+		var changes []byte
+		if err := rows.Scan(&p.ID, &p.ShopID, &p.ShopCode, &changes); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(changes, &p.Changes); err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+// SetSpecProposalConflicts перезаписывает конфликты предложения
+func (r *repoImpl) SetSpecProposalConflicts(ctx context.Context, id int64, conflicts []domain.SpecConflict) error {
+	data, err := json.Marshal(nonNil(conflicts))
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, `UPDATE spec_proposals SET conflicts = $2 WHERE id = $1`, id, data)
+	return err
+}
+
+// ReplaceComponentImages заменяет фото компонента, полученные из магазина
+func (r *repoImpl) ReplaceComponentImages(ctx context.Context,
+	compID string, shopID int64, mainImage string, images []string,
+) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		`DELETE FROM component_images WHERE component_id = $1 AND shop_id = $2`, compID, shopID,
+	); err != nil {
+		return err
+	}
+
+	const q = `
+INSERT INTO component_images (component_id, shop_id, url, is_main, position)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (component_id, url) DO NOTHING
+`
+	urls := images
+	if mainImage != "" {
+		urls = append([]string{mainImage}, images...)
+	}
+	for i, u := range urls {
+		if u == "" {
+			continue
+		}
+		isMain := mainImage != "" && i == 0
+		if _, err := tx.ExecContext(ctx, q, compID, shopID, u, isMain, i); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// nonNil — пустой срез вместо nil, чтобы в JSONB попадал [], а не null
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
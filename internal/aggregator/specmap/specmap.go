@@ -0,0 +1,227 @@
+// Package specmap переводит строки характеристик со страниц магазинов
+// («Сокет: AM4», «Длина видеокарты: 250 мм») в ключи specs каталога
+// и приводит значения к каноническому виду через domain.NormalizeSpecs.
+package specmap
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"StartupPCConfigurator/internal/domain"
+)
+
+// Rule — названия строк магазинов и ключи, в которые они переходят
+type Rule struct {
+	Labels []string `yaml:"labels"`
+	Keys   []string `yaml:"keys"`
+}
+
+// CategoryRule — подстроки текста хлебных крошек, по которым узнаём категорию
+type CategoryRule struct {
+	Category string   `yaml:"category"`
+	Match    []string `yaml:"match"`
+}
+
+type file struct {
+	Categories []CategoryRule `yaml:"categories"`
+	Specs      []Rule         `yaml:"specs"`
+}
+
+// Mapper — правила сопоставления
+type Mapper struct {
+	categories []categoryHint
+	labels     map[string][]string // нормализованное название → ключи
+}
+
+type categoryHint struct {
+	category domain.ComponentCategory
+	needle   string
+}
+
+// Result — что удалось извлечь из строк характеристик
+type Result struct {
+	Specs    map[string]interface{} // только значения, прошедшие нормализацию
+	Errors   []domain.SpecError     // значения, которые не удалось привести к схеме
+	Unmapped []domain.SpecRow       // строки без правила
+}
+
+//go:embed specmap.yaml
+var defaultRules []byte
+
+// Default — правила из встроенного specmap.yaml
+var Default = mustParse(defaultRules)
+
+func mustParse(data []byte) *Mapper {
+	m, err := Parse(data)
+	if err != nil {
+		panic(fmt.Sprintf("встроенные правила specmap: %v", err))
+	}
+	return m
+}
+
+// Load читает правила из YAML-файла
+func Load(path string) (*Mapper, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse разбирает и проверяет правила
+func Parse(data []byte) (*Mapper, error) {
+	var f file
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, err
+	}
+	m := &Mapper{labels: make(map[string][]string)}
+
+	for _, c := range f.Categories {
+		if !domain.IsValidCategory(c.Category) {
+			return nil, fmt.Errorf("unknown category %q", c.Category)
+		}
+		for _, needle := range c.Match {
+			m.categories = append(m.categories, categoryHint{domain.ComponentCategory(c.Category), normalizeLabel(needle)})
+		}
+	}
+
+	for i, r := range f.Specs {
+		if len(r.Labels) == 0 || len(r.Keys) == 0 {
+			return nil, fmt.Errorf("spec rule #%d: labels and keys are required", i)
+		}
+		for _, k := range r.Keys {
+			if !knownKey(k) {
+				return nil, fmt.Errorf("spec rule #%d: key %q is not in any category schema", i, k)
+			}
+		}
+		for _, l := range r.Labels {
+			l = normalizeLabel(l)
+			m.labels[l] = append(m.labels[l], r.Keys...)
+		}
+	}
+	return m, nil
+}
+
+// Category определяет категорию по тексту хлебных крошек («Видеокарты»)
+func (m *Mapper) Category(text string) (domain.ComponentCategory, bool) {
+	t := normalizeLabel(text)
+	if t == "" {
+		return "", false
+	}
+	for _, h := range m.categories {
+		if strings.Contains(t, h.needle) {
+			return h.category, true
+		}
+	}
+	return "", false
+}
+
+// Map переводит строки характеристик в specs категории. Если одна и та же
+// характеристика встречается несколько раз, берётся первая строка.
+func (m *Mapper) Map(category domain.ComponentCategory, rows []domain.SpecRow) Result {
+	types := domain.SpecTypes[category]
+	raw := make(map[string]interface{})
+	var res Result
+	for _, row := range rows {
+		key := ""
+		for _, k := range m.labels[normalizeLabel(row.Name)] {
+			if _, ok := types[k]; ok {
+				key = k
+				break
+			}
+		}
+		if key == "" {
+			res.Unmapped = append(res.Unmapped, row)
+			continue
+		}
+		if _, seen := raw[key]; !seen {
+			raw[key] = strings.TrimSpace(row.Value)
+		}
+	}
+
+	specs, errs := domain.NormalizeSpecs(category, raw)
+	for _, e := range errs {
+		delete(specs, e.Key) // в предложение попадают только понятные значения
+	}
+	res.Specs, res.Errors = specs, errs
+	return res
+}
+
+// Diff — значения из specs, которых нет в каталоге или которые от него отличаются.
+// current — specs компонента из каталога.
+func Diff(category domain.ComponentCategory, current, specs map[string]interface{}) []domain.SpecChange {
+	cur, _ := domain.NormalizeSpecs(category, current)
+	keys := make([]string, 0, len(specs))
+	for k := range specs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var changes []domain.SpecChange
+	for _, k := range keys {
+		old, ok := cur[k]
+		if ok && SameValue(old, specs[k]) {
+			continue
+		}
+		ch := domain.SpecChange{Key: k, Proposed: specs[k]}
+		if ok {
+			ch.Current = old
+		}
+		changes = append(changes, ch)
+	}
+	return changes
+}
+
+// SameValue сравнивает нормализованные значения: 8 и 8.0, ["AM4"] и ["AM4"]
+func SameValue(a, b interface{}) bool {
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+// Conflicts находит ключи, для которых предложения разных магазинов по
+// одному компоненту расходятся. Возвращает конфликты по ID предложения.
+func Conflicts(proposals []domain.SpecProposal) map[int64][]domain.SpecConflict {
+	out := make(map[int64][]domain.SpecConflict, len(proposals))
+	for _, p := range proposals {
+		out[p.ID] = []domain.SpecConflict{}
+		for _, ch := range p.Changes {
+			for _, other := range proposals {
+				if other.ID == p.ID || other.ShopID == p.ShopID {
+					continue
+				}
+				for _, och := range other.Changes {
+					if och.Key == ch.Key && !SameValue(och.Proposed, ch.Proposed) {
+						out[p.ID] = append(out[p.ID], domain.SpecConflict{
+							Key:        ch.Key,
+							ShopCode:   other.ShopCode,
+							Value:      och.Proposed,
+							ProposalID: other.ID,
+						})
+					}
+				}
+			}
+		}
+	}
+	return out
+}
+
+func knownKey(key string) bool {
+	for _, types := range domain.SpecTypes {
+		if _, ok := types[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeLabel — «Сокет:» → «сокет», «Объём памяти» → «объем памяти»
+func normalizeLabel(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = strings.TrimSuffix(s, ":")
+	s = strings.ReplaceAll(s, "ё", "е")
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,97 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"StartupPCConfigurator/internal/aggregator/specmap"
+	"StartupPCConfigurator/internal/domain"
+)
+
+// enrich сохраняет фото товара и отправляет характеристики со страницы на
+// проверку. Каталог напрямую не меняется: значения, которых в нём нет или
+// которые отличаются, становятся предложением (spec_proposals), а расхождения
+// между магазинами помечаются конфликтами.
+func (uc *updateUseCase) enrich(ctx context.Context, shopID int64, it ShopComponent, parsed *ParsedItem) error {
+	if uc.specs == nil {
+		return nil
+	}
+	if it.ComponentID != "" && (parsed.MainImage != "" || len(parsed.Images) > 0) {
+		if err := uc.repo.ReplaceComponentImages(ctx, it.ComponentID, shopID, parsed.MainImage, parsed.Images); err != nil {
+			return fmt.Errorf("save images: %w", err)
+		}
+	}
+	if len(parsed.Specs) == 0 {
+		return nil
+	}
+
+	p := domain.SpecProposal{ShopID: shopID, URL: it.URL, Name: parsed.Name, Status: domain.ProposalPending}
+	var (
+		category domain.ComponentCategory
+		current  map[string]interface{}
+	)
+	if it.ComponentID != "" {
+		id, err := strconv.Atoi(it.ComponentID)
+		if err != nil {
+			return fmt.Errorf("component id %q: %w", it.ComponentID, err)
+		}
+		cat, raw, err := uc.repo.GetComponentSpecs(ctx, it.ComponentID)
+		if err != nil {
+			return fmt.Errorf("component %s: %w", it.ComponentID, err)
+		}
+		if len(raw) > 0 {
+			if err := json.Unmarshal(raw, &current); err != nil {
+				return fmt.Errorf("component %s specs: %w", it.ComponentID, err)
+			}
+		}
+		p.ComponentID = &id
+		category = domain.ComponentCategory(cat)
+	} else {
+		cat, ok := uc.specs.Category(parsed.Category)
+		if !ok {
+			return fmt.Errorf("cannot detect category of %s from %q", it.URL, parsed.Category)
+		}
+		category = cat
+	}
+	p.Category = string(category)
+
+	res := uc.specs.Map(category, parsed.Specs)
+	for _, e := range res.Errors {
+		uc.logger.Printf("spec %s from %s skipped: %v", e.Key, it.URL, e.Message)
+	}
+	p.Changes = specmap.Diff(category, current, res.Specs)
+	p.Unmapped = res.Unmapped
+
+	if p.ComponentID == nil {
+		// новый товар предлагаем даже без распознанных характеристик —
+		// название и ссылка уже полезны
+		_, err := uc.repo.SaveSpecProposal(ctx, p)
+		return err
+	}
+
+	if len(p.Changes) == 0 {
+		// всё совпадает с каталогом — старое предложение магазина больше не нужно
+		if err := uc.repo.DeleteSpecProposal(ctx, shopID, it.URL); err != nil {
+			return err
+		}
+	} else if _, err := uc.repo.SaveSpecProposal(ctx, p); err != nil {
+		return err
+	}
+	return uc.refreshConflicts(ctx, *p.ComponentID)
+}
+
+// refreshConflicts пересчитывает конфликты всех открытых предложений компонента
+func (uc *updateUseCase) refreshConflicts(ctx context.Context, componentID int) error {
+	pending, err := uc.repo.ListPendingSpecProposals(ctx, componentID)
+	if err != nil {
+		return err
+	}
+	for id, conflicts := range specmap.Conflicts(pending) {
+		if err := uc.repo.SetSpecProposalConflicts(ctx, id, conflicts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
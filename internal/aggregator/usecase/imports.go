@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"strconv"
+
+	"StartupPCConfigurator/internal/domain"
+)
+
+// RunImportJob импортирует прайс-лист как задачу update_jobs: статус,
+// прогресс и итоговый отчёт видны через GET /offers/imports/:id.
+// ref — id, выданный importer'ом до постановки в очередь (может быть пустым).
+func (uc *offersUseCase) RunImportJob(ctx context.Context, r io.Reader, opts ImportOptions, ref string) (domain.ImportJob, error) {
+	job := domain.ImportJob{
+		Ref:      ref,
+		Status:   domain.JobRunning,
+		FileName: filepath.Base(opts.Filename),
+		ShopCode: opts.ShopCode,
+		DryRun:   opts.DryRun,
+	}
+	id, err := uc.repo.CreateImportJob(ctx, job)
+	if err != nil {
+		return job, err
+	}
+	job.ID = id
+
+	progress := opts.Progress
+	opts.Progress = func(processed, total int) {
+		if err := uc.repo.UpdateImportJobProgress(ctx, id, processed, total); err != nil {
+			uc.logger.Printf("import job %d: progress: %v", id, err)
+		}
+		if progress != nil {
+			progress(processed, total)
+		}
+	}
+
+	report, importErr := uc.ImportPriceList(ctx, r, opts)
+	report.JobID = id
+	status, msg := domain.JobDone, ""
+	if importErr != nil {
+		status, msg = domain.JobFailed, importErr.Error()
+	}
+	// отчёт пишем и при ошибке: в нём видно, до чего дошёл импорт
+	if err := uc.repo.FinishImportJob(ctx, id, status, &report, msg); err != nil {
+		uc.logger.Printf("import job %d: finish: %v", id, err)
+	}
+
+	done, err := uc.repo.GetImportJob(ctx, id)
+	if err != nil {
+		done = job
+		done.Status, done.Error, done.Report = status, msg, &report
+	}
+	return done, importErr
+}
+
+// ListImportJobs возвращает последние задачи импорта
+func (uc *offersUseCase) ListImportJobs(ctx context.Context, status string, limit int) ([]domain.ImportJob, error) {
+	return uc.repo.ListImportJobs(ctx, status, limit)
+}
+
+// GetImportJob ищет задачу по числовому id или по ref от importer'а
+func (uc *offersUseCase) GetImportJob(ctx context.Context, idOrRef string) (domain.ImportJob, error) {
+	if id, err := strconv.ParseInt(idOrRef, 10, 64); err == nil {
+		return uc.repo.GetImportJob(ctx, id)
+	}
+	return uc.repo.GetImportJobByRef(ctx, idOrRef)
+}
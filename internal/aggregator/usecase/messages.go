@@ -1,6 +1,8 @@
 // internal/aggregator/usecase/messages.go
 package usecase
 
+import "StartupPCConfigurator/internal/domain"
+
 // ShopUpdateMsg — тело сообщений из очереди shop_update
 type ShopUpdateMsg struct {
 	JobID  int64  `json:"jobId"`
@@ -8,10 +10,25 @@ type ShopUpdateMsg struct {
 	Type   string `json:"type,omitempty"`
 }
 
+// PriceChangedMsg — событие price.changed; EventID одинаков при повторной доставке
 type PriceChangedMsg struct {
+	EventID     string  `json:"eventId,omitempty"`
 	ComponentID string  `json:"componentId"`
 	ShopID      int64   `json:"shopId"`
 	OldPrice    float64 `json:"oldPrice"`
 	NewPrice    float64 `json:"newPrice"`
 	Timestamp   int64   `json:"timestamp"` // тут UnixNano или Unix
 }
+
+// AvailabilityChangedMsg — событие availability.changed: товар появился
+// в продаже (InStock) или закончился
+type AvailabilityChangedMsg struct {
+	EventID     string                    `json:"eventId,omitempty"`
+	ComponentID string                    `json:"componentId"`
+	ShopID      int64                     `json:"shopId"`
+	OldStatus   domain.AvailabilityStatus `json:"oldStatus"`
+	NewStatus   domain.AvailabilityStatus `json:"newStatus"`
+	InStock     bool                      `json:"inStock"`
+	Price       float64                   `json:"price"`
+	Timestamp   int64                     `json:"timestamp"`
+}
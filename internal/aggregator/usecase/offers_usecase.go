@@ -1,49 +1,62 @@
 package usecase
 
 import (
+	"StartupPCConfigurator/internal/aggregator/normalize"
 	"StartupPCConfigurator/internal/domain"
+	"bufio"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"log"
-	"strconv"
-
-	"github.com/xuri/excelize/v2"
+	"strings"
 )
 
 // OffersRepository — только для GET /offers
 type OffersRepository interface {
 	FetchOffers(ctx context.Context, filter domain.OffersFilter) ([]domain.Offer, error)
 
-	GetOfferPrice(ctx context.Context, componentID string, shopID int64) (float64, error)
+	GetOfferState(ctx context.Context, componentID string, shopID int64) (OfferState, error)
 	GetMinPrice(ctx context.Context, componentID string) (float64, string, error)
-	UpsertOffer(ctx context.Context, componentID string, shopID int64, price float64, availability, url string) error
-	InsertPriceHistory(ctx context.Context, componentID string, shopID int64, price float64) error
+	SaveOffer(ctx context.Context, u OfferUpdate) error
 	GetShopIDByCode(ctx context.Context, code string) (int64, error)
+	GetPriceListMapping(ctx context.Context, shopID int64) ([]byte, error)
+	ExistingComponentIDs(ctx context.Context, ids []string) (map[string]bool, error)
+
+	// задачи импорта в update_jobs (imports.go)
+	CreateImportJob(ctx context.Context, job domain.ImportJob) (int64, error)
+	UpdateImportJobProgress(ctx context.Context, id int64, processed, total int) error
+	FinishImportJob(ctx context.Context, id int64, status string, report *domain.PriceImportReport, errMsg string) error
+	GetImportJob(ctx context.Context, id int64) (domain.ImportJob, error)
+	GetImportJobByRef(ctx context.Context, ref string) (domain.ImportJob, error)
+	ListImportJobs(ctx context.Context, status string, limit int) ([]domain.ImportJob, error)
 }
 
 type OffersUseCase interface {
 	GetOffers(ctx context.Context, filter domain.OffersFilter) ([]domain.Offer, error)
 	GetMinPrice(ctx context.Context, componentID string) (float64, string, error)
-	ImportPriceList(ctx context.Context, records io.Reader) error
+	ImportPriceList(ctx context.Context, r io.Reader, opts ImportOptions) (domain.PriceImportReport, error)
+
+	// задачи импорта (imports.go)
+	RunImportJob(ctx context.Context, r io.Reader, opts ImportOptions, ref string) (domain.ImportJob, error)
+	ListImportJobs(ctx context.Context, status string, limit int) ([]domain.ImportJob, error)
+	GetImportJob(ctx context.Context, idOrRef string) (domain.ImportJob, error)
 }
 
 type offersUseCase struct {
-	repo      OffersRepository
-	publisher Publisher
-	logger    *log.Logger
+	repo   OffersRepository
+	logger *log.Logger
 }
 
+// NewOffersUseCase — события price.changed пишутся в outbox, публикует их OutboxRelay
 func NewOffersUseCase(
 	repo OffersRepository,
-	pub Publisher,
 	logger *log.Logger,
 ) OffersUseCase {
 	return &offersUseCase{
-		repo:      repo,
-		publisher: pub,
-		logger:    logger,
+		repo:   repo,
+		logger: logger,
 	}
 }
 
@@ -58,47 +71,198 @@ func (uc *offersUseCase) GetMinPrice(ctx context.Context, componentID string) (f
 	return uc.repo.GetMinPrice(ctx, componentID)
 }
 
-func (uc *offersUseCase) ImportPriceList(ctx context.Context, r io.Reader) error {
-	// 1. Читаем Excel
-	f, err := excelize.OpenReader(r)
+// ImportPriceList загружает прайс-лист (CSV или XLSX) в offers и возвращает
+// отчёт по строкам. Ошибка в строке не прерывает импорт, а попадает в отчёт.
+func (uc *offersUseCase) ImportPriceList(ctx context.Context, r io.Reader, opts ImportOptions) (domain.PriceImportReport, error) {
+	report := domain.PriceImportReport{
+		DryRun:            opts.DryRun,
+		UnknownShops:      []string{},
+		UnknownComponents: []string{},
+		PriceChanges:      []domain.PriceChange{},
+		SkippedRows:       []domain.PriceImportSkip{},
+	}
+
+	// 1. Раскладка: из запроса, из настроек магазина или по умолчанию
+	var fixedShopID int64
+	mapping := DefaultPriceListMapping
+	if opts.ShopCode != "" {
+		id, err := uc.repo.GetShopIDByCode(ctx, opts.ShopCode)
+		if errors.Is(err, domain.ErrShopNotFound) {
+			return report, fmt.Errorf("%w: unknown shop %q", ErrBadPriceList, opts.ShopCode)
+		}
+		if err != nil {
+			return report, err
+		}
+		fixedShopID = id
+		if opts.Mapping == nil {
+			raw, err := uc.repo.GetPriceListMapping(ctx, id)
+			if err != nil {
+				return report, err
+			}
+			if len(raw) > 0 {
+				if err := json.Unmarshal(raw, &mapping); err != nil {
+					return report, fmt.Errorf("shop %s: price_list_mapping: %w", opts.ShopCode, err)
+				}
+			}
+		}
+	}
+	if opts.Mapping != nil {
+		mapping = *opts.Mapping
+	}
+	if opts.Sheet != "" {
+		mapping.Sheet = opts.Sheet
+	}
+
+	// 2. Читаем файл
+	br := bufio.NewReader(r)
+	head, _ := br.Peek(4)
+	format, err := detectFormat(opts.Format, opts.Filename, head)
 	if err != nil {
-		return fmt.Errorf("cannot read excel: %w", err)
+		return report, err
 	}
-	rows, err := f.GetRows("Sheet1") // или по имени/индексу
+	report.Format = format
+	rows, sheet, err := readPriceList(br, format, mapping)
 	if err != nil {
-		return fmt.Errorf("cannot get rows: %w", err)
+		return report, err
 	}
+	report.Sheet = sheet
 
-	// 2. Собираем записи
-	//var recs []ImportRecord
+	var header []string
+	first := 1 // номер первой строки с данными, с 1
+	if !mapping.NoHeader && len(rows) > 0 {
+		header, rows = rows[0], rows[1:]
+		first = 2
+	}
+	cols, err := mapping.resolve(header, fixedShopID != 0)
+	if err != nil {
+		return report, err
+	}
 
+	// 3. Проверяем компоненты одним запросом
+	ids := make([]string, 0, len(rows))
+	for _, row := range rows {
+		if id := cell(row, cols.component); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	known, err := uc.repo.ExistingComponentIDs(ctx, ids)
+	if err != nil {
+		return report, err
+	}
+
+	shops := map[string]int64{}
+	unknownShops := map[string]bool{}
+	unknownComps := map[string]bool{}
+	skip := func(n int, compID, shop, reason string) {
+		report.Skipped++
+		report.SkippedRows = append(report.SkippedRows, domain.PriceImportSkip{
+			Row: n, ComponentID: compID, ShopCode: shop, Reason: reason,
+		})
+	}
+
+	// 4. Разбираем строки
 	for i, row := range rows {
-		if i == 0 {
+		n := first + i
+		if opts.Progress != nil && i > 0 && i%progressEvery == 0 {
+			opts.Progress(i, len(rows))
+		}
+		if isBlankRow(row) {
 			continue
 		}
+		report.Rows++
 
-		code := row[1] // из XLSX: ячейка с кодом магазина, например "DNS"
-		// --- вместо ParseInt: получаем реальный shop_id из БД ---
-		shopID, err := uc.repo.GetShopIDByCode(ctx, code)
+		compID := cell(row, cols.component)
+		code := opts.ShopCode
+		if cols.shop >= 0 && cell(row, cols.shop) != "" {
+			code = cell(row, cols.shop)
+		}
+		switch {
+		case compID == "":
+			skip(n, compID, code, "component id is empty")
+			continue
+		case !known[compID]:
+			if !unknownComps[compID] {
+				unknownComps[compID] = true
+				report.UnknownComponents = append(report.UnknownComponents, compID)
+			}
+			skip(n, compID, code, "unknown component")
+			continue
+		case code == "":
+			skip(n, compID, code, "shop code is empty")
+			continue
+		}
+
+		shopID, ok := shops[code]
+		if !ok && !unknownShops[code] {
+			id, err := uc.repo.GetShopIDByCode(ctx, code)
+			switch {
+			case errors.Is(err, domain.ErrShopNotFound):
+				unknownShops[code] = true
+				report.UnknownShops = append(report.UnknownShops, code)
+			case err != nil:
+				return report, err
+			default:
+				shopID, ok = id, true
+				shops[code] = id
+			}
+		}
+		if !ok {
+			skip(n, compID, code, "unknown shop")
+			continue
+		}
+
+		// валюта из отдельной колонки, если в самой цене её нет
+		currency, ok := normalize.Currency(cell(row, cols.currency))
+		if !ok {
+			currency = normalize.DefaultCurrency
+		}
+		price, err := normalize.ParsePrice(cell(row, cols.price), currency)
 		if err != nil {
-			uc.logger.Printf("unknown shop code %q: %v", code, err)
+			skip(n, compID, code, err.Error())
 			continue
 		}
+		avail := normalize.ParseAvailability(cell(row, cols.availability))
 
-		price, _ := strconv.ParseFloat(row[2], 64)
+		state, err := uc.repo.GetOfferState(ctx, compID, shopID)
+		if err != nil {
+			skip(n, compID, code, "read current price: "+err.Error())
+			continue
+		}
+		old := state.Price
+		if old != 0 && old != price.Amount {
+			report.PriceChanges = append(report.PriceChanges, domain.PriceChange{
+				ComponentID: compID, ShopCode: code,
+				OldPrice: old, NewPrice: price.Amount, Currency: price.Currency,
+			})
+		}
+		if opts.DryRun {
+			report.Imported++
+			continue
+		}
 
-		// 1) получить старую цену
-		old, _ := uc.repo.GetOfferPrice(ctx, row[0], shopID)
-		// 2) upsert + history
-		_ = uc.repo.UpsertOffer(ctx, row[0], shopID, price, row[4], row[5])
-		_ = uc.repo.InsertPriceHistory(ctx, row[0], shopID, price)
+		u := OfferUpdate{
+			ComponentID: compID, ShopID: shopID,
+			Price: price, Availability: avail, URL: cell(row, cols.url),
+		}
+		// изменились цена или наличие — события в outbox вместе с оффером
+		if u.Events, err = offerEvents(compID, shopID, state, price, avail); err != nil {
+			skip(n, compID, code, "offer events: "+err.Error())
+			continue
+		}
+		if err := uc.repo.SaveOffer(ctx, u); err != nil {
+			skip(n, compID, code, "save offer: "+err.Error())
+			continue
+		}
+		report.Imported++
+	}
+	return report, nil
+}
 
-		// 3) если изменилась — публикуем в RabbitMQ
-		if price != old {
-			if err := uc.publisher.PublishPriceChanged(row[0], shopID, old, price); err != nil {
-				uc.logger.Printf("publish failed: %v", err)
-			}
+func isBlankRow(row []string) bool {
+	for _, v := range row {
+		if strings.TrimSpace(v) != "" {
+			return false
 		}
 	}
-	return nil
+	return true
 }
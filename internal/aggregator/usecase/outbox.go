@@ -0,0 +1,191 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"StartupPCConfigurator/internal/aggregator/normalize"
+	"StartupPCConfigurator/internal/domain"
+)
+
+// Ключи событий в aggregator-ex
+const (
+	RoutingKeyPriceChanged        = "price.changed"
+	RoutingKeyAvailabilityChanged = "availability.changed"
+)
+
+// OfferUpdate — новые цена и наличие оффера. Репозиторий сохраняет оффер,
+// запись price_history и события одной транзакцией.
+type OfferUpdate struct {
+	ComponentID  string
+	ShopID       int64
+	Price        normalize.Price
+	Availability normalize.Availability
+	URL          string
+	Events       []domain.OutboxEvent
+}
+
+// offerEvents собирает события по изменению оффера: price.changed, если
+// изменилась цена, и availability.changed, если товар появился или закончился
+func offerEvents(componentID string, shopID int64, old OfferState,
+	price normalize.Price, avail normalize.Availability,
+) ([]domain.OutboxEvent, error) {
+	now := time.Now()
+	var events []domain.OutboxEvent
+	if old.Price != price.Amount {
+		msg := PriceChangedMsg{
+			EventID:     uuid.NewString(),
+			ComponentID: componentID,
+			ShopID:      shopID,
+			OldPrice:    old.Price,
+			NewPrice:    price.Amount,
+			Timestamp:   now.Unix(),
+		}
+		ev, err := newEvent(RoutingKeyPriceChanged, msg.EventID, msg, now)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, ev)
+	}
+	if availabilityFlipped(old, avail.Status) {
+		msg := AvailabilityChangedMsg{
+			EventID:     uuid.NewString(),
+			ComponentID: componentID,
+			ShopID:      shopID,
+			OldStatus:   old.Availability,
+			NewStatus:   avail.Status,
+			InStock:     avail.Status.Available(),
+			Price:       price.Amount,
+			Timestamp:   now.Unix(),
+		}
+		ev, err := newEvent(RoutingKeyAvailabilityChanged, msg.EventID, msg, now)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, ev)
+	}
+	return events, nil
+}
+
+// availabilityFlipped — товар перешёл между «можно купить» и «нельзя».
+// Новый оффер и нераспознанный текст наличия переходом не считаются.
+func availabilityFlipped(old OfferState, status domain.AvailabilityStatus) bool {
+	if !old.Exists || old.Availability == "" ||
+		old.Availability == domain.AvailabilityUnknown || status == domain.AvailabilityUnknown {
+		return false
+	}
+	return old.Availability.Available() != status.Available()
+}
+
+func newEvent(routingKey, eventID string, msg interface{}, at time.Time) (domain.OutboxEvent, error) {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return domain.OutboxEvent{}, err
+	}
+	return domain.OutboxEvent{
+		EventID:    eventID,
+		RoutingKey: routingKey,
+		Payload:    payload,
+		CreatedAt:  at,
+	}, nil
+}
+
+// ErrEventRejected — брокер отверг событие (nack): повтор, скорее всего,
+// не поможет, в отличие от обрыва связи или таймаута
+var ErrEventRejected = errors.New("event rejected by broker")
+
+// OutboxParkedError — событие отложено после maxAttempts отказов брокера,
+// relay его больше не отправляет
+type OutboxParkedError struct {
+	EventID    string
+	RoutingKey string
+	Attempts   int
+	Err        error
+}
+
+func (e *OutboxParkedError) Error() string {
+	return fmt.Sprintf("parked after %d attempts: %v", e.Attempts, e.Err)
+}
+
+func (e *OutboxParkedError) Unwrap() error { return e.Err }
+
+// OutboxRepository — хранилище outbox для relay
+type OutboxRepository interface {
+	// RelayOutbox берёт неотправленные события по порядку, вызывает send и
+	// помечает отправленными; на первой ошибке останавливается. Событие,
+	// отвергнутое брокером на maxAttempts-й попытке, откладывается —
+	// возвращается *OutboxParkedError.
+	RelayOutbox(ctx context.Context, limit, maxAttempts int, send func(domain.OutboxEvent) error) (int, error)
+	// PurgeOutbox удаляет отправленные события старше olderThan
+	PurgeOutbox(ctx context.Context, olderThan time.Duration) (int64, error)
+}
+
+// Параметры relay
+const (
+	outboxBatch       = 100
+	outboxInterval    = time.Second
+	outboxRetention   = 7 * 24 * time.Hour
+	outboxMaxAttempts = 10 // отказов брокера, после которых событие откладывается
+)
+
+// OutboxRelay публикует события из outbox в RabbitMQ: at-least-once,
+// получатели отсекают повторы по EventID
+type OutboxRelay struct {
+	repo   OutboxRepository
+	pub    EventPublisher
+	logger *log.Logger
+}
+
+func NewOutboxRelay(repo OutboxRepository, pub EventPublisher, logger *log.Logger) *OutboxRelay {
+	return &OutboxRelay{repo: repo, pub: pub, logger: logger}
+}
+
+// Run опрашивает outbox, пока не отменён ctx
+func (r *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(outboxInterval)
+	defer ticker.Stop()
+	lastPurge := time.Time{}
+
+	for {
+		// полная пачка — скорее всего, есть ещё, берём следующую сразу
+		for {
+			n, err := r.repo.RelayOutbox(ctx, outboxBatch, outboxMaxAttempts, func(ev domain.OutboxEvent) error {
+				return r.pub.PublishEvent(ctx, ev)
+			})
+			// отложенное событие больше не держит очередь — идём дальше сразу
+			var parked *OutboxParkedError
+			if errors.As(err, &parked) {
+				r.logger.Printf("ALERT outbox relay: %s event %s parked, needs attention: %v",
+					parked.RoutingKey, parked.EventID, parked)
+				continue
+			}
+			if err != nil && ctx.Err() == nil {
+				r.logger.Printf("outbox relay: %d sent, stopped: %v", n, err)
+			}
+			if err != nil || n < outboxBatch {
+				break
+			}
+		}
+
+		if time.Since(lastPurge) > time.Hour {
+			if n, err := r.repo.PurgeOutbox(ctx, outboxRetention); err != nil {
+				r.logger.Printf("outbox purge: %v", err)
+			} else if n > 0 {
+				r.logger.Printf("outbox purge: %d sent events removed", n)
+			}
+			lastPurge = time.Now()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -1,15 +1,72 @@
 package usecase
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
 
-// Parser умеет парсить один URL и возвращать структурированный результат
+	"StartupPCConfigurator/internal/domain"
+)
+
+// Parser умеет парсить один URL и возвращать структурированный результат.
+// Чтобы подключить новый магазин, достаточно реализовать этот интерфейс
+// и зарегистрировать парсер под кодом магазина (shops.code).
 type Parser interface {
 	Parse(ctx context.Context, url string) (*ParsedItem, error)
 }
 
-// Скопируйте сюда только нужные поля из dns.ParsedItem:
+// ParsedItem — то, что агрегатор берёт со страницы товара: цена и наличие
+// идут в offers, остальное — в предложения для каталога
 type ParsedItem struct {
 	Price        string
 	Availability string
 	URL          string
+	Name         string
+	Category     string // текст хлебных крошек
+	MainImage    string
+	Images       []string
+	Specs        []domain.SpecRow
+}
+
+// NoParserError — для магазина не зарегистрирован парсер
+type NoParserError struct {
+	ShopCode string
+}
+
+func (e *NoParserError) Error() string {
+	return fmt.Sprintf("no parser registered for shop %q", e.ShopCode)
+}
+
+// ParserRegistry — парсеры магазинов по shops.code (без учёта регистра)
+type ParserRegistry struct {
+	parsers map[string]Parser
+}
+
+func NewParserRegistry() *ParserRegistry {
+	return &ParserRegistry{parsers: make(map[string]Parser)}
+}
+
+// Register добавляет парсер магазина; повторная регистрация заменяет прежний
+func (r *ParserRegistry) Register(shopCode string, p Parser) {
+	r.parsers[strings.ToLower(shopCode)] = p
+}
+
+// Get возвращает парсер магазина или *NoParserError
+func (r *ParserRegistry) Get(shopCode string) (Parser, error) {
+	p, ok := r.parsers[strings.ToLower(shopCode)]
+	if !ok {
+		return nil, &NoParserError{ShopCode: shopCode}
+	}
+	return p, nil
+}
+
+// Codes — зарегистрированные коды магазинов, для логов
+func (r *ParserRegistry) Codes() []string {
+	codes := make([]string, 0, len(r.parsers))
+	for code := range r.parsers {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
 }
@@ -0,0 +1,244 @@
+package usecase
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// ErrBadPriceList — файл прайс-листа не разобран или колонки не сопоставлены
+var ErrBadPriceList = errors.New("invalid price list")
+
+// Форматы прайс-листов
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// PriceColumns — где в прайс-листе искать поля: имя колонки из заголовка
+// (без учёта регистра) или её номер, начиная с 1. Пусто — колонки нет.
+type PriceColumns struct {
+	Component    string `json:"component"`
+	Shop         string `json:"shop,omitempty"`
+	Price        string `json:"price"`
+	Currency     string `json:"currency,omitempty"`
+	Availability string `json:"availability,omitempty"`
+	URL          string `json:"url,omitempty"`
+}
+
+// PriceListMapping — раскладка прайс-листа магазина (shops.price_list_mapping)
+type PriceListMapping struct {
+	Sheet     string       `json:"sheet,omitempty"`     // имя листа или номер с 1; пусто — первый лист
+	Delimiter string       `json:"delimiter,omitempty"` // для CSV; пусто — по первой строке
+	NoHeader  bool         `json:"noHeader,omitempty"`  // первой строкой идут данные
+	Columns   PriceColumns `json:"columns"`
+}
+
+// DefaultPriceListMapping — прежняя раскладка: компонент, магазин, цена,
+// валюта, наличие, ссылка
+var DefaultPriceListMapping = PriceListMapping{
+	Columns: PriceColumns{
+		Component:    "1",
+		Shop:         "2",
+		Price:        "3",
+		Currency:     "4",
+		Availability: "5",
+		URL:          "6",
+	},
+}
+
+// ImportOptions — параметры импорта прайс-листа
+type ImportOptions struct {
+	Filename string            // по расширению определяется формат
+	Format   string            // csv | xlsx; пусто — по имени файла или содержимому
+	ShopCode string            // магазин для всех строк, если в файле нет колонки магазина
+	Mapping  *PriceListMapping // nil — раскладка магазина из БД или DefaultPriceListMapping
+	Sheet    string            // лист xlsx поверх раскладки
+	DryRun   bool              // только отчёт, без записи и событий
+
+	// Progress вызывается по ходу разбора строк (nil — не нужен)
+	Progress func(processed, total int)
+}
+
+// progressEvery — как часто сообщать о прогрессе импорта, в строках
+const progressEvery = 200
+
+// priceColumns — номера колонок (с 0) после сопоставления; -1 — колонки нет
+type priceColumns struct {
+	component, shop, price, currency, availability, url int
+}
+
+// resolve сопоставляет колонки с заголовком
+func (m PriceListMapping) resolve(header []string, shopFixed bool) (priceColumns, error) {
+	find := func(field, ref string, required bool) (int, error) {
+		ref = strings.TrimSpace(ref)
+		if ref == "" {
+			if required {
+				return -1, fmt.Errorf("%w: column %q is not mapped", ErrBadPriceList, field)
+			}
+			return -1, nil
+		}
+		if n, err := strconv.Atoi(ref); err == nil {
+			if n < 1 {
+				return -1, fmt.Errorf("%w: column %q: number must start from 1", ErrBadPriceList, field)
+			}
+			return n - 1, nil
+		}
+		if m.NoHeader {
+			return -1, fmt.Errorf("%w: column %q: file has no header, use a column number", ErrBadPriceList, field)
+		}
+		for i, h := range header {
+			if strings.EqualFold(strings.TrimSpace(h), ref) {
+				return i, nil
+			}
+		}
+		return -1, fmt.Errorf("%w: column %q not found in header", ErrBadPriceList, ref)
+	}
+
+	var (
+		cols priceColumns
+		err  error
+	)
+	if cols.component, err = find("component", m.Columns.Component, true); err != nil {
+		return cols, err
+	}
+	if cols.shop, err = find("shop", m.Columns.Shop, !shopFixed); err != nil {
+		return cols, err
+	}
+	if cols.price, err = find("price", m.Columns.Price, true); err != nil {
+		return cols, err
+	}
+	if cols.currency, err = find("currency", m.Columns.Currency, false); err != nil {
+		return cols, err
+	}
+	if cols.availability, err = find("availability", m.Columns.Availability, false); err != nil {
+		return cols, err
+	}
+	if cols.url, err = find("url", m.Columns.URL, false); err != nil {
+		return cols, err
+	}
+	return cols, nil
+}
+
+// cell возвращает значение колонки или "", если строка короче
+func cell(row []string, idx int) string {
+	if idx < 0 || idx >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[idx])
+}
+
+// detectFormat определяет формат по явному значению, имени файла или сигнатуре
+func detectFormat(format, filename string, data []byte) (string, error) {
+	switch strings.ToLower(format) {
+	case FormatCSV, FormatXLSX:
+		return strings.ToLower(format), nil
+	case "":
+	default:
+		return "", fmt.Errorf("%w: unsupported format %q", ErrBadPriceList, format)
+	}
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv", ".txt":
+		return FormatCSV, nil
+	case ".xlsx":
+		return FormatXLSX, nil
+	}
+	// xlsx — zip-архив
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		return FormatXLSX, nil
+	}
+	return FormatCSV, nil
+}
+
+// readPriceList читает все строки прайс-листа. Для xlsx возвращает имя листа.
+func readPriceList(r io.Reader, format string, m PriceListMapping) ([][]string, string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, "", err
+	}
+	switch format {
+	case FormatXLSX:
+		return readXLSX(data, m.Sheet)
+	default:
+		rows, err := readCSV(data, m.Delimiter)
+		return rows, "", err
+	}
+}
+
+func readXLSX(data []byte, sheet string) ([][]string, string, error) {
+	f, err := excelize.OpenReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: cannot read excel: %v", ErrBadPriceList, err)
+	}
+	defer f.Close()
+
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, "", fmt.Errorf("%w: workbook has no sheets", ErrBadPriceList)
+	}
+	name := sheets[0]
+	if sheet != "" {
+		name = ""
+		if n, err := strconv.Atoi(sheet); err == nil && n >= 1 && n <= len(sheets) {
+			name = sheets[n-1]
+		}
+		for _, s := range sheets {
+			if strings.EqualFold(s, sheet) {
+				name = s
+			}
+		}
+		if name == "" {
+			return nil, "", fmt.Errorf("%w: sheet %q not found (have %s)", ErrBadPriceList, sheet, strings.Join(sheets, ", "))
+		}
+	}
+	rows, err := f.GetRows(name)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: cannot get rows: %v", ErrBadPriceList, err)
+	}
+	return rows, name, nil
+}
+
+func readCSV(data []byte, delimiter string) ([][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // BOM из Excel
+	cr := csv.NewReader(bytes.NewReader(data))
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	cr.TrimLeadingSpace = true
+
+	switch {
+	case delimiter == `\t` || delimiter == "tab":
+		cr.Comma = '\t'
+	case delimiter != "":
+		cr.Comma = []rune(delimiter)[0]
+	default:
+		cr.Comma = guessDelimiter(data)
+	}
+	rows, err := cr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: cannot read csv: %v", ErrBadPriceList, err)
+	}
+	return rows, nil
+}
+
+// guessDelimiter выбирает самый частый разделитель первой строки:
+// русский Excel сохраняет CSV через «;»
+func guessDelimiter(data []byte) rune {
+	line := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		line = data[:i]
+	}
+	best, bestCount := ',', 0
+	for _, d := range []rune{';', '\t', ','} {
+		if n := bytes.Count(line, []byte(string(d))); n > bestCount {
+			best, bestCount = d, n
+		}
+	}
+	return best
+}
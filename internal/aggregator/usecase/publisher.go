@@ -1,7 +1,13 @@
 package usecase
 
-// Publisher — абстракция для публикации событий в RabbitMQ
-type Publisher interface {
-	// PublishPriceChanged генерирует событие price.changed
-	PublishPriceChanged(componentID string, shopID int64, oldPrice, newPrice float64) error
+import (
+	"context"
+
+	"StartupPCConfigurator/internal/domain"
+)
+
+// EventPublisher — абстракция для публикации событий outbox в RabbitMQ
+type EventPublisher interface {
+	// PublishEvent возвращает nil, когда брокер принял событие
+	PublishEvent(ctx context.Context, ev domain.OutboxEvent) error
 }
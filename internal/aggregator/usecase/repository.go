@@ -2,21 +2,40 @@ package usecase
 
 import (
 	"context"
+	"encoding/json"
+
+	"StartupPCConfigurator/internal/domain"
 )
 
-// ShopComponent — пара component_id + URL страницы в магазине
+// ShopComponent — пара component_id + URL страницы в магазине.
+// Пустой ComponentID — товар ещё не сопоставлен с каталогом.
 type ShopComponent struct {
 	ComponentID string
 	URL         string
 }
 
+// OfferState — текущие цена и наличие оффера; Exists = false — оффера ещё нет
+type OfferState struct {
+	Exists       bool
+	Price        float64
+	Availability domain.AvailabilityStatus
+}
+
 // Repository — всё, что нужно UpdateUseCase
 type Repository interface {
 	ListShopComponents(ctx context.Context, shopID int64) ([]ShopComponent, error)
-	UpsertOffer(ctx context.Context, compID string, shopID int64, price float64, availability, url string) error
-	InsertPriceHistory(ctx context.Context, compID string, shopID int64, price float64) error
+	GetShopCode(ctx context.Context, shopID int64) (string, error)
+	SaveOffer(ctx context.Context, u OfferUpdate) error
 	UpdateJobStatus(ctx context.Context, jobID int64, status string, message interface{}) error
 	BulkUpsertOffers(ctx context.Context, recs []ImportRecord) error
-	GetOfferPrice(ctx context.Context, compID string, shopID int64) (float64, error)
+	GetOfferState(ctx context.Context, compID string, shopID int64) (OfferState, error)
 	GetMinPrice(ctx context.Context, componentID string) (float64, string, error)
+
+	// обогащение каталога (enrich.go)
+	GetComponentSpecs(ctx context.Context, compID string) (category string, specs json.RawMessage, err error)
+	SaveSpecProposal(ctx context.Context, p domain.SpecProposal) (int64, error)
+	DeleteSpecProposal(ctx context.Context, shopID int64, url string) error
+	ListPendingSpecProposals(ctx context.Context, componentID int) ([]domain.SpecProposal, error)
+	SetSpecProposalConflicts(ctx context.Context, id int64, conflicts []domain.SpecConflict) error
+	ReplaceComponentImages(ctx context.Context, compID string, shopID int64, mainImage string, images []string) error
 }
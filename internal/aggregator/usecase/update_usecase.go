@@ -1,10 +1,12 @@
 package usecase
 
 import (
+	"StartupPCConfigurator/internal/aggregator/normalize"
+	"StartupPCConfigurator/internal/aggregator/specmap"
 	_ "StartupPCConfigurator/internal/config/usecase"
 	"context"
+	"fmt"
 	"log"
-	"strconv"
 	_ "time"
 )
 
@@ -13,19 +15,19 @@ type UpdateUseCase interface {
 }
 
 type updateUseCase struct {
-	repo      Repository
-	publisher Publisher
-	parser    Parser
-	logger    *log.Logger
+	repo    Repository
+	parsers *ParserRegistry
+	specs   *specmap.Mapper // nil — характеристики со страниц не собираются
+	logger  *log.Logger
 }
 
 func NewUpdateUseCase(
 	repo Repository,
-	pub Publisher,
-	parser Parser,
+	parsers *ParserRegistry,
+	specs *specmap.Mapper,
 	logger *log.Logger,
 ) UpdateUseCase {
-	return &updateUseCase{repo, pub, parser, logger}
+	return &updateUseCase{repo, parsers, specs, logger}
 }
 
 func (uc *updateUseCase) ProcessShopUpdate(ctx context.Context, jobID, shopID int64) error {
@@ -34,51 +36,70 @@ func (uc *updateUseCase) ProcessShopUpdate(ctx context.Context, jobID, shopID in
 		return err
 	}
 
-	// 2) Список страниц для парсинга
+	// 2) Парсер выбираем по коду магазина
+	code, err := uc.repo.GetShopCode(ctx, shopID)
+	if err != nil {
+		err = fmt.Errorf("shop %d: %w", shopID, err)
+		uc.repo.UpdateJobStatus(ctx, jobID, "failed", err.Error())
+		return err
+	}
+	parser, err := uc.parsers.Get(code)
+	if err != nil {
+		uc.repo.UpdateJobStatus(ctx, jobID, "failed", err.Error())
+		return err
+	}
+
+	// 3) Список страниц для парсинга
 	items, err := uc.repo.ListShopComponents(ctx, shopID)
 	if err != nil {
 		uc.repo.UpdateJobStatus(ctx, jobID, "failed", err.Error())
 		return err
 	}
 
-	// 3) Парсим и пишем в offers + history
+	// 4) Парсим и пишем в offers + history
 	for _, it := range items {
-		parsed, err := uc.parser.Parse(ctx, it.URL)
+		parsed, err := parser.Parse(ctx, it.URL)
 		if err != nil {
 			uc.logger.Printf("parser error for %s: %v", it.URL, err)
 			continue
 		}
-		// конвертация цены
-		price, err := strconv.ParseFloat(parsed.Price, 64)
+		// характеристики и фото — на проверку в каталог
+		if err := uc.enrich(ctx, shopID, it, parsed); err != nil {
+			uc.logger.Printf("enrich error for %s: %v", it.URL, err)
+		}
+		if it.ComponentID == "" {
+			continue // товар не сопоставлен с каталогом — цену писать некуда
+		}
+		// цена и наличие: «12 499 ₽» → 12499 RUB, «В наличии в 3 магазинах» → in_stock
+		price, err := normalize.ParsePrice(parsed.Price, normalize.DefaultCurrency)
 		if err != nil {
-			uc.logger.Printf("price parse error for %s: %v", parsed.Price, err)
+			uc.logger.Printf("price parse error for %s: %v", it.URL, err)
 			continue
 		}
+		avail := normalize.ParseAvailability(parsed.Availability)
 
 		// внутри цикла импорта
-		oldPrice, err := uc.repo.GetOfferPrice(ctx, it.ComponentID, shopID)
+		state, err := uc.repo.GetOfferState(ctx, it.ComponentID, shopID)
 		if err != nil {
 			uc.logger.Printf("cannot get old price: %v", err)
-			oldPrice = 0
+			state = OfferState{}
 		}
 
-		// upsert в offers
-		if err := uc.repo.UpsertOffer(ctx, it.ComponentID, shopID, price, parsed.Availability, parsed.URL); err != nil {
-			uc.logger.Printf("db error upsert offer: %v", err)
-			continue
+		// offers + история + события price.changed / availability.changed — одной транзакцией
+		u := OfferUpdate{
+			ComponentID: it.ComponentID, ShopID: shopID,
+			Price: price, Availability: avail, URL: parsed.URL,
 		}
-		// запись в историю
-		if err := uc.repo.InsertPriceHistory(ctx, it.ComponentID, shopID, price); err != nil {
-			uc.logger.Printf("db error insert history: %v", err)
+		if u.Events, err = offerEvents(it.ComponentID, shopID, state, price, avail); err != nil {
+			uc.logger.Printf("offer events: %v", err)
+			continue
 		}
-
-		if oldPrice != price {
-			if err := uc.publisher.PublishPriceChanged(it.ComponentID, shopID, oldPrice, price); err != nil {
-				uc.logger.Printf("failed to publish price.changed: %v", err)
-			}
+		if err := uc.repo.SaveOffer(ctx, u); err != nil {
+			uc.logger.Printf("db error save offer: %v", err)
+			continue
 		}
 	}
 
-	// 4) Завершить job
+	// 5) Завершить job
 	return uc.repo.UpdateJobStatus(ctx, jobID, "done", nil)
 }
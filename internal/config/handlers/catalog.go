@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"StartupPCConfigurator/internal/config/usecase"
+	"StartupPCConfigurator/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportSize — ограничение на размер файла импорта
+const maxImportSize = 10 << 20
+
+// ComponentRequest — тело POST /config/admin/components и PUT /config/admin/components/:id
+type ComponentRequest struct {
+	ID       int             `json:"id,omitempty"` // только для импорта
+	Name     string          `json:"name"`
+	Category string          `json:"category"`
+	Brand    string          `json:"brand"`
+	Specs    json.RawMessage `json:"specs"`
+}
+
+func (r ComponentRequest) toDomain() domain.Component {
+	return domain.Component{
+		ID:       r.ID,
+		Name:     r.Name,
+		Category: r.Category,
+		Brand:    r.Brand,
+		Specs:    r.Specs,
+	}
+}
+
+// catalogError переводит ошибку сервиса в HTTP-ответ
+func catalogError(c *gin.Context, err error) {
+	var specErr *usecase.SpecValidationError
+	var inUse *usecase.ComponentInUseError
+	switch {
+	case errors.As(err, &specErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid component", "errors": specErr.Errors})
+	case errors.As(err, &inUse):
+		c.JSON(http.StatusConflict, gin.H{
+			"error":          inUse.Error(),
+			"configurations": inUse.Configurations,
+		})
+	case errors.Is(err, domain.ErrComponentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func componentIDParam(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid component id"})
+		return 0, false
+	}
+	return id, true
+}
+
+// CreateComponent обрабатывает POST /config/admin/components
+func (h *ConfigHandler) CreateComponent(c *gin.Context) {
+	var req ComponentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	comp, err := h.service.CreateComponent(req.toDomain())
+	if err != nil {
+		catalogError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, comp)
+}
+
+// UpdateComponent обрабатывает PUT /config/admin/components/:id
+func (h *ConfigHandler) UpdateComponent(c *gin.Context) {
+	id, ok := componentIDParam(c)
+	if !ok {
+		return
+	}
+	var req ComponentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	comp, err := h.service.UpdateComponent(id, req.toDomain())
+	if err != nil {
+		catalogError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, comp)
+}
+
+// DeleteComponent обрабатывает DELETE /config/admin/components/:id[?hard=true|force=true]
+func (h *ConfigHandler) DeleteComponent(c *gin.Context) {
+	id, ok := componentIDParam(c)
+	if !ok {
+		return
+	}
+	hard, _ := strconv.ParseBool(c.Query("hard"))
+	force, _ := strconv.ParseBool(c.Query("force"))
+	if err := h.service.DeleteComponent(id, hard, force); err != nil {
+		catalogError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ImportComponents обрабатывает POST /config/admin/components/import[?dryRun=true].
+// Принимает JSON-массив или CSV — телом запроса либо файлом в поле "file".
+func (h *ConfigHandler) ImportComponents(c *gin.Context) {
+	data, format, err := readImportBody(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var rows []domain.Component
+	if format == "csv" {
+		rows, err = parseComponentsCSV(data)
+	} else {
+		rows, err = parseComponentsJSON(data)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dryRun, _ := strconv.ParseBool(c.Query("dryRun"))
+	report, err := h.service.ImportComponents(rows, dryRun)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// readImportBody читает данные импорта и определяет формат: csv или json
+func readImportBody(c *gin.Context) ([]byte, string, error) {
+	if fh, err := c.FormFile("file"); err == nil {
+		if fh.Size > maxImportSize {
+			return nil, "", fmt.Errorf("file is too large (max %d bytes)", maxImportSize)
+		}
+		f, err := fh.Open()
+		if err != nil {
+			return nil, "", err
+		}
+		defer f.Close()
+		data, err := io.ReadAll(f)
+		if err != nil {
+			return nil, "", err
+		}
+		format := "json"
+		if strings.EqualFold(filepath.Ext(fh.Filename), ".csv") {
+			format = "csv"
+		}
+		return data, format, nil
+	}
+
+	data, err := io.ReadAll(io.LimitReader(c.Request.Body, maxImportSize+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if len(data) > maxImportSize {
+		return nil, "", fmt.Errorf("body is too large (max %d bytes)", maxImportSize)
+	}
+	format := "json"
+	if strings.Contains(c.ContentType(), "csv") {
+		format = "csv"
+	}
+	return data, format, nil
+}
+
+func parseComponentsJSON(data []byte) ([]domain.Component, error) {
+	var reqs []ComponentRequest
+	if err := json.Unmarshal(data, &reqs); err != nil {
+		return nil, fmt.Errorf("invalid JSON: expected an array of components: %v", err)
+	}
+	rows := make([]domain.Component, 0, len(reqs))
+	for _, r := range reqs {
+		rows = append(rows, r.toDomain())
+	}
+	return rows, nil
+}
+
+// parseComponentsCSV разбирает CSV с заголовком: name, category обязательны;
+// id, brand и specs (JSON-объект) — по желанию
+func parseComponentsCSV(data []byte) ([]domain.Component, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.TrimLeadingSpace = true
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %v", err)
+	}
+	if len(records) == 0 {
+		return nil, errors.New("empty CSV")
+	}
+
+	col := map[string]int{}
+	for i, name := range records[0] {
+		col[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"name", "category"} {
+		if _, ok := col[required]; !ok {
+			return nil, fmt.Errorf("CSV header must contain %q", required)
+		}
+	}
+	get := func(rec []string, name string) string {
+		if i, ok := col[name]; ok && i < len(rec) {
+			return strings.TrimSpace(rec[i])
+		}
+		return ""
+	}
+
+	rows := make([]domain.Component, 0, len(records)-1)
+	for _, rec := range records[1:] {
+		comp := domain.Component{
+			Name:     get(rec, "name"),
+			Category: get(rec, "category"),
+			Brand:    get(rec, "brand"),
+			Specs:    json.RawMessage(get(rec, "specs")),
+		}
+		// нечисловой id — -1: импорт отклонит строку, а не станет искать по имени
+		if raw := get(rec, "id"); raw != "" {
+			if comp.ID, err = strconv.Atoi(raw); err != nil || comp.ID <= 0 {
+				comp.ID = -1
+			}
+		}
+		rows = append(rows, comp)
+	}
+	return rows, nil
+}
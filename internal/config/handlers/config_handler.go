@@ -18,8 +18,10 @@ type CreateConfigRequest struct {
 }
 
 type ComponentRef struct {
+	ID       int    `json:"id,omitempty"` // если указан — ищем по ID, иначе по имени
 	Category string `json:"category"`
 	Name     string `json:"name"`
+	Quantity int    `json:"quantity,omitempty"` // по умолчанию 1
 }
 
 // CreateConfig обрабатывает POST /config/newconfig
@@ -46,6 +48,11 @@ func (h *ConfigHandler) CreateConfig(c *gin.Context) {
 	// Вызываем бизнес-логику
 	config, err := h.service.CreateConfiguration(userID, req.Name, toDomainRefs(req.Components))
 	if err != nil {
+		var incompat *usecase.IncompatibleBuildError
+		if errors.As(err, &incompat) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "report": incompat.Report})
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -90,7 +97,10 @@ func (h *ConfigHandler) UpdateConfig(c *gin.Context) {
 	)
 	if err != nil {
 		// Примерный разбор ошибок
+		var incompat *usecase.IncompatibleBuildError
 		switch {
+		case errors.As(err, &incompat):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "report": incompat.Report})
 		case errors.Is(err, domain.ErrConfigNotFound):
 			c.JSON(http.StatusNotFound, gin.H{"error": "configuration not found"})
 		case errors.Is(err, domain.ErrForbidden):
@@ -107,7 +117,7 @@ func (h *ConfigHandler) UpdateConfig(c *gin.Context) {
 
 // Новый тип запроса под POST /config/compatible
 type CompatMultiRequest struct {
-	Category string                `json:"category" binding:"required,oneof=cpu gpu motherboard ram hdd ssd cooler case psu"`
+	Category string                `json:"category" binding:"required,oneof=cpu gpu motherboard ram hdd ssd cooler case psu case_fan"`
 	Bases    []domain.ComponentRef `json:"bases"    binding:"required"`
 	Brand    *string               `json:"brand,omitempty"`   // новый
 	Usecase  *string               `json:"usecase,omitempty"` // новый
@@ -134,6 +144,63 @@ func (h *ConfigHandler) GetCompatibleComponentsMulti(c *gin.Context) {
 	c.JSON(http.StatusOK, comps)
 }
 
+// CheckConfigRequest — тело POST /config/check
+type CheckConfigRequest struct {
+	Components []ComponentRef `json:"components" binding:"required"`
+}
+
+// requestLang берёт язык сообщений из ?lang или заголовка Accept-Language
+func requestLang(c *gin.Context) string {
+	if lang := c.Query("lang"); lang != "" {
+		return usecase.NormalizeLang(lang)
+	}
+	return usecase.NormalizeLang(c.GetHeader("Accept-Language"))
+}
+
+// CheckConfig обрабатывает POST /config/check — проверка набора компонентов без сохранения
+func (h *ConfigHandler) CheckConfig(c *gin.Context) {
+	var req CheckConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	report, err := h.service.CheckComponents(toDomainRefs(req.Components), requestLang(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// CheckUserConfig обрабатывает GET /config/userconf/:configId/check
+func (h *ConfigHandler) CheckUserConfig(c *gin.Context) {
+	raw, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	userID, ok := raw.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user_id"})
+		return
+	}
+
+	report, err := h.service.CheckUserConfiguration(userID, c.Param("configId"), requestLang(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrConfigNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "configuration not found"})
+		case errors.Is(err, domain.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
 // GetUserConfigs обрабатывает GET /config/userconf
 func (h *ConfigHandler) GetUserConfigs(c *gin.Context) {
 	raw, exists := c.Get("user_id")
@@ -208,9 +275,92 @@ func (h *ConfigHandler) GetUseCaseBuild(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"components": build})
 }
 
-// GenerateUseCaseConfigsRequest — тело POST /config/usecase/:name/generate
+// GenerateUseCaseConfigsRequest — тело POST /config/generate и
+// POST /config/usecase/:name/generate (во втором случае usecase берётся из пути)
 type GenerateUseCaseConfigsRequest struct {
-	Components []ComponentRef `json:"components"`
+	Usecase    string            `json:"usecase"`
+	Budget     int               `json:"budget"`     // 0 — без ограничения
+	Components []ComponentRef    `json:"components"` // закреплённые компоненты
+	Brands     map[string]string `json:"brands"`     // категория → бренд
+	Limit      int               `json:"limit"`
+}
+
+// GenerateConfigs обрабатывает POST /config/generate
+func (h *ConfigHandler) GenerateConfigs(c *gin.Context) {
+	var req GenerateUseCaseConfigsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	if req.Usecase == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "usecase is required"})
+		return
+	}
+	h.generate(c, req)
+}
+
+// GenerateUseCaseConfigs обрабатывает POST /config/usecase/:name/generate
+func (h *ConfigHandler) GenerateUseCaseConfigs(c *gin.Context) {
+	var req GenerateUseCaseConfigsRequest
+	// пустое тело допустимо: генерируем без бюджета и закреплённых компонентов
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
+	}
+	req.Usecase = c.Param("name")
+	h.generate(c, req)
+}
+
+func (h *ConfigHandler) generate(c *gin.Context, req GenerateUseCaseConfigsRequest) {
+	builds, err := h.service.GenerateBuilds(usecase.GenerateOptions{
+		UseCase: req.Usecase,
+		Budget:  req.Budget,
+		Pinned:  toDomainRefs(req.Components),
+		Brands:  req.Brands,
+		Limit:   req.Limit,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"builds": builds})
+}
+
+// OptimizeBuildRequest — тело POST /config/optimize
+type OptimizeBuildRequest struct {
+	Usecase    string            `json:"usecase" binding:"required"`
+	Budget     int               `json:"budget" binding:"required,gt=0"`
+	Caps       map[string]int    `json:"caps"`       // категория → максимальная цена позиции
+	Components []ComponentRef    `json:"components"` // закреплённые компоненты
+	Brands     map[string]string `json:"brands"`
+}
+
+// OptimizeBuild обрабатывает POST /config/optimize
+func (h *ConfigHandler) OptimizeBuild(c *gin.Context) {
+	var req OptimizeBuildRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	build, err := h.service.OptimizeBuild(usecase.OptimizeOptions{
+		UseCase:      req.Usecase,
+		Budget:       req.Budget,
+		CategoryCaps: req.Caps,
+		Pinned:       toDomainRefs(req.Components),
+		Brands:       req.Brands,
+	})
+	if errors.Is(err, usecase.ErrNoBuildInBudget) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, build)
 }
 
 // вспомогательная конвертация, как и для Create/Update
@@ -218,8 +368,10 @@ func toDomainRefs(input []ComponentRef) []domain.ComponentRef {
 	var result []domain.ComponentRef
 	for _, c := range input {
 		result = append(result, domain.ComponentRef{
+			ID:       c.ID,
 			Category: c.Category,
 			Name:     c.Name,
+			Quantity: c.Quantity,
 		})
 	}
 	return result
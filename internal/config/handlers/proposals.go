@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"StartupPCConfigurator/internal/config/usecase"
+	"StartupPCConfigurator/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// proposalError переводит ошибку сервиса в HTTP-ответ
+func proposalError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrProposalNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrProposalReviewed):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		catalogError(c, err)
+	}
+}
+
+func proposalIDParam(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid proposal id"})
+		return 0, false
+	}
+	return id, true
+}
+
+// ListSpecProposals обрабатывает GET /config/admin/spec-proposals
+func (h *ConfigHandler) ListSpecProposals(c *gin.Context) {
+	status := c.DefaultQuery("status", domain.ProposalPending)
+	if status == "all" {
+		status = ""
+	}
+	switch status {
+	case "", domain.ProposalPending, domain.ProposalApproved, domain.ProposalRejected:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
+		return
+	}
+	componentID := 0
+	if raw := c.Query("componentId"); raw != "" {
+		id, err := strconv.Atoi(raw)
+		if err != nil || id <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid componentId"})
+			return
+		}
+		componentID = id
+	}
+
+	list, err := h.service.ListSpecProposals(status, componentID)
+	if err != nil {
+		proposalError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"proposals": list})
+}
+
+// ApproveSpecProposal обрабатывает POST /config/admin/spec-proposals/:id/approve.
+// Тело необязательно: без него принимаются все изменения без конфликтов.
+func (h *ConfigHandler) ApproveSpecProposal(c *gin.Context) {
+	userID, ok := userIDFromContext(c)
+	if !ok {
+		return
+	}
+	id, ok := proposalIDParam(c)
+	if !ok {
+		return
+	}
+	var req usecase.ProposalApproval
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	comp, err := h.service.ApproveSpecProposal(userID, id, req)
+	if err != nil {
+		proposalError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, comp)
+}
+
+// RejectSpecProposal обрабатывает POST /config/admin/spec-proposals/:id/reject
+func (h *ConfigHandler) RejectSpecProposal(c *gin.Context) {
+	userID, ok := userIDFromContext(c)
+	if !ok {
+		return
+	}
+	id, ok := proposalIDParam(c)
+	if !ok {
+		return
+	}
+	if err := h.service.RejectSpecProposal(userID, id); err != nil {
+		proposalError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetComponentImages обрабатывает GET /config/components/:id/images
+func (h *ConfigHandler) GetComponentImages(c *gin.Context) {
+	id, ok := componentIDParam(c)
+	if !ok {
+		return
+	}
+	images, err := h.service.GetComponentImages(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"images": images})
+}
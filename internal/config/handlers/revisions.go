@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"StartupPCConfigurator/internal/config/usecase"
+	"StartupPCConfigurator/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// revisionError переводит ошибку сервиса в HTTP-ответ
+func revisionError(c *gin.Context, err error) {
+	var incompat *usecase.IncompatibleBuildError
+	var removed *usecase.RevisionComponentsRemovedError
+	switch {
+	case errors.As(err, &incompat):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "report": incompat.Report})
+	case errors.As(err, &removed):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "components": removed.Components})
+	case errors.Is(err, domain.ErrConfigNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "configuration not found"})
+	case errors.Is(err, domain.ErrRevisionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// revisionParam разбирает номер ревизии. Пустое значение допустимо, только
+// если def >= 0 — тогда возвращается def.
+func revisionParam(c *gin.Context, raw string, def int) (int, bool) {
+	if raw == "" && def >= 0 {
+		return def, true
+	}
+	rev, err := strconv.Atoi(raw)
+	if err != nil || rev <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid revision"})
+		return 0, false
+	}
+	return rev, true
+}
+
+// ListRevisions обрабатывает GET /config/userconf/:configId/revisions
+func (h *ConfigHandler) ListRevisions(c *gin.Context) {
+	userID, ok := userIDFromContext(c)
+	if !ok {
+		return
+	}
+	revs, err := h.service.ListRevisions(userID, c.Param("configId"))
+	if err != nil {
+		revisionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"revisions": revs})
+}
+
+// GetRevision обрабатывает GET /config/userconf/:configId/revisions/:rev
+func (h *ConfigHandler) GetRevision(c *gin.Context) {
+	userID, ok := userIDFromContext(c)
+	if !ok {
+		return
+	}
+	rev, ok := revisionParam(c, c.Param("rev"), -1)
+	if !ok {
+		return
+	}
+	out, err := h.service.GetRevision(userID, c.Param("configId"), rev)
+	if err != nil {
+		revisionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// RestoreRevision обрабатывает POST /config/userconf/:configId/revisions/:rev/restore
+func (h *ConfigHandler) RestoreRevision(c *gin.Context) {
+	userID, ok := userIDFromContext(c)
+	if !ok {
+		return
+	}
+	rev, ok := revisionParam(c, c.Param("rev"), -1)
+	if !ok {
+		return
+	}
+	cfg, err := h.service.RestoreRevision(userID, c.Param("configId"), rev)
+	if err != nil {
+		revisionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, cfg)
+}
+
+// DiffRevisions обрабатывает GET /config/userconf/:configId/diff?from=1&to=3.
+// Без to сравнивает с последней ревизией.
+func (h *ConfigHandler) DiffRevisions(c *gin.Context) {
+	userID, ok := userIDFromContext(c)
+	if !ok {
+		return
+	}
+	from, ok := revisionParam(c, c.Query("from"), -1)
+	if !ok {
+		return
+	}
+	to, ok := revisionParam(c, c.Query("to"), 0)
+	if !ok {
+		return
+	}
+	diff, err := h.service.DiffRevisions(userID, c.Param("configId"), from, to, requestLang(c))
+	if err != nil {
+		revisionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, diff)
+}
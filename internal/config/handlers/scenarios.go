@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"StartupPCConfigurator/internal/config/usecase"
+	"StartupPCConfigurator/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ScenarioRequest — тело POST /config/admin/scenarios и PUT /config/admin/scenarios/:name
+type ScenarioRequest struct {
+	Name        string          `json:"name"` // только для создания
+	Description string          `json:"description"`
+	Rule        json.RawMessage `json:"rule" binding:"required"`
+	Weights     json.RawMessage `json:"weights"` // при обновлении можно не передавать
+	Comment     string          `json:"comment"`
+}
+
+// RollbackScenarioRequest — тело POST /config/admin/scenarios/:name/rollback
+type RollbackScenarioRequest struct {
+	Version int `json:"version" binding:"required,gt=0"`
+}
+
+func (r ScenarioRequest) toDomain() domain.Scenario {
+	return domain.Scenario{
+		Name:        r.Name,
+		Description: r.Description,
+		Rule:        r.Rule,
+		Weights:     r.Weights,
+		Comment:     r.Comment,
+	}
+}
+
+// scenarioError переводит ошибку сервиса в HTTP-ответ
+func scenarioError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrScenarioNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrScenarioExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrInvalidScenario):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// ListScenarios обрабатывает GET /config/admin/scenarios
+func (h *ConfigHandler) ListScenarios(c *gin.Context) {
+	list, err := h.service.ListScenarios()
+	if err != nil {
+		scenarioError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"scenarios": list})
+}
+
+// GetScenarioVersions обрабатывает GET /config/admin/scenarios/:name/versions
+func (h *ConfigHandler) GetScenarioVersions(c *gin.Context) {
+	versions, err := h.service.GetScenarioVersions(c.Param("name"))
+	if err != nil {
+		scenarioError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"versions": versions})
+}
+
+// CreateScenario обрабатывает POST /config/admin/scenarios
+func (h *ConfigHandler) CreateScenario(c *gin.Context) {
+	var req ScenarioRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	sc, err := h.service.CreateScenario(c.MustGet("user_id").(uuid.UUID), req.toDomain())
+	if err != nil {
+		scenarioError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, sc)
+}
+
+// UpdateScenario обрабатывает PUT /config/admin/scenarios/:name — создаёт новую версию
+func (h *ConfigHandler) UpdateScenario(c *gin.Context) {
+	var req ScenarioRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	sc, err := h.service.UpdateScenario(c.MustGet("user_id").(uuid.UUID), c.Param("name"), req.toDomain())
+	if err != nil {
+		scenarioError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, sc)
+}
+
+// RollbackScenario обрабатывает POST /config/admin/scenarios/:name/rollback
+func (h *ConfigHandler) RollbackScenario(c *gin.Context) {
+	var req RollbackScenarioRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		// версию можно передать и query-параметром: ?version=3
+		v, convErr := strconv.Atoi(c.Query("version"))
+		if convErr != nil || v <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "version is required"})
+			return
+		}
+		req.Version = v
+	}
+	sc, err := h.service.RollbackScenario(c.Param("name"), req.Version)
+	if err != nil {
+		scenarioError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, sc)
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"StartupPCConfigurator/internal/config/usecase"
+	"StartupPCConfigurator/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CreateShareRequest — тело POST /config/userconf/:configId/shares
+type CreateShareRequest struct {
+	ExpiresAt *time.Time `json:"expiresAt"` // nil — бессрочная ссылка
+}
+
+// ForkShareRequest — тело POST /config/shared/:slug/fork
+type ForkShareRequest struct {
+	Name string `json:"name"` // по умолчанию — имя исходной сборки с пометкой «копия»
+}
+
+// userIDFromContext достаёт user_id, положенный AuthMiddleware; иначе отвечает 401
+func userIDFromContext(c *gin.Context) (uuid.UUID, bool) {
+	raw, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return uuid.Nil, false
+	}
+	userID, ok := raw.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user_id"})
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+// shareError переводит ошибку сервиса в HTTP-ответ
+func shareError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrConfigNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "configuration not found"})
+	case errors.Is(err, domain.ErrShareNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrShareExpired):
+		c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+	case errors.Is(err, usecase.ErrInvalidShareExpiry):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// CreateShare обрабатывает POST /config/userconf/:configId/shares
+func (h *ConfigHandler) CreateShare(c *gin.Context) {
+	userID, ok := userIDFromContext(c)
+	if !ok {
+		return
+	}
+	var req CreateShareRequest
+	// тело необязательно: без него ссылка бессрочная
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
+	}
+	share, err := h.service.CreateShare(userID, c.Param("configId"), req.ExpiresAt)
+	if err != nil {
+		shareError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, share)
+}
+
+// ListShares обрабатывает GET /config/userconf/:configId/shares
+func (h *ConfigHandler) ListShares(c *gin.Context) {
+	userID, ok := userIDFromContext(c)
+	if !ok {
+		return
+	}
+	shares, err := h.service.ListShares(userID, c.Param("configId"))
+	if err != nil {
+		shareError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"shares": shares})
+}
+
+// RevokeShare обрабатывает DELETE /config/shares/:slug
+func (h *ConfigHandler) RevokeShare(c *gin.Context) {
+	userID, ok := userIDFromContext(c)
+	if !ok {
+		return
+	}
+	if err := h.service.RevokeShare(userID, c.Param("slug")); err != nil {
+		shareError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetSharedConfig обрабатывает GET /config/shared/:slug — без авторизации
+func (h *ConfigHandler) GetSharedConfig(c *gin.Context) {
+	shared, err := h.service.GetSharedConfiguration(c.Param("slug"), requestLang(c))
+	if err != nil {
+		shareError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, shared)
+}
+
+// ForkSharedConfig обрабатывает POST /config/shared/:slug/fork
+func (h *ConfigHandler) ForkSharedConfig(c *gin.Context) {
+	userID, ok := userIDFromContext(c)
+	if !ok {
+		return
+	}
+	var req ForkShareRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
+	}
+	cfg, err := h.service.ForkSharedConfiguration(userID, c.Param("slug"), req.Name)
+	if err != nil {
+		shareError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, cfg)
+}
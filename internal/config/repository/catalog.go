@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"StartupPCConfigurator/internal/domain"
+
+	"github.com/lib/pq"
+)
+
+// pgForeignKeyViolation — код ошибки Postgres при нарушении внешнего ключа
+const pgForeignKeyViolation = "23503"
+
+// CreateComponent добавляет компонент в каталог
+func (r *configRepository) CreateComponent(c domain.Component) (domain.Component, error) {
+	err := r.db.QueryRow(`
+		INSERT INTO components (name, category, brand, specs)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, updated_at`,
+		c.Name, c.Category, c.Brand, []byte(c.Specs),
+	).Scan(&c.ID, &c.CreatedAt, &c.UpdatedAt)
+	return c, err
+}
+
+// UpdateComponent перезаписывает компонент целиком. Удалённый компонент
+// при этом восстанавливается в каталоге.
+func (r *configRepository) UpdateComponent(c domain.Component) (domain.Component, error) {
+	err := r.db.QueryRow(`
+		UPDATE components
+		   SET name = $2, category = $3, brand = $4, specs = $5,
+		       deleted_at = NULL, updated_at = NOW()
+		 WHERE id = $1
+		RETURNING created_at, updated_at`,
+		c.ID, c.Name, c.Category, c.Brand, []byte(c.Specs),
+	).Scan(&c.CreatedAt, &c.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Component{}, domain.ErrComponentNotFound
+	}
+	return c, err
+}
+
+// SoftDeleteComponent скрывает компонент из каталога
+func (r *configRepository) SoftDeleteComponent(id int) error {
+	res, err := r.db.Exec(`
+		UPDATE components SET deleted_at = NOW(), updated_at = NOW()
+		 WHERE id = $1 AND deleted_at IS NULL`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return domain.ErrComponentNotFound
+	}
+	return nil
+}
+
+// DeleteComponent удаляет компонент физически. Если на него ссылаются
+// сборки, Postgres не даст удалить (ON DELETE RESTRICT) — вернём domain.ErrComponentInUse.
+func (r *configRepository) DeleteComponent(id int) error {
+	res, err := r.db.Exec(`DELETE FROM components WHERE id = $1`, id)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == pgForeignKeyViolation {
+		return domain.ErrComponentInUse
+	}
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return domain.ErrComponentNotFound
+	}
+	return nil
+}
+
+// GetComponentUsages возвращает сборки, в которые входит компонент
+func (r *configRepository) GetComponentUsages(id int) ([]domain.ConfigurationRef, error) {
+	rows, err := r.db.Query(`
+		SELECT DISTINCT c.id, c.name, c.user_id
+		  FROM configuration_components cc
+		  JOIN configurations c ON c.id = cc.config_id
+		 WHERE cc.component_id = $1
+		 ORDER BY c.id`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.ConfigurationRef
+	for rows.Next() {
+		var ref domain.ConfigurationRef
+		if err := rows.Scan(&ref.ID, &ref.Name, &ref.UserID); err != nil {
+			return nil, err
+		}
+		out = append(out, ref)
+	}
+	return out, rows.Err()
+}
+
+// FindCatalogComponent ищет компонент для импорта: по id, если он задан,
+// иначе по категории и имени. Удалённые компоненты тоже находятся — deleted
+// это показывает; живой компонент с тем же именем важнее удалённого.
+// Если ничего не нашлось — domain.ErrComponentNotFound.
+func (r *configRepository) FindCatalogComponent(id int, category, name string) (int, bool, error) {
+	var (
+		found   int
+		deleted bool
+		err     error
+	)
+	if id != 0 {
+		err = r.db.QueryRow(`
+			SELECT id, deleted_at IS NOT NULL FROM components WHERE id = $1`, id,
+		).Scan(&found, &deleted)
+	} else {
+		err = r.db.QueryRow(`
+			SELECT id, deleted_at IS NOT NULL
+			  FROM components
+			 WHERE LOWER(category) = LOWER($1) AND LOWER(name) = LOWER($2)
+			 ORDER BY deleted_at IS NOT NULL, id
+			 LIMIT 1`, category, name,
+		).Scan(&found, &deleted)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, domain.ErrComponentNotFound
+	}
+	return found, deleted, err
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"StartupPCConfigurator/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+const proposalColumns = `p.id, p.component_id, p.shop_id, s.code, p.url, p.category, p.name,
+       p.changes, p.conflicts, p.unmapped, p.status, p.created_at, p.updated_at, p.reviewed_at`
+
+func scanProposal(row rowScanner) (domain.SpecProposal, error) {
+	var (
+		p                           domain.SpecProposal
+		componentID                 sql.NullInt64
+		changes, conflicts, unknown []byte
+		reviewedAt                  sql.NullTime
+	)
+	if err := row.Scan(&p.ID, &componentID, &p.ShopID, &p.ShopCode, &p.URL, &p.Category, &p.Name,
+		&changes, &conflicts, &unknown, &p.Status, &p.CreatedAt, &p.UpdatedAt, &reviewedAt); err != nil {
+		return domain.SpecProposal{}, err
+	}
+	if componentID.Valid {
+		id := int(componentID.Int64)
+		p.ComponentID = &id
+	}
+	if reviewedAt.Valid {
+		p.ReviewedAt = &reviewedAt.Time
+	}
+	for _, f := range []struct {
+		data []byte
+		dst  interface{}
+	}{{changes, &p.Changes}, {conflicts, &p.Conflicts}, {unknown, &p.Unmapped}} {
+		if err := json.Unmarshal(f.data, f.dst); err != nil {
+			return domain.SpecProposal{}, err
+		}
+	}
+	return p, nil
+}
+
+// ListSpecProposals возвращает предложения характеристик, новые первыми.
+// Пустой status и componentID = 0 — без фильтра.
+func (r *configRepository) ListSpecProposals(status string, componentID int) ([]domain.SpecProposal, error) {
+	rows, err := r.db.Query(`
+		SELECT `+proposalColumns+`
+		  FROM spec_proposals p
+		  JOIN shops s ON s.id = p.shop_id
+		 WHERE ($1 = '' OR p.status = $1)
+		   AND ($2 = 0 OR p.component_id = $2)
+		 ORDER BY p.updated_at DESC, p.id DESC`, status, componentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []domain.SpecProposal{}
+	for rows.Next() {
+		p, err := scanProposal(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+// GetSpecProposal ищет предложение по id
+func (r *configRepository) GetSpecProposal(id int64) (domain.SpecProposal, error) {
+	p, err := scanProposal(r.db.QueryRow(`
+		SELECT `+proposalColumns+`
+		  FROM spec_proposals p
+		  JOIN shops s ON s.id = p.shop_id
+		 WHERE p.id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.SpecProposal{}, domain.ErrProposalNotFound
+	}
+	return p, err
+}
+
+// ReviewSpecProposal закрывает открытое предложение. componentID — компонент,
+// созданный по предложению нового товара (nil — не меняется).
+func (r *configRepository) ReviewSpecProposal(id int64, status string, reviewer uuid.UUID, componentID *int) error {
+	res, err := r.db.Exec(`
+		UPDATE spec_proposals
+		   SET status = $2, reviewed_by = $3, reviewed_at = NOW(), updated_at = NOW(),
+		       component_id = COALESCE($4, component_id)
+		 WHERE id = $1 AND status = 'pending'`, id, status, reviewer, componentID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return domain.ErrProposalReviewed
+	}
+	return nil
+}
+
+// LinkShopComponent сопоставляет страницу магазина с компонентом каталога:
+// дальше парсер будет писать по ней цены
+func (r *configRepository) LinkShopComponent(shopID int64, url string, componentID int) error {
+	_, err := r.db.Exec(`
+		UPDATE shop_components SET component_id = $3
+		 WHERE shop_id = $1 AND url = $2 AND component_id IS NULL`, shopID, url, componentID)
+	return err
+}
+
+// GetComponentImages возвращает фото компонента: главные первыми
+func (r *configRepository) GetComponentImages(componentID int) ([]domain.ComponentImage, error) {
+	rows, err := r.db.Query(`
+		SELECT i.url, s.code, i.is_main
+		  FROM component_images i
+		  JOIN shops s ON s.id = i.shop_id
+		 WHERE i.component_id = $1
+		 ORDER BY i.is_main DESC, s.code, i.position`, componentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []domain.ComponentImage{}
+	for rows.Next() {
+		var img domain.ComponentImage
+		if err := rows.Scan(&img.URL, &img.ShopCode, &img.IsMain); err != nil {
+			return nil, err
+		}
+		out = append(out, img)
+	}
+	return out, rows.Err()
+}
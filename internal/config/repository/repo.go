@@ -18,9 +18,10 @@ import (
 type ConfigRepository interface {
 	GetComponents(category, search, brand string) ([]domain.Component, error)
 	GetCompatibleComponents(filter domain.CompatibilityFilter) ([]domain.Component, error)
-	CreateConfiguration(userId uuid.UUID, name string, components []domain.Component) (domain.Configuration, error)
-	UpdateConfiguration(userId uuid.UUID, configId, name string, comps []domain.Component) (domain.Configuration, error)
+	CreateConfiguration(userId uuid.UUID, name string, items []domain.ComponentRef) (domain.Configuration, error)
+	UpdateConfiguration(userId uuid.UUID, configId, name string, items []domain.ComponentRef) (domain.Configuration, error)
 	GetUserConfigurations(userId uuid.UUID) ([]domain.Configuration, error)
+	GetConfigurationByID(configId string) (domain.Configuration, error)
 	DeleteConfiguration(userId uuid.UUID, configId string) error
 	GetComponentByID(category, id string) (domain.Component, error)
 	GetComponentByName(category, name string) (domain.Component, error)
@@ -31,6 +32,40 @@ type ConfigRepository interface {
 	FilterPoolByCompatibility(pool []domain.Component, filter domain.CompatibilityFilter) ([]domain.Component, error)
 	GetComponentsFiltered(ctx context.Context, f ComponentFilter) ([]domain.Component, error) // ← НОВОЕ
 	GetMinPrices(ctx context.Context, ids []int) (map[int]int, error)
+	GetCheapestOffers(ctx context.Context, ids []int) (map[int]domain.CheapestOffer, error)
+
+	// сценарии сборок (scenarios.go)
+	GetActiveScenarios() ([]domain.Scenario, error)
+	GetScenarioVersions(name string) ([]domain.Scenario, error)
+	CreateScenarioVersion(sc domain.Scenario) (domain.Scenario, error)
+	ActivateScenarioVersion(name string, version int) (domain.Scenario, error)
+	IsSuperuser(userID uuid.UUID) (bool, error)
+
+	// управление каталогом (catalog.go)
+	CreateComponent(c domain.Component) (domain.Component, error)
+	UpdateComponent(c domain.Component) (domain.Component, error)
+	SoftDeleteComponent(id int) error
+	DeleteComponent(id int) error
+	GetComponentUsages(id int) ([]domain.ConfigurationRef, error)
+	FindCatalogComponent(id int, category, name string) (int, bool, error)
+
+	// публичные ссылки на сборки (shares.go)
+	CreateShare(sh domain.ConfigurationShare) (domain.ConfigurationShare, error)
+	GetShareBySlug(slug string) (domain.ConfigurationShare, error)
+	GetConfigurationShares(configID int) ([]domain.ConfigurationShare, error)
+	RevokeShare(slug string) error
+
+	// история изменений сборок (revisions.go)
+	GetConfigurationRevisions(configID int) ([]domain.ConfigurationRevision, error)
+	GetConfigurationRevision(configID, revision int) (domain.ConfigurationRevision, error)
+	GetComponentsByIDs(ids []int) (map[int]domain.Component, error)
+
+	// характеристики и фото со страниц магазинов (proposals.go)
+	ListSpecProposals(status string, componentID int) ([]domain.SpecProposal, error)
+	GetSpecProposal(id int64) (domain.SpecProposal, error)
+	ReviewSpecProposal(id int64, status string, reviewer uuid.UUID, componentID *int) error
+	LinkShopComponent(shopID int64, url string, componentID int) error
+	GetComponentImages(componentID int) ([]domain.ComponentImage, error)
 }
 
 // Реализация
@@ -61,6 +96,7 @@ func (r *configRepository) GetCompatibleComponents(filter domain.CompatibilityFi
         SELECT id, name, category, brand, specs, created_at, updated_at
           FROM components
          WHERE LOWER(category) = LOWER($1)
+           AND deleted_at IS NULL
 	`
 
 	args := []interface{}{filter.Category}
@@ -128,7 +164,7 @@ func (r *configRepository) GetComponents(category, search, brand string) ([]doma
 
 func (r *configRepository) CreateConfiguration(
 	userId uuid.UUID, name string,
-	components []domain.Component,
+	items []domain.ComponentRef,
 ) (domain.Configuration, error) {
 	tx, err := r.db.Begin()
 	if err != nil {
@@ -155,33 +191,25 @@ func (r *configRepository) CreateConfiguration(
 	}
 
 	insertComp := `
-		INSERT INTO configuration_components (config_id, component_id, category, created_at)
-		VALUES ($1, $2, $3, NOW())
+		INSERT INTO configuration_components (config_id, component_id, category, quantity, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
 	`
-	for _, comp := range components {
-		_, err = tx.Exec(insertComp, configID, comp.ID, comp.Category)
+	for _, it := range items {
+		_, err = tx.Exec(insertComp, configID, it.ID, it.Category, it.Quantity)
 		if err != nil {
 			return domain.Configuration{}, err
 		}
 	}
 
-	// Стало так:
-	refs := make([]domain.ComponentRef, 0, len(components))
-	for _, c := range components {
-		refs = append(refs, domain.ComponentRef{
-			ID:       c.ID,
-			Name:     c.Name,
-			Category: c.Category,
-			Brand:    c.Brand,
-			Specs:    c.Specs,
-		})
+	if err = insertRevision(tx, configID, name, items, userId); err != nil {
+		return domain.Configuration{}, err
 	}
 
 	return domain.Configuration{
 		ID:         configID,
 		Name:       name,
 		UserID:     userId,
-		Components: refs,
+		Components: items,
 		CreatedAt:  createdAt,
 		UpdatedAt:  updatedAt,
 	}, nil
@@ -190,7 +218,7 @@ func (r *configRepository) CreateConfiguration(
 func (r *configRepository) UpdateConfiguration(
 	userId uuid.UUID,
 	configId, name string,
-	comps []domain.Component,
+	items []domain.ComponentRef,
 ) (domain.Configuration, error) {
 	tx, err := r.db.Begin()
 	if err != nil {
@@ -249,16 +277,21 @@ func (r *configRepository) UpdateConfiguration(
 
 	queryInsertComp := `
 		INSERT INTO configuration_components
-			(config_id, component_id, category, created_at)
-		VALUES ($1, $2, $3, NOW())
+			(config_id, component_id, category, quantity, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
 	`
-	for _, c := range comps {
-		_, err = tx.Exec(queryInsertComp, configId, c.ID, c.Category)
+	for _, it := range items {
+		_, err = tx.Exec(queryInsertComp, configId, it.ID, it.Category, it.Quantity)
 		if err != nil {
 			return domain.Configuration{}, err
 		}
 	}
 
+	// каждая правка — новая неизменяемая ревизия
+	if err = insertRevision(tx, existing.ID, name, items, userId); err != nil {
+		return domain.Configuration{}, err
+	}
+
 	// обновим время конфигурации
 	err = tx.QueryRow(queryCheck, configId).Scan(
 		&existing.ID,
@@ -271,24 +304,13 @@ func (r *configRepository) UpdateConfiguration(
 		return domain.Configuration{}, err
 	}
 
-	refs := make([]domain.ComponentRef, 0, len(comps))
-	for _, c := range comps {
-		refs = append(refs, domain.ComponentRef{
-			Category: c.Category,
-			Name:     c.Name,
-			Brand:    c.Brand,
-			Specs:    c.Specs,
-			ID:       c.ID,
-		})
-	}
-
 	updatedConfig := domain.Configuration{
 		ID:         existing.ID,
 		UserID:     existing.UserID,
 		Name:       existing.Name,
 		CreatedAt:  existing.CreatedAt,
 		UpdatedAt:  existing.UpdatedAt,
-		Components: refs,
+		Components: items,
 	}
 
 	return updatedConfig, nil
@@ -316,7 +338,7 @@ func (r *configRepository) GetUserConfigurations(userId uuid.UUID) ([]domain.Con
 		}
 
 		compQuery := `
-		SELECT c.name, c.category, c.brand, c.id, c.specs
+		SELECT c.name, c.category, c.brand, c.id, c.specs, cc.quantity
 		FROM configuration_components cc
 		JOIN components c ON cc.component_id = c.id
 		WHERE cc.config_id = $1
@@ -327,7 +349,7 @@ func (r *configRepository) GetUserConfigurations(userId uuid.UUID) ([]domain.Con
 		}
 		for compRows.Next() {
 			var ref domain.ComponentRef
-			if err := compRows.Scan(&ref.Name, &ref.Category, &ref.Brand, &ref.ID, &ref.Specs); err != nil {
+			if err := compRows.Scan(&ref.Name, &ref.Category, &ref.Brand, &ref.ID, &ref.Specs, &ref.Quantity); err != nil {
 				compRows.Close()
 				return nil, err
 			}
@@ -341,6 +363,42 @@ func (r *configRepository) GetUserConfigurations(userId uuid.UUID) ([]domain.Con
 	return configs, nil
 }
 
+// GetConfigurationByID возвращает сборку вместе с компонентами.
+// Проверка владельца остаётся на стороне сервиса.
+func (r *configRepository) GetConfigurationByID(configId string) (domain.Configuration, error) {
+	var cfg domain.Configuration
+	err := r.db.QueryRow(`
+		SELECT id, user_id, name, created_at, updated_at
+		FROM configurations
+		WHERE id = $1
+	`, configId).Scan(&cfg.ID, &cfg.UserID, &cfg.Name, &cfg.CreatedAt, &cfg.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return domain.Configuration{}, domain.ErrConfigNotFound
+	} else if err != nil {
+		return domain.Configuration{}, err
+	}
+
+	rows, err := r.db.Query(`
+		SELECT c.name, c.category, c.brand, c.id, c.specs, cc.quantity
+		FROM configuration_components cc
+		JOIN components c ON cc.component_id = c.id
+		WHERE cc.config_id = $1
+	`, cfg.ID)
+	if err != nil {
+		return domain.Configuration{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var ref domain.ComponentRef
+		if err := rows.Scan(&ref.Name, &ref.Category, &ref.Brand, &ref.ID, &ref.Specs, &ref.Quantity); err != nil {
+			return domain.Configuration{}, err
+		}
+		cfg.Components = append(cfg.Components, ref)
+	}
+	return cfg, rows.Err()
+}
+
 func (r *configRepository) DeleteConfiguration(userId uuid.UUID, configId string) error {
 	tx, err := r.db.Begin()
 	if err != nil {
@@ -387,7 +445,7 @@ func (r *configRepository) GetComponentByID(category, id string) (domain.Compone
 	query := `
 		SELECT id, name, category, brand, specs, created_at, updated_at
 		FROM components
-		WHERE id = $1 AND category = $2
+		WHERE id = $1 AND category = $2 AND deleted_at IS NULL
 	`
 
 	// предполагаем, что id — это int (если uuid — адаптировать)
@@ -406,6 +464,9 @@ func (r *configRepository) GetComponentByID(category, id string) (domain.Compone
 		&c.CreatedAt,
 		&c.UpdatedAt,
 	)
+	if err == sql.ErrNoRows {
+		return domain.Component{}, domain.ErrComponentNotFound
+	}
 	if err != nil {
 		return domain.Component{}, err
 	}
@@ -418,6 +479,7 @@ func (r *configRepository) GetComponentByName(category, name string) (domain.Com
 		SELECT id, name, category, brand, specs, created_at, updated_at
 		FROM components
 		WHERE LOWER(category) = LOWER($1) AND LOWER(name) = LOWER($2)
+		  AND deleted_at IS NULL
 	`
 
 	var c domain.Component
@@ -460,7 +522,7 @@ func (r *configRepository) GetUseCases() ([]domain.UseCase, error) {
 func (r *configRepository) GetBrandsByCategory(cat string) ([]string, error) {
 	const q = `SELECT DISTINCT brand
                FROM components
-               WHERE category = $1
+               WHERE category = $1 AND deleted_at IS NULL
                ORDER BY brand`
 	rows, err := r.db.Query(q, cat)
 	if err != nil {
@@ -499,6 +561,7 @@ func (r *configRepository) GetComponentsByFilters(
       SELECT id, name, category, brand, specs, created_at, updated_at
         FROM components
        WHERE LOWER(category)=LOWER($1)
+         AND deleted_at IS NULL
     `
 	args := []interface{}{category}
 	idx := 2
@@ -634,7 +697,7 @@ func (r *configRepository) GetComponentsFiltered(
 	sb.WriteString(`
         SELECT id, name, category, brand, specs, created_at, updated_at
           FROM components
-         WHERE TRUE
+         WHERE deleted_at IS NULL
     `)
 
 	// --- категории ---------------------------------------------------------
@@ -724,7 +787,9 @@ func (r *configRepository) GetComponentsFiltered(
 	return out, rows.Err()
 }
 
-// GetMinPrices вернёт map[component_id]int(рубли)
+// GetMinPrices вернёт map[component_id]int(рубли). Предложения, которые
+// сейчас не купить (out_of_stock, preorder), не учитываются; unknown —
+// учитываются: наличие не распознано или оффер ещё не перепарсен.
 func (r *configRepository) GetMinPrices(
 	ctx context.Context, ids []int,
 ) (map[int]int, error) {
@@ -738,6 +803,7 @@ SELECT component_id,
        MIN(price)::int AS min_price     -- сразу округлим
   FROM offers
  WHERE component_id = ANY($1)
+   AND availability_status NOT IN ('out_of_stock', 'preorder')
  GROUP BY component_id
 `
 	rows, err := r.db.QueryContext(ctx, q, pq.Array(ids))
@@ -756,3 +822,44 @@ SELECT component_id,
 	}
 	return out, rows.Err()
 }
+
+// GetCheapestOffers вернёт самое дешёвое предложение по каждому компоненту
+// вместе с магазином. При равной цене берётся магазин с меньшим id.
+// Наличие учитывается так же, как в GetMinPrices.
+func (r *configRepository) GetCheapestOffers(
+	ctx context.Context, ids []int,
+) (map[int]domain.CheapestOffer, error) {
+
+	if len(ids) == 0 {
+		return map[int]domain.CheapestOffer{}, nil
+	}
+
+	const q = `
+SELECT DISTINCT ON (o.component_id)
+       o.component_id, o.shop_id, s.code, s.name,
+       o.price::int, COALESCE(o.url, '')
+  FROM offers o
+  JOIN shops  s ON s.id = o.shop_id
+ WHERE o.component_id = ANY($1)
+   AND o.availability_status NOT IN ('out_of_stock', 'preorder')
+ ORDER BY o.component_id, o.price, o.shop_id
+`
+	rows, err := r.db.QueryContext(ctx, q, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make(map[int]domain.CheapestOffer, len(ids))
+	for rows.Next() {
+		var o domain.CheapestOffer
+		if err := rows.Scan(
+			&o.ComponentID, &o.ShopID, &o.ShopCode, &o.ShopName,
+			&o.Price, &o.URL,
+		); err != nil {
+			return nil, err
+		}
+		out[o.ComponentID] = o
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"StartupPCConfigurator/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// revisionItem — позиция сборки в снимке ревизии. Specs не храним:
+// они берутся из каталога на момент чтения.
+type revisionItem struct {
+	ID       int    `json:"id"`
+	Category string `json:"category"`
+	Name     string `json:"name"`
+	Brand    string `json:"brand,omitempty"`
+	Quantity int    `json:"quantity"`
+}
+
+// insertRevision сохраняет текущее состояние сборки следующей ревизией.
+// Вызывается в той же транзакции, что и изменение сборки.
+func insertRevision(tx *sql.Tx, configID int, name string, items []domain.ComponentRef, userID uuid.UUID) error {
+	snapshot := make([]revisionItem, 0, len(items))
+	for _, it := range items {
+		qty := it.Quantity
+		if qty < 1 {
+			qty = 1
+		}
+		snapshot = append(snapshot, revisionItem{
+			ID:       it.ID,
+			Category: it.Category,
+			Name:     it.Name,
+			Brand:    it.Brand,
+			Quantity: qty,
+		})
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	// блокируем сборку, чтобы параллельные правки не получили один номер
+	if _, err := tx.Exec(`SELECT 1 FROM configurations WHERE id = $1 FOR UPDATE`, configID); err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+		INSERT INTO configuration_revisions (config_id, revision, name, components, created_by)
+		SELECT $1, COALESCE(MAX(revision), 0) + 1, $2, $3, $4
+		  FROM configuration_revisions
+		 WHERE config_id = $1`,
+		configID, name, data, userID,
+	)
+	return err
+}
+
+func scanRevision(row rowScanner) (domain.ConfigurationRevision, error) {
+	var (
+		rev       domain.ConfigurationRevision
+		data      []byte
+		createdBy uuid.NullUUID
+	)
+	if err := row.Scan(&rev.ConfigID, &rev.Revision, &rev.Name, &data, &createdBy, &rev.CreatedAt); err != nil {
+		return domain.ConfigurationRevision{}, err
+	}
+	if createdBy.Valid {
+		rev.CreatedBy = &createdBy.UUID
+	}
+	var snapshot []revisionItem
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return domain.ConfigurationRevision{}, err
+	}
+	rev.Components = make([]domain.ComponentRef, 0, len(snapshot))
+	for _, it := range snapshot {
+		rev.Components = append(rev.Components, domain.ComponentRef{
+			ID:       it.ID,
+			Category: it.Category,
+			Name:     it.Name,
+			Brand:    it.Brand,
+			Quantity: it.Quantity,
+		})
+	}
+	return rev, nil
+}
+
+const revisionColumns = `config_id, revision, name, components, created_by, created_at`
+
+// GetConfigurationRevisions возвращает ревизии сборки, новые первыми
+func (r *configRepository) GetConfigurationRevisions(configID int) ([]domain.ConfigurationRevision, error) {
+	rows, err := r.db.Query(`
+		SELECT `+revisionColumns+`
+		  FROM configuration_revisions
+		 WHERE config_id = $1
+		 ORDER BY revision DESC`, configID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.ConfigurationRevision
+	for rows.Next() {
+		rev, err := scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, rev)
+	}
+	return out, rows.Err()
+}
+
+// GetConfigurationRevision возвращает одну ревизию сборки
+func (r *configRepository) GetConfigurationRevision(configID, revision int) (domain.ConfigurationRevision, error) {
+	rev, err := scanRevision(r.db.QueryRow(`
+		SELECT `+revisionColumns+`
+		  FROM configuration_revisions
+		 WHERE config_id = $1 AND revision = $2`, configID, revision))
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ConfigurationRevision{}, domain.ErrRevisionNotFound
+	}
+	return rev, err
+}
+
+// GetComponentsByIDs возвращает компоненты по id, включая скрытые из каталога:
+// они остаются в сохранённых сборках и их ревизиях
+func (r *configRepository) GetComponentsByIDs(ids []int) (map[int]domain.Component, error) {
+	out := make(map[int]domain.Component, len(ids))
+	if len(ids) == 0 {
+		return out, nil
+	}
+	rows, err := r.db.Query(`
+		SELECT id, name, category, brand, specs, created_at, updated_at
+		  FROM components
+		 WHERE id = ANY($1)`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c domain.Component
+		if err := rows.Scan(&c.ID, &c.Name, &c.Category, &c.Brand, &c.Specs, &c.CreatedAt, &c.UpdatedAt); err != nil {
+			return nil, err
+		}
+		out[c.ID] = c
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"StartupPCConfigurator/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+const scenarioColumns = `
+	u.name, COALESCE(u.description, ''), sr.version, sr.is_active,
+	sr.rule, sr.weights, COALESCE(sr.comment, ''), sr.created_by, sr.created_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanScenario(row rowScanner) (domain.Scenario, error) {
+	var (
+		sc        domain.Scenario
+		createdBy uuid.NullUUID
+	)
+	err := row.Scan(
+		&sc.Name, &sc.Description, &sc.Version, &sc.Active,
+		&sc.Rule, &sc.Weights, &sc.Comment, &createdBy, &sc.CreatedAt,
+	)
+	if createdBy.Valid {
+		sc.CreatedBy = &createdBy.UUID
+	}
+	return sc, err
+}
+
+func scanScenarios(rows *sql.Rows) ([]domain.Scenario, error) {
+	defer rows.Close()
+	var out []domain.Scenario
+	for rows.Next() {
+		sc, err := scanScenario(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, sc)
+	}
+	return out, rows.Err()
+}
+
+// GetActiveScenarios возвращает действующую версию каждого сценария
+func (r *configRepository) GetActiveScenarios() ([]domain.Scenario, error) {
+	rows, err := r.db.Query(`
+		SELECT ` + scenarioColumns + `
+		  FROM scenario_rules sr
+		  JOIN usecases u ON u.id = sr.usecase_id
+		 WHERE sr.is_active
+		 ORDER BY u.id`)
+	if err != nil {
+		return nil, err
+	}
+	return scanScenarios(rows)
+}
+
+// GetScenarioVersions возвращает все версии сценария, новые первыми
+func (r *configRepository) GetScenarioVersions(name string) ([]domain.Scenario, error) {
+	rows, err := r.db.Query(`
+		SELECT `+scenarioColumns+`
+		  FROM scenario_rules sr
+		  JOIN usecases u ON u.id = sr.usecase_id
+		 WHERE u.name = $1
+		 ORDER BY sr.version DESC`, name)
+	if err != nil {
+		return nil, err
+	}
+	out, err := scanScenarios(rows)
+	if err == nil && len(out) == 0 {
+		return nil, domain.ErrScenarioNotFound
+	}
+	return out, err
+}
+
+// CreateScenarioVersion сохраняет новую версию сценария и делает её активной.
+// Если сценария ещё нет в usecases — создаёт его.
+func (r *configRepository) CreateScenarioVersion(sc domain.Scenario) (domain.Scenario, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return domain.Scenario{}, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		} else {
+			_ = tx.Commit()
+		}
+	}()
+
+	// upsert блокирует строку usecases — параллельные версии не получат один номер
+	var usecaseID int
+	err = tx.QueryRow(`
+		INSERT INTO usecases (name, description)
+		VALUES ($1, $2)
+		ON CONFLICT (name) DO UPDATE
+		   SET description = COALESCE(NULLIF(EXCLUDED.description, ''), usecases.description)
+		RETURNING id, COALESCE(description, '')`,
+		sc.Name, sc.Description,
+	).Scan(&usecaseID, &sc.Description)
+	if err != nil {
+		return domain.Scenario{}, err
+	}
+
+	err = tx.QueryRow(`
+		SELECT COALESCE(MAX(version), 0) + 1 FROM scenario_rules WHERE usecase_id = $1`,
+		usecaseID,
+	).Scan(&sc.Version)
+	if err != nil {
+		return domain.Scenario{}, err
+	}
+
+	if _, err = tx.Exec(`
+		UPDATE scenario_rules SET is_active = FALSE
+		 WHERE usecase_id = $1 AND is_active`, usecaseID); err != nil {
+		return domain.Scenario{}, err
+	}
+
+	var createdBy uuid.NullUUID
+	if sc.CreatedBy != nil {
+		createdBy = uuid.NullUUID{UUID: *sc.CreatedBy, Valid: true}
+	}
+	err = tx.QueryRow(`
+		INSERT INTO scenario_rules (usecase_id, version, rule, weights, is_active, comment, created_by)
+		VALUES ($1, $2, $3, $4, TRUE, NULLIF($5, ''), $6)
+		RETURNING created_at`,
+		usecaseID, sc.Version, []byte(sc.Rule), []byte(sc.Weights), sc.Comment, createdBy,
+	).Scan(&sc.CreatedAt)
+	if err != nil {
+		return domain.Scenario{}, err
+	}
+	sc.Active = true
+	return sc, nil
+}
+
+// ActivateScenarioVersion делает активной указанную версию (откат)
+func (r *configRepository) ActivateScenarioVersion(name string, version int) (domain.Scenario, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return domain.Scenario{}, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		} else {
+			_ = tx.Commit()
+		}
+	}()
+
+	var usecaseID int
+	err = tx.QueryRow(`
+		SELECT sr.usecase_id
+		  FROM scenario_rules sr
+		  JOIN usecases u ON u.id = sr.usecase_id
+		 WHERE u.name = $1 AND sr.version = $2
+		   FOR UPDATE OF sr`, name, version).Scan(&usecaseID)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = domain.ErrScenarioNotFound
+	}
+	if err != nil {
+		return domain.Scenario{}, err
+	}
+
+	if _, err = tx.Exec(`
+		UPDATE scenario_rules SET is_active = FALSE
+		 WHERE usecase_id = $1 AND is_active`, usecaseID); err != nil {
+		return domain.Scenario{}, err
+	}
+	if _, err = tx.Exec(`
+		UPDATE scenario_rules SET is_active = TRUE
+		 WHERE usecase_id = $1 AND version = $2`, usecaseID, version); err != nil {
+		return domain.Scenario{}, err
+	}
+
+	var sc domain.Scenario
+	sc, err = scanScenario(tx.QueryRow(`
+		SELECT `+scenarioColumns+`
+		  FROM scenario_rules sr
+		  JOIN usecases u ON u.id = sr.usecase_id
+		 WHERE sr.usecase_id = $1 AND sr.version = $2`, usecaseID, version))
+	return sc, err
+}
+
+// IsSuperuser сообщает, есть ли у пользователя права администратора
+func (r *configRepository) IsSuperuser(userID uuid.UUID) (bool, error) {
+	var ok bool
+	err := r.db.QueryRow(`SELECT is_superuser FROM users WHERE id = $1`, userID).Scan(&ok)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return ok, err
+}
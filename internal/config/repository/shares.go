@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"StartupPCConfigurator/internal/domain"
+)
+
+const shareColumns = `id, config_id, slug, created_by, expires_at, revoked_at, created_at`
+
+func scanShare(row rowScanner) (domain.ConfigurationShare, error) {
+	var (
+		sh        domain.ConfigurationShare
+		expiresAt sql.NullTime
+		revokedAt sql.NullTime
+	)
+	if err := row.Scan(&sh.ID, &sh.ConfigID, &sh.Slug, &sh.CreatedBy, &expiresAt, &revokedAt, &sh.CreatedAt); err != nil {
+		return domain.ConfigurationShare{}, err
+	}
+	if expiresAt.Valid {
+		sh.ExpiresAt = &expiresAt.Time
+	}
+	if revokedAt.Valid {
+		sh.RevokedAt = &revokedAt.Time
+	}
+	return sh, nil
+}
+
+// CreateShare сохраняет ссылку на сборку
+func (r *configRepository) CreateShare(sh domain.ConfigurationShare) (domain.ConfigurationShare, error) {
+	return scanShare(r.db.QueryRow(`
+		INSERT INTO configuration_shares (config_id, slug, created_by, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING `+shareColumns,
+		sh.ConfigID, sh.Slug, sh.CreatedBy, sh.ExpiresAt,
+	))
+}
+
+// GetShareBySlug ищет ссылку по slug, в том числе отозванную
+func (r *configRepository) GetShareBySlug(slug string) (domain.ConfigurationShare, error) {
+	sh, err := scanShare(r.db.QueryRow(`
+		SELECT `+shareColumns+`
+		  FROM configuration_shares
+		 WHERE slug = $1`, slug))
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ConfigurationShare{}, domain.ErrShareNotFound
+	}
+	return sh, err
+}
+
+// GetConfigurationShares возвращает все ссылки на сборку, новые первыми
+func (r *configRepository) GetConfigurationShares(configID int) ([]domain.ConfigurationShare, error) {
+	rows, err := r.db.Query(`
+		SELECT `+shareColumns+`
+		  FROM configuration_shares
+		 WHERE config_id = $1
+		 ORDER BY created_at DESC, id DESC`, configID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.ConfigurationShare
+	for rows.Next() {
+		sh, err := scanShare(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, sh)
+	}
+	return out, rows.Err()
+}
+
+// RevokeShare отзывает ссылку; повторный отзыв ничего не меняет
+func (r *configRepository) RevokeShare(slug string) error {
+	res, err := r.db.Exec(`
+		UPDATE configuration_shares
+		   SET revoked_at = COALESCE(revoked_at, NOW())
+		 WHERE slug = $1`, slug)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return domain.ErrShareNotFound
+	}
+	return nil
+}
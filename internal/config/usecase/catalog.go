@@ -0,0 +1,178 @@
+package usecase
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"StartupPCConfigurator/internal/domain"
+)
+
+// maxImportRows — ограничение на размер одного импорта
+const maxImportRows = 5000
+
+// SpecValidationError — компонент не прошёл проверку; Errors отдаются клиенту
+type SpecValidationError struct {
+	Errors []domain.SpecError
+}
+
+func (e *SpecValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, se := range e.Errors {
+		msgs = append(msgs, se.Error())
+	}
+	return fmt.Sprintf("invalid component: %s", strings.Join(msgs, "; "))
+}
+
+// ComponentInUseError — компонент нельзя удалить: он входит в сохранённые сборки
+type ComponentInUseError struct {
+	ComponentID    int
+	Configurations []domain.ConfigurationRef
+}
+
+func (e *ComponentInUseError) Error() string {
+	return fmt.Sprintf("component %d is used in %d configuration(s)", e.ComponentID, len(e.Configurations))
+}
+
+func (e *ComponentInUseError) Unwrap() error { return domain.ErrComponentInUse }
+
+// normalizeComponent приводит поля и specs к каноническому виду и проверяет их.
+// В каталог specs пишутся уже нормализованными: "65 W" → 65, "am4" → "AM4".
+func normalizeComponent(c *domain.Component) []domain.SpecError {
+	var errs []domain.SpecError
+	c.Name = strings.TrimSpace(c.Name)
+	c.Brand = strings.TrimSpace(c.Brand)
+	c.Category = strings.ToLower(strings.TrimSpace(c.Category))
+	if c.Name == "" {
+		errs = append(errs, domain.SpecError{Key: "name", Message: "is required"})
+	}
+	if !domain.IsValidCategory(c.Category) {
+		errs = append(errs, domain.SpecError{Key: "category", Message: fmt.Sprintf("unknown category %q", c.Category)})
+		return errs
+	}
+
+	specs, specErrs := domain.ParseSpecs(domain.ComponentCategory(c.Category), c.Specs)
+	errs = append(errs, specErrs...)
+	if len(errs) > 0 {
+		return errs
+	}
+	c.Specs, _ = json.Marshal(specs)
+	return nil
+}
+
+// CreateComponent добавляет компонент в каталог
+func (s *configService) CreateComponent(c domain.Component) (domain.Component, error) {
+	if errs := normalizeComponent(&c); len(errs) > 0 {
+		return domain.Component{}, &SpecValidationError{Errors: errs}
+	}
+	return s.repo.CreateComponent(c)
+}
+
+// UpdateComponent перезаписывает компонент каталога
+func (s *configService) UpdateComponent(id int, c domain.Component) (domain.Component, error) {
+	if errs := normalizeComponent(&c); len(errs) > 0 {
+		return domain.Component{}, &SpecValidationError{Errors: errs}
+	}
+	c.ID = id
+	return s.repo.UpdateComponent(c)
+}
+
+// DeleteComponent скрывает компонент из каталога (soft delete) или, при
+// hard = true, удаляет его совсем. Компонент из сохранённых сборок не
+// удаляется: скрыть его можно только с force = true (сборки с ним больше
+// не пересохранить), удалить совсем — никогда.
+func (s *configService) DeleteComponent(id int, hard, force bool) error {
+	usages, err := s.repo.GetComponentUsages(id)
+	if err != nil {
+		return err
+	}
+	if len(usages) > 0 && (hard || !force) {
+		return &ComponentInUseError{ComponentID: id, Configurations: usages}
+	}
+	if !hard {
+		return s.repo.SoftDeleteComponent(id)
+	}
+	err = s.repo.DeleteComponent(id)
+	if errors.Is(err, domain.ErrComponentInUse) {
+		// сборку успели сохранить между проверкой и удалением
+		usages, _ = s.repo.GetComponentUsages(id)
+		return &ComponentInUseError{ComponentID: id, Configurations: usages}
+	}
+	return err
+}
+
+// ImportComponents создаёт или обновляет компоненты пачкой. Строка с id
+// обновляет компонент, без id — ищется по категории и имени. Найденный
+// удалённый компонент восстанавливается (status restored, считается в
+// updated). Ошибка в одной строке не мешает остальным; при dryRun ничего не
+// пишется, но поиск тот же, что и при настоящем импорте.
+func (s *configService) ImportComponents(rows []domain.Component, dryRun bool) (domain.ComponentImportReport, error) {
+	report := domain.ComponentImportReport{DryRun: dryRun}
+	if len(rows) == 0 {
+		return report, errors.New("no rows to import")
+	}
+	if len(rows) > maxImportRows {
+		return report, fmt.Errorf("too many rows: %d (max %d)", len(rows), maxImportRows)
+	}
+
+	for i, c := range rows {
+		res := domain.ComponentImportRow{Row: i + 1, ID: c.ID, Name: c.Name}
+		errs := normalizeComponent(&c)
+		if c.ID < 0 {
+			res.ID = 0
+			errs = append(errs, domain.SpecError{Key: "id", Message: "must be a positive integer"})
+		}
+		if len(errs) > 0 {
+			res.Status = "error"
+			res.Errors = errs
+			report.Failed++
+			report.Rows = append(report.Rows, res)
+			continue
+		}
+
+		id, deleted, err := s.repo.FindCatalogComponent(c.ID, c.Category, c.Name)
+		switch {
+		case errors.Is(err, domain.ErrComponentNotFound) && c.ID == 0:
+			err = nil // новый компонент
+		case errors.Is(err, domain.ErrComponentNotFound):
+			err = fmt.Errorf("component %d not found", c.ID)
+		case err == nil:
+			c.ID = id
+		}
+
+		if err == nil {
+			switch {
+			case c.ID != 0 && deleted:
+				res.Status = "restored"
+			case c.ID != 0:
+				res.Status = "updated"
+			default:
+				res.Status = "created"
+			}
+			switch {
+			case dryRun:
+			case c.ID != 0:
+				c, err = s.repo.UpdateComponent(c)
+			default:
+				c, err = s.repo.CreateComponent(c)
+			}
+		}
+		if err != nil {
+			res.Status = "error"
+			res.Errors = []domain.SpecError{{Key: "row", Message: err.Error()}}
+			report.Failed++
+			report.Rows = append(report.Rows, res)
+			continue
+		}
+
+		res.ID = c.ID
+		if res.Status == "created" {
+			report.Created++
+		} else {
+			report.Updated++
+		}
+		report.Rows = append(report.Rows, res)
+	}
+	return report, nil
+}
@@ -3,188 +3,484 @@ package usecase
 import (
 	"encoding/json"
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
 
+	"StartupPCConfigurator/internal/config/usecase/rules"
 	"StartupPCConfigurator/internal/domain"
 )
 
-// CheckCompatibility проверяет полную сборку и возвращает список ошибок, если что-то не совместимо.
-// Поддерживаем проверки:
-//   - CPU ↔ MB (socket)
-//   - RAM ↔ MB (ram_type)
-//   - SSD ↔ MB (interface + form_factor)
-//   - GPU ↔ Case (length_mm)
-//   - CPU ↔ Case (cooler_height)
-//   - PSU ↔ (CPU power_draw + GPU power_draw + 150)
-//   - Case ↔ MB (form_factor входит в список допустимых у корпуса)
-func CheckCompatibility(components []domain.Component) []string {
-	errs := []string{}
-	// Распарсим все specs в map по категории
-	specsByCat := map[string]map[string]interface{}{}
+// defaultLang — язык сообщений, если клиент не указал свой
+const defaultLang = "ru"
+
+var supportedLangs = map[string]bool{"ru": true, "en": true}
+
+// specMissingMessages — сообщение для issue уровня info, когда правило
+// не удалось проверить из-за отсутствия характеристики
+var specMissingMessages = map[string]string{
+	"ru": "Не удалось проверить правило: у компонента %s нет характеристики %s",
+	"en": "Rule could not be checked: component %s has no %s spec",
+}
+
+// NormalizeLang приводит значение ?lang или Accept-Language ("en-US,en;q=0.9")
+// к одному из поддерживаемых языков.
+func NormalizeLang(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if i := strings.IndexAny(lang, ",;-_"); i >= 0 {
+		lang = lang[:i]
+	}
+	if supportedLangs[lang] {
+		return lang
+	}
+	return defaultLang
+}
+
+// compatItem — позиция сборки с распарсенными specs и количеством
+type compatItem struct {
+	comp  domain.ComponentRef
+	qty   int
+	specs map[string]interface{}
+}
+
+// newCompatItem парсит и нормализует specs позиции ("65 W" → 65);
+// nil, если specs не разобрать
+func newCompatItem(c domain.ComponentRef) *compatItem {
+	var m map[string]interface{}
+	if err := json.Unmarshal(c.Specs, &m); err != nil {
+		return nil
+	}
+	m, _ = domain.NormalizeSpecs(domain.ComponentCategory(strings.ToLower(c.Category)), m)
+	qty := c.Quantity
+	if qty < 1 {
+		qty = 1
+	}
+	return &compatItem{comp: c, qty: qty, specs: m}
+}
+
+// groupCompatItems раскладывает позиции сборки по категориям
+func groupCompatItems(items []domain.ComponentRef) map[string][]*compatItem {
+	byCat := map[string][]*compatItem{}
+	for _, c := range items {
+		it := newCompatItem(c)
+		if it == nil {
+			continue
+		}
+		cat := strings.ToLower(c.Category)
+		byCat[cat] = append(byCat[cat], it)
+	}
+	return byCat
+}
+
+// componentsToItems превращает список компонентов в позиции по одной штуке
+func componentsToItems(components []domain.Component) []domain.ComponentRef {
+	items := make([]domain.ComponentRef, 0, len(components))
 	for _, c := range components {
-		var m map[string]interface{}
-		if err := json.Unmarshal(c.Specs, &m); err != nil {
+		items = append(items, domain.ComponentRef{
+			ID:       c.ID,
+			Name:     c.Name,
+			Category: c.Category,
+			Brand:    c.Brand,
+			Specs:    c.Specs,
+			Quantity: 1,
+		})
+	}
+	return items
+}
+
+// compatCheck накапливает issues одной проверки
+type compatCheck struct {
+	lang   string
+	issues []domain.CompatibilityIssue
+	missed map[string]bool // ruleID/componentID/key — чтобы не дублировать info
+}
+
+func (c *compatCheck) add(
+	ruleID string,
+	sev domain.CompatibilitySeverity,
+	items []*compatItem,
+	keys []string,
+	msg string,
+) {
+	issue := domain.CompatibilityIssue{
+		RuleID:       ruleID,
+		Severity:     sev,
+		ComponentIDs: make([]int, 0, len(items)),
+		Categories:   make([]string, 0, len(items)),
+		SpecKeys:     keys,
+		Message:      msg,
+	}
+	seen := map[*compatItem]bool{}
+	for _, it := range items {
+		if seen[it] {
 			continue
 		}
-		specsByCat[strings.ToLower(c.Category)] = m
+		seen[it] = true
+		issue.ComponentIDs = append(issue.ComponentIDs, it.comp.ID)
+		issue.Categories = append(issue.Categories, strings.ToLower(it.comp.Category))
 	}
+	c.issues = append(c.issues, issue)
+}
 
-	cpu := specsByCat["cpu"]
-	mb := specsByCat["motherboard"]
-	ram := specsByCat["ram"]
-	gpu := specsByCat["gpu"]
-	psu := specsByCat["psu"]
-	cs := specsByCat["case"]
-	ssd := specsByCat["ssd"]
-	hdd := specsByCat["hdd"]
+// violate фиксирует нарушение правила
+func (c *compatCheck) violate(r rules.CompatRule, items []*compatItem, left, right interface{}) {
+	tpl, ok := r.Messages[c.lang]
+	if !ok {
+		tpl, ok = r.Messages[defaultLang]
+	}
+	if !ok {
+		tpl = r.ID
+	}
+	msg := strings.NewReplacer("{left}", formatSpecValue(left), "{right}", formatSpecValue(right)).Replace(tpl)
+	c.add(r.ID, domain.CompatibilitySeverity(r.Severity), items, ruleKeys(r), msg)
+}
+
+// missing фиксирует, что правило не проверено: у it нет характеристики key
+func (c *compatCheck) missing(r rules.CompatRule, it *compatItem, key string) {
+	if r.Missing == rules.MissingSkip {
+		return
+	}
+	mk := fmt.Sprintf("%s/%d/%s", r.ID, it.comp.ID, key)
+	if c.missed[mk] {
+		return
+	}
+	if c.missed == nil {
+		c.missed = map[string]bool{}
+	}
+	c.missed[mk] = true
 
-	// 1) CPU ↔ MB: socket
-	if cpu != nil && mb != nil {
-		if cpu["socket"] != mb["socket"] {
-			errs = append(errs, fmt.Sprintf("CPU.socket %v ≠ MB.socket %v", cpu["socket"], mb["socket"]))
+	sev := domain.SeverityInfo
+	if r.Missing == rules.MissingError {
+		sev = domain.SeverityError
+	}
+	c.add(r.ID, sev, []*compatItem{it}, []string{key}, fmt.Sprintf(specMissingMessages[c.lang], it.comp.Name, key))
+}
+
+func ruleKeys(r rules.CompatRule) []string {
+	var keys []string
+	seen := map[string]bool{}
+	for _, l := range r.Left {
+		if l.Key != "" && !seen[l.Key] {
+			seen[l.Key] = true
+			keys = append(keys, l.Key)
 		}
 	}
-	// 2) RAM ↔ MB: ram_type
-	if ram != nil && mb != nil {
-		if ram["ram_type"] != mb["ram_type"] {
-			errs = append(errs, fmt.Sprintf("RAM.ram_type %v ≠ MB.ram_type %v", ram["ram_type"], mb["ram_type"]))
+	if r.Right.Key != "" && !seen[r.Right.Key] {
+		keys = append(keys, r.Right.Key)
+	}
+	return keys
+}
+
+func formatSpecValue(v interface{}) string {
+	switch x := v.(type) {
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case []interface{}:
+		parts := make([]string, 0, len(x))
+		for _, e := range x {
+			parts = append(parts, formatSpecValue(e))
 		}
+		return strings.Join(parts, ", ")
+	default:
+		return fmt.Sprint(v)
 	}
-	// 3) SSD ↔ MB: interface + form_factor
-	if ssd != nil && mb != nil {
-		// интерфейс
-		if ssd["interface"] != mb["pcie_version"] && ssd["interface"] != mb["interface"] {
-			errs = append(errs, fmt.Sprintf("SSD.interface %v ≠ MB.pcie_version %v", ssd["interface"], mb["pcie_version"]))
+}
+
+var numberRe = regexp.MustCompile(`\d+(\.\d+)?`)
+
+// specNumber достаёт число из характеристики: 242, "242", "PCIe 4.0" → 4
+func specNumber(v interface{}) (float64, bool) {
+	switch x := v.(type) {
+	case float64:
+		return x, true
+	case int:
+		return float64(x), true
+	case string:
+		m := numberRe.FindString(x)
+		if m == "" {
+			return 0, false
 		}
-		// форм-фактор (M.2 vs число слотов)
-		if ssdFF, ok := ssd["form_factor"].(string); ok {
-			if ssdFF == "M.2" {
-				if m2, ok := mb["m2_slots"].(float64); !ok || m2 < 1 {
-					errs = append(errs, "SSD требует M.2-слот, а MB не поддерживает")
+		f, err := strconv.ParseFloat(m, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func specEqual(a, b interface{}) bool {
+	if an, ok := a.(float64); ok {
+		if bn, ok := specNumber(b); ok {
+			return an == bn
+		}
+	}
+	return strings.EqualFold(strings.TrimSpace(fmt.Sprint(a)), strings.TrimSpace(fmt.Sprint(b)))
+}
+
+// compareSpecs применяет оператор правила; known=false, если сравнить нельзя
+// (например, lte для нечисловых значений)
+func compareSpecs(r rules.CompatRule, left, right interface{}) (pass, known bool) {
+	switch r.Op {
+	case rules.OpEq:
+		return specEqual(left, right), true
+	case rules.OpPrefix:
+		return strings.HasPrefix(strings.ToUpper(fmt.Sprint(left)), strings.ToUpper(fmt.Sprint(right))), true
+	case rules.OpContains:
+		if list, ok := right.([]interface{}); ok {
+			for _, x := range list {
+				if specEqual(left, x) {
+					return true, true
 				}
 			}
+			return false, true
 		}
+		if list, ok := left.([]interface{}); ok {
+			for _, x := range list {
+				if specEqual(x, right) {
+					return true, true
+				}
+			}
+			return false, true
+		}
+		return specEqual(left, right), true
+	case rules.OpLte:
+		ln, ok1 := specNumber(left)
+		rn, ok2 := specNumber(right)
+		if !ok1 || !ok2 {
+			return false, false
+		}
+		return ln+r.Offset <= rn, true
 	}
+	return false, false
+}
 
-	// 3.1) HDD ↔ MB: интерфейс + наличие портов
-	if hdd != nil && mb != nil {
-		// a) HDD должен быть SATA-семейства
-		if iface, _ := hdd["interface"].(string); !strings.HasPrefix(strings.ToUpper(iface), "SATA") {
-			errs = append(errs, fmt.Sprintf("HDD.interface %v ≠ SATA", iface))
+// filterItems оставляет компоненты, подходящие под все условия своей категории
+func filterItems(items []*compatItem, conds []rules.CompatCondition) []*compatItem {
+	var out []*compatItem
+next:
+	for _, it := range items {
+		for _, cond := range conds {
+			if strings.EqualFold(cond.Category, it.comp.Category) && !cond.Match(it.specs[cond.Key]) {
+				continue next
+			}
 		}
+		out = append(out, it)
+	}
+	return out
+}
 
-		// b) На плате обязательно ≥1 SATA-порт
-		if ports, ok := mb["sata_ports"].(float64); !ok || ports < 1 {
-			errs = append(errs, "HDD требует SATA-порт, а MB не поддерживает")
-		}
+func whereConds(w *rules.CompatCondition) []rules.CompatCondition {
+	if w == nil {
+		return nil
 	}
+	return []rules.CompatCondition{*w}
+}
 
-	// 3.2) HDD ↔ Case: есть ли свободный 3.5-бей
-	if hdd != nil && cs != nil {
-		if bays, ok := cs["drive_bays_3_5"].(float64); ok && bays < 1 {
-			errs = append(errs, "Case не имеет 3.5\" отсеков для HDD")
+// evalRule проверяет одно правило на сборке
+func (c *compatCheck) evalRule(r rules.CompatRule, byCat map[string][]*compatItem) {
+	// условия when по «посторонним» категориям — хотя бы один компонент должен подходить
+	operandCats := map[string]bool{}
+	for _, cat := range r.Categories() {
+		operandCats[cat] = true
+	}
+	for _, cond := range r.When {
+		if operandCats[strings.ToLower(cond.Category)] {
+			continue
+		}
+		if len(filterItems(byCat[strings.ToLower(cond.Category)], []rules.CompatCondition{cond})) == 0 {
+			return
 		}
 	}
 
-	// 4) GPU ↔ Case: длина
-	if gpu != nil && cs != nil {
-		if gl, ok1 := gpu["length_mm"].(float64); ok1 {
-			if cm, ok2 := cs["gpu_max_length"].(float64); ok2 && gl > cm {
-				errs = append(errs, fmt.Sprintf("GPU.length_mm %.0f > Case.gpu_max_length %.0f", gl, cm))
-			}
+	var rights []*compatItem
+	if !r.Right.IsConst() {
+		rights = filterItems(byCat[strings.ToLower(r.Right.Category)], r.When)
+		if len(rights) == 0 {
+			return
 		}
 	}
-	// 5) CPU ↔ Case: высота кулера
-	if cpu != nil && cs != nil {
-		if ch, ok1 := cpu["cooler_height"].(float64); ok1 {
-			if cmh, ok2 := cs["cooler_max_height"].(float64); ok2 && ch > cmh {
-				errs = append(errs, fmt.Sprintf("CPU.cooler_height %.0f > Case.cooler_max_height %.0f", ch, cmh))
-			}
+	rightValue := func(rt *compatItem) (interface{}, bool) {
+		if rt == nil {
+			return r.Right.Value, true
 		}
+		v, ok := rt.specs[r.Right.Key]
+		if !ok || v == nil {
+			c.missing(r, rt, r.Right.Key)
+			return nil, false
+		}
+		return v, true
+	}
+	if r.Right.IsConst() {
+		rights = []*compatItem{nil}
 	}
-	// 6) Case ↔ MB: form_factor входит в список
-	if cs != nil && mb != nil {
-		if allowed, ok := cs["max_motherboard_form_factors"].([]interface{}); ok {
-			ff := mb["form_factor"]
-			okf := false
-			for _, x := range allowed {
-				if x == ff {
-					okf = true
-					break
+
+	switch r.Op {
+	case rules.OpSumLte, rules.OpCountLte:
+		total := r.Offset
+		var contributors []*compatItem
+		for _, op := range r.Left {
+			items := filterItems(byCat[strings.ToLower(op.Category)], append(whereConds(op.Where), r.When...))
+			for _, it := range items {
+				if r.Op == rules.OpCountLte {
+					total += float64(it.qty)
+					contributors = append(contributors, it)
+					continue
+				}
+				v, ok := it.specs[op.Key]
+				if !ok || v == nil {
+					v = op.Default
+				}
+				if n, ok := specNumber(v); ok {
+					total += n * float64(it.qty)
+					contributors = append(contributors, it)
 				}
-			}
-			if !okf {
-				errs = append(errs, fmt.Sprintf("MB.form_factor %v не поддерживается Case", ff))
 			}
 		}
-	}
-	// 7) PSU ↔ CPU+GPU: мощность
-	if psu != nil {
-		need := 150.0 // запас
-		if cpu != nil {
-			if d, ok := cpu["power_draw"].(float64); ok {
-				need += d
-			}
+		// нечего проверять: ни одного подходящего компонента и нет постоянной нагрузки
+		if len(contributors) == 0 && (r.Op == rules.OpCountLte || r.Offset == 0) {
+			return
 		}
-		if gpu != nil {
-			if d, ok := gpu["power_draw"].(float64); ok {
-				need += d
+		for _, rt := range rights {
+			rv, ok := rightValue(rt)
+			if !ok {
+				continue
+			}
+			rn, ok := specNumber(rv)
+			if !ok {
+				c.missing(r, rt, r.Right.Key)
+				continue
+			}
+			if total > rn {
+				items := contributors
+				if rt != nil {
+					items = append([]*compatItem{rt}, contributors...)
+				}
+				c.violate(r, items, total, rv)
 			}
 		}
-		if p, ok := psu["power"].(float64); ok && p < need {
-			errs = append(errs, fmt.Sprintf("PSU.power %.0f < required %.0f", p, need))
+
+	default:
+		l := r.Left[0]
+		lefts := filterItems(byCat[strings.ToLower(l.Category)], append(whereConds(l.Where), r.When...))
+		for _, lt := range lefts {
+			lv, ok := lt.specs[l.Key]
+			if !ok || lv == nil {
+				c.missing(r, lt, l.Key)
+				continue
+			}
+			for _, rt := range rights {
+				rv, ok := rightValue(rt)
+				if !ok {
+					continue
+				}
+				pass, known := compareSpecs(r, lv, rv)
+				if !known {
+					if _, ok := specNumber(lv); !ok {
+						c.missing(r, lt, l.Key)
+					} else {
+						c.missing(r, rt, r.Right.Key)
+					}
+					continue
+				}
+				if !pass {
+					items := []*compatItem{lt}
+					if rt != nil {
+						items = append(items, rt)
+					}
+					c.violate(r, items, lv, rv)
+				}
+			}
 		}
 	}
-	// PSU ↔ Case form-factor
-	if psu != nil && cs != nil {
-		if ff, _ := psu["form_factor"].(string); ff != "" {
-			if cf, _ := cs["psu_form_factor"].(string); !strings.EqualFold(ff, cf) {
-				errs = append(errs,
-					fmt.Sprintf("PSU.form_factor %v ≠ Case.psu_form_factor %v", ff, cf))
-			}
+}
+
+// CheckCompatibilityReport проверяет сборку (по одной штуке каждого компонента)
+func CheckCompatibilityReport(components []domain.Component, lang string) domain.CompatibilityReport {
+	return CheckBuildReport(componentsToItems(components), lang)
+}
+
+// CheckBuildReport проверяет позиции сборки с учётом количества по набору
+// правил rules.CompatRules и возвращает структурированный отчёт.
+func CheckBuildReport(items []domain.ComponentRef, lang string) domain.CompatibilityReport {
+	chk := &compatCheck{lang: NormalizeLang(lang)}
+	byCat := groupCompatItems(items)
+	for _, r := range rules.CompatRules {
+		chk.evalRule(r, byCat)
+	}
+
+	report := domain.CompatibilityReport{
+		Compatible: true,
+		Issues:     chk.issues,
+	}
+	if report.Issues == nil {
+		report.Issues = []domain.CompatibilityIssue{}
+	}
+	for _, is := range report.Issues {
+		if is.Severity == domain.SeverityError {
+			report.Compatible = false
+			break
 		}
 	}
+	return report
+}
 
-	return errs
+// filterCompatibleCandidates оставляет кандидатов, которые не дают ошибок
+// совместимости с уже выбранными компонентами bases. Проверяются те же
+// правила, что и в CheckCompatibilityReport, но только с участием кандидата.
+func filterCompatibleCandidates(candidates []domain.Component, bases []domain.ComponentRef) []domain.Component {
+	out := make([]domain.Component, 0, len(candidates))
+	for _, cand := range candidates {
+		byCat := groupCompatItems(bases)
+		it := newCompatItem(componentsToItems([]domain.Component{cand})[0])
+		if it == nil {
+			continue
+		}
+		cat := strings.ToLower(cand.Category)
+		byCat[cat] = append(byCat[cat], it)
+		if !hasCompatErrors(byCat, it) {
+			out = append(out, cand)
+		}
+	}
+	return out
 }
 
-// GenerateConfigurations возвращает все возможные сборки из всех категорий
-// с учётом CheckCompatibility. Делает бэктрекинг с ранней отбраковкой.
-func GenerateConfigurations(allComponents []domain.Component) [][]domain.Component {
-	// сгруппируем по категориям
-	categories := map[string][]domain.Component{}
-	for _, c := range allComponents {
-		key := strings.ToLower(c.Category)
-		categories[key] = append(categories[key], c)
-	}
-	// список категорий, которые участвуют в сборке
-	order := []string{"cpu", "motherboard", "ram", "ssd", "hdd", "gpu", "psu", "case", "cooler"}
-
-	var results [][]domain.Component
-	var current []domain.Component
-
-	var backtrack func(idx int)
-	backtrack = func(idx int) {
-		if idx == len(order) {
-			// коньченый набор — проверяем всю сборку
-			if len(CheckCompatibility(current)) == 0 {
-				combo := make([]domain.Component, len(current))
-				copy(combo, current)
-				results = append(results, combo)
-			}
-			return
+// hasCompatErrors проверяет правила, затрагивающие категорию it, и сообщает,
+// есть ли среди них ошибки с участием it
+func hasCompatErrors(byCat map[string][]*compatItem, it *compatItem) bool {
+	cat := strings.ToLower(it.comp.Category)
+	chk := &compatCheck{lang: defaultLang}
+	for _, r := range rules.CompatRules {
+		if !ruleTouches(r, cat) {
+			continue
+		}
+		chk.evalRule(r, byCat)
+	}
+	for _, is := range chk.issues {
+		if is.Severity != domain.SeverityError {
+			continue
 		}
-		cat := order[idx]
-		for _, comp := range categories[cat] {
-			current = append(current, comp)
-			// частичная проверка: если хоть одна ошибка — отсекаем
-			if len(CheckCompatibility(current)) == 0 {
-				backtrack(idx + 1)
+		for i, id := range is.ComponentIDs {
+			if id == it.comp.ID && is.Categories[i] == cat {
+				return true
 			}
-			current = current[:len(current)-1]
 		}
 	}
+	return false
+}
+
+func ruleTouches(r rules.CompatRule, category string) bool {
+	for _, c := range r.Categories() {
+		if strings.EqualFold(c, category) {
+			return true
+		}
+	}
+	return false
+}
 
-	backtrack(0)
-	return results
+// CheckCompatibility проверяет полную сборку и возвращает список ошибок, если что-то не совместимо.
+// Обёртка над CheckCompatibilityReport: берём только issues уровня error.
+func CheckCompatibility(components []domain.Component) []string {
+	errs := []string{}
+	for _, is := range CheckCompatibilityReport(components, defaultLang).Errors() {
+		errs = append(errs, is.Message)
+	}
+	return errs
 }
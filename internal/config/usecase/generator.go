@@ -0,0 +1,540 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"StartupPCConfigurator/internal/config/usecase/rules"
+	"StartupPCConfigurator/internal/domain"
+)
+
+// GenerateOptions — параметры генерации сборок
+type GenerateOptions struct {
+	UseCase string
+	Budget  int                   // максимальная стоимость сборки, 0 — без ограничения
+	Pinned  []domain.ComponentRef // компоненты, которые пользователь уже выбрал
+	Brands  map[string]string     // категория → предпочитаемый бренд
+	Limit   int                   // сколько сборок вернуть
+}
+
+const (
+	defaultGenerateLimit = 5
+	maxGenerateLimit     = 20
+	// maxVisitedBuilds ограничивает перебор. Пулы отсортированы по полезности
+	// для сценария, поэтому лучшие сборки находятся в начале обхода; остановку
+	// по лимиту buildWalker отмечает в truncated.
+	maxVisitedBuilds = 200000
+)
+
+// generatorOrder — порядок перебора: сначала категории, которые сильнее
+// всего сужают выбор остальных
+var generatorOrder = []string{"cpu", "motherboard", "ram", "case", "psu", "gpu", "ssd", "hdd", "cooler"}
+
+// genCandidate — компонент пула с ценой и полезностью для сценария
+type genCandidate struct {
+	item  *compatItem
+	offer *domain.CheapestOffer // nil — предложений нет
+	price int                   // минимальная цена × количество, 0 — цена неизвестна
+	score float64               // 0..1
+	value float64               // score × вес категории в сценарии
+}
+
+// genPool — кандидаты одной категории
+type genPool struct {
+	category string
+	optional bool // сборка допустима и без этой категории
+	pinned   bool // компонент закреплён пользователем
+	items    []genCandidate
+}
+
+func (p genPool) minPrice() int {
+	if p.optional || len(p.items) == 0 {
+		return 0
+	}
+	m := p.items[0].price
+	for _, c := range p.items[1:] {
+		if c.price < m {
+			m = c.price
+		}
+	}
+	return m
+}
+
+// buildWalker перебирает сборки по пулам в глубину. Несовместимые и
+// выходящие за бюджет ветки отсекаются сразу, не доходя до полной сборки.
+type buildWalker struct {
+	pools    []genPool
+	budget   int
+	maxVisit int
+	visit    func(picked []genCandidate, price int)
+	// bound (если задан) отсекает ветку по уже набранной цене и ценности
+	// до того, как спускаться к пулу depth
+	bound func(depth, price int, value float64) bool
+
+	byCat     map[string][]*compatItem
+	picked    []genCandidate
+	minRest   []int // minRest[i] — минимальная стоимость пулов i..end
+	visited   int
+	truncated bool // обход остановлен по maxVisit, часть веток не просмотрена
+}
+
+func newBuildWalker(pools []genPool, budget, maxVisit int, visit func([]genCandidate, int)) *buildWalker {
+	return &buildWalker{pools: pools, budget: budget, maxVisit: maxVisit, visit: visit}
+}
+
+func (w *buildWalker) run() {
+	w.byCat = map[string][]*compatItem{}
+	w.picked = w.picked[:0]
+	w.visited = 0
+	w.truncated = false
+	w.minRest = make([]int, len(w.pools)+1)
+	for i := len(w.pools) - 1; i >= 0; i-- {
+		w.minRest[i] = w.minRest[i+1] + w.pools[i].minPrice()
+	}
+	w.walk(0, 0, 0)
+}
+
+func (w *buildWalker) done() bool {
+	return w.maxVisit > 0 && w.visited >= w.maxVisit
+}
+
+func (w *buildWalker) walk(depth, price int, value float64) {
+	if w.done() {
+		w.truncated = true
+		return
+	}
+	if depth == len(w.pools) {
+		w.visited++
+		w.visit(w.picked, price)
+		return
+	}
+	pool := w.pools[depth]
+	for i, c := range pool.items {
+		total := price + c.price
+		if w.budget > 0 && total+w.minRest[depth+1] > w.budget {
+			continue
+		}
+		if w.bound != nil && w.bound(depth+1, total, value+c.value) {
+			continue
+		}
+		w.byCat[pool.category] = append(w.byCat[pool.category], c.item)
+		if !hasCompatErrors(w.byCat, c.item) {
+			w.picked = append(w.picked, c)
+			w.walk(depth+1, total, value+c.value)
+			w.picked = w.picked[:len(w.picked)-1]
+		}
+		w.byCat[pool.category] = w.byCat[pool.category][:len(w.byCat[pool.category])-1]
+		if w.done() {
+			// остались непросмотренные кандидаты этого пула
+			w.truncated = w.truncated || i < len(pool.items)-1 || pool.optional
+			return
+		}
+	}
+	// вариант «без этой категории» пробуем последним
+	if pool.optional && (w.bound == nil || !w.bound(depth+1, price, value)) {
+		w.walk(depth+1, price, value)
+	}
+}
+
+// pickedComponents превращает выбранных кандидатов в список компонентов
+func pickedComponents(picked []genCandidate) []domain.Component {
+	out := make([]domain.Component, 0, len(picked))
+	for _, c := range picked {
+		ref := c.item.comp
+		out = append(out, domain.Component{
+			ID:       ref.ID,
+			Name:     ref.Name,
+			Category: ref.Category,
+			Brand:    ref.Brand,
+			Specs:    ref.Specs,
+		})
+	}
+	return out
+}
+
+// pricedComponents — позиции сборки с количеством, ценой и магазином
+func pricedComponents(picked []genCandidate) []domain.PricedComponent {
+	out := make([]domain.PricedComponent, 0, len(picked))
+	for i, comp := range pickedComponents(picked) {
+		c := picked[i]
+		pc := domain.PricedComponent{
+			Component: comp,
+			Quantity:  c.item.qty,
+			Price:     c.price,
+		}
+		if c.offer != nil {
+			pc.UnitPrice = c.offer.Price
+			pc.ShopID = c.offer.ShopID
+			pc.ShopCode = c.offer.ShopCode
+			pc.ShopName = c.offer.ShopName
+			pc.URL = c.offer.URL
+		}
+		out = append(out, pc)
+	}
+	return out
+}
+
+// generator — подготовленные пулы кандидатов для одного сценария
+type generator struct {
+	usecase string
+	weights rules.Weights
+	budget  int
+	pools   []genPool
+	scores  map[string]map[int]float64 // категория → ID → полезность
+	tdp     map[string]map[int]int     // cpu/gpu → ID → TDP
+}
+
+// generatedBuild — найденная сборка с оценками
+type generatedBuild struct {
+	components []domain.PricedComponent
+	price      int
+	rank       int     // rankCached: 0..5
+	score      float64 // непрерывная взвешенная оценка, для упорядочивания внутри rank
+}
+
+// better — a лучше b: выше ранг, затем выше оценка, затем дешевле
+func (a generatedBuild) better(b generatedBuild) bool {
+	if a.rank != b.rank {
+		return a.rank > b.rank
+	}
+	if a.score != b.score {
+		return a.score > b.score
+	}
+	return a.price < b.price
+}
+
+// newGenerator собирает пулы кандидатов: каталог → сценарий → бренд → цена
+func (s *configService) newGenerator(ctx context.Context, opts GenerateOptions) (*generator, error) {
+	sc, ok := s.scenario(opts.UseCase)
+	if !ok {
+		return nil, fmt.Errorf("unknown usecase %q", opts.UseCase)
+	}
+	rule := sc.rule
+	if opts.Budget < 0 {
+		return nil, fmt.Errorf("invalid budget %d", opts.Budget)
+	}
+	g := &generator{
+		usecase: opts.UseCase,
+		weights: sc.weights,
+		budget:  opts.Budget,
+		scores:  map[string]map[int]float64{},
+		tdp:     map[string]map[int]int{"cpu": {}, "gpu": {}},
+	}
+
+	// закреплённые компоненты — отдельный пул из одного элемента каждый
+	pinned, err := s.resolveComponents(opts.Pinned)
+	if err != nil {
+		return nil, err
+	}
+	brands := map[string]string{}
+	for cat, b := range opts.Brands {
+		brands[strings.ToLower(cat)] = strings.TrimSpace(b)
+	}
+	pinnedCats := map[string]bool{}
+	for _, ref := range pinned {
+		it := newCompatItem(ref)
+		if it == nil {
+			return nil, fmt.Errorf("invalid specs for %s / %s", ref.Category, ref.Name)
+		}
+		cat := strings.ToLower(ref.Category)
+		pinnedCats[cat] = true
+		g.pools = append(g.pools, genPool{category: cat, pinned: true, items: []genCandidate{{item: it}}})
+	}
+
+	for _, cat := range generatorOrder {
+		if pinnedCats[cat] {
+			continue
+		}
+		pool := genPool{category: cat}
+		switch cat {
+		case "hdd":
+			if rule.MinHDDCapacity == 0 {
+				continue // HDD сценарию не нужен
+			}
+		case "gpu":
+			if rule.MinGPUMemory == 0 && rule.MaxGPUMemory == 0 {
+				continue // достаточно встроенной графики
+			}
+			pool.optional = rule.MinGPUMemory == 0
+		case "cooler":
+			pool.optional = true // у многих процессоров есть боксовый кулер
+		}
+
+		comps, err := s.repo.GetComponentsByCategory(cat)
+		if err != nil {
+			return nil, err
+		}
+		brand := brands[cat]
+		for _, c := range comps {
+			if !matchesScenario(c, rule) {
+				continue
+			}
+			if cat == "cooler" && !coolerMatches(c, rule) {
+				continue
+			}
+			if brand != "" && !strings.EqualFold(c.Brand, brand) {
+				continue
+			}
+			if it := newCompatItem(componentsToItems([]domain.Component{c})[0]); it != nil {
+				pool.items = append(pool.items, genCandidate{item: it})
+			}
+		}
+		if len(pool.items) == 0 && !pool.optional {
+			if brand != "" {
+				return nil, fmt.Errorf("no %s of brand %q fits usecase %q", cat, brand, opts.UseCase)
+			}
+			return nil, fmt.Errorf("no %s fits usecase %q", cat, opts.UseCase)
+		}
+		g.pools = append(g.pools, pool)
+	}
+
+	if err := g.applyPrices(ctx, s); err != nil {
+		return nil, err
+	}
+	g.scorePools(rule)
+	return g, nil
+}
+
+// applyPrices проставляет минимальные цены. При заданном бюджете компоненты
+// без предложений отбрасываются: стоимость такой сборки не посчитать.
+func (g *generator) applyPrices(ctx context.Context, s *configService) error {
+	var ids []int
+	for _, p := range g.pools {
+		for _, c := range p.items {
+			ids = append(ids, c.item.comp.ID)
+		}
+	}
+	offers, err := s.repo.GetCheapestOffers(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for i := range g.pools {
+		p := &g.pools[i]
+		kept := p.items[:0]
+		for _, c := range p.items {
+			offer, ok := offers[c.item.comp.ID]
+			if !ok {
+				if g.budget > 0 && !p.pinned {
+					continue
+				}
+			} else {
+				c.offer = &offer
+				c.price = offer.Price * c.item.qty
+			}
+			kept = append(kept, c)
+		}
+		p.items = kept
+		if len(p.items) == 0 && !p.optional {
+			return fmt.Errorf("no priced %s available for usecase %q", p.category, g.usecase)
+		}
+	}
+	return nil
+}
+
+// scorePools оценивает полезность кандидатов для сценария и сортирует пулы:
+// сначала полезные, при равенстве — дешёвые
+func (g *generator) scorePools(rule rules.ScenarioRule) {
+	for i := range g.pools {
+		p := &g.pools[i]
+		if g.scores[p.category] == nil {
+			g.scores[p.category] = map[int]float64{}
+		}
+
+		// для CPU шкала — разброс потоков внутри пула
+		minThreads, maxThreads := 0, 0
+		if p.category == "cpu" {
+			for j, c := range p.items {
+				t := cpuThreads(c.item.specs)
+				if j == 0 || t < minThreads {
+					minThreads = t
+				}
+				if t > maxThreads {
+					maxThreads = t
+				}
+			}
+		}
+
+		for j := range p.items {
+			c := &p.items[j]
+			specs := c.item.specs
+			switch p.category {
+			case "cpu":
+				c.score = normalize(cpuThreads(specs), minThreads, maxThreads)
+				g.tdp["cpu"][c.item.comp.ID] = toInt(specs["tdp"])
+			case "gpu":
+				c.score = normalize(toInt(specs["memory_gb"]), rule.MinGPUMemory, rule.MaxGPUMemory)
+				g.tdp["gpu"][c.item.comp.ID] = toInt(specs["power_draw"])
+			case "ram":
+				// объём считается по всем модулям позиции
+				c.score = normalize(toInt(specs["capacity"])*c.item.qty, rule.MinRAM, rule.MaxRAM)
+			case "ssd":
+				c.score = normalize(toInt(specs["max_throughput"]), rule.MinSSDThroughput, 8000)
+			case "hdd":
+				c.score = normalize(toInt(specs["capacity_gb"])*c.item.qty, rule.MinHDDCapacity, rule.MaxHDDCapacity)
+			case "psu":
+				c.score = normalize(toInt(specs["power"]), rule.MinPSUPower, rule.MaxPSUPower)
+			}
+			c.value = c.score * categoryWeight(g.weights, p.category)
+			g.scores[p.category][c.item.comp.ID] = c.score
+		}
+
+		sort.SliceStable(p.items, func(a, b int) bool {
+			if p.items[a].score != p.items[b].score {
+				return p.items[a].score > p.items[b].score
+			}
+			return p.items[a].price < p.items[b].price
+		})
+	}
+}
+
+// categoryWeight — вес категории в оценке сборки для сценария
+func categoryWeight(w rules.Weights, category string) float64 {
+	switch strings.ToLower(category) {
+	case "cpu":
+		return w.CPU
+	case "gpu":
+		return w.GPU
+	case "ram":
+		return w.RAM
+	case "ssd":
+		return w.SSD
+	case "hdd":
+		return w.HDD
+	case "psu":
+		return w.PSU
+	}
+	return 0
+}
+
+func cpuThreads(specs map[string]interface{}) int {
+	if t := toInt(specs["threads"]); t > 0 {
+		return t
+	}
+	return toInt(specs["cores"])
+}
+
+// evaluate считает ранг (rankCached) и взвешенную оценку сборки
+func (g *generator) evaluate(picked []genCandidate, price int) generatedBuild {
+	combo := pricedComponents(picked)
+	rank := rankCached(g.weights, combo,
+		g.scores["cpu"], g.scores["gpu"], g.scores["ram"],
+		g.scores["ssd"], g.scores["hdd"], g.scores["psu"],
+		g.tdp["cpu"], g.tdp["gpu"],
+	)
+
+	var score, total float64
+	for _, c := range picked {
+		if wt := categoryWeight(g.weights, c.item.comp.Category); wt > 0 {
+			score += c.value
+			total += wt
+		}
+	}
+	if total > 0 {
+		score /= total
+	}
+	return generatedBuild{components: combo, price: price, rank: rank, score: score}
+}
+
+func (g *generator) walk(visit func(generatedBuild)) {
+	newBuildWalker(g.pools, g.budget, maxVisitedBuilds, func(picked []genCandidate, price int) {
+		visit(g.evaluate(picked, price))
+	}).run()
+}
+
+// rankLabel — название сборки по её рангу
+func rankLabel(rank int) string {
+	if rank >= len(buildLabels) {
+		rank = len(buildLabels) - 1
+	}
+	if rank < 0 {
+		rank = 0
+	}
+	return buildLabels[rank]
+}
+
+func (b generatedBuild) named() domain.NamedBuild {
+	return domain.NamedBuild{
+		Name:       rankLabel(b.rank),
+		Score:      b.rank,
+		TotalPrice: b.price,
+		Components: b.components,
+	}
+}
+
+// GenerateBuilds подбирает лучшие совместимые сборки под сценарий и бюджет
+func (s *configService) GenerateBuilds(opts GenerateOptions) ([]domain.NamedBuild, error) {
+	if opts.Limit <= 0 {
+		opts.Limit = defaultGenerateLimit
+	}
+	if opts.Limit > maxGenerateLimit {
+		opts.Limit = maxGenerateLimit
+	}
+	g, err := s.newGenerator(context.Background(), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// держим top-N, отсортированный от лучшей к худшей
+	var top []generatedBuild
+	g.walk(func(b generatedBuild) {
+		if len(top) == opts.Limit && !b.better(top[len(top)-1]) {
+			return
+		}
+		i := sort.Search(len(top), func(i int) bool { return b.better(top[i]) })
+		top = append(top, generatedBuild{})
+		copy(top[i+1:], top[i:])
+		top[i] = b
+		if len(top) > opts.Limit {
+			top = top[:opts.Limit]
+		}
+	})
+	if len(top) == 0 {
+		return nil, fmt.Errorf("no compatible builds found for %q", opts.UseCase)
+	}
+
+	out := make([]domain.NamedBuild, 0, len(top))
+	for _, b := range top {
+		out = append(out, b.named())
+	}
+	return out, nil
+}
+
+// GetUseCaseBuild возвращает по одной сборке на каждый уровень — от
+// бюджетной до максимальной; на уровне берётся самая дешёвая сборка.
+func (s *configService) GetUseCaseBuild(usecaseName string, limit int) ([]domain.NamedBuild, error) {
+	g, err := s.newGenerator(context.Background(), GenerateOptions{UseCase: usecaseName})
+	if err != nil {
+		return nil, err
+	}
+
+	tiers := make([]*generatedBuild, len(buildLabels))
+	g.walk(func(b generatedBuild) {
+		tier := b.rank
+		if tier >= len(tiers) {
+			tier = len(tiers) - 1
+		}
+		cur := tiers[tier]
+		if cur == nil || b.price < cur.price || (b.price == cur.price && b.score > cur.score) {
+			tiers[tier] = &b
+		}
+	})
+
+	var out []domain.NamedBuild
+	for tier, b := range tiers {
+		if b == nil {
+			continue
+		}
+		nb := b.named()
+		nb.Name = buildLabels[tier]
+		out = append(out, nb)
+		if limit > 0 && len(out) == limit {
+			break
+		}
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no compatible builds found for %q", usecaseName)
+	}
+	return out, nil
+}
@@ -0,0 +1,182 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"StartupPCConfigurator/internal/domain"
+)
+
+// ErrNoBuildInBudget — в рамках бюджета и ограничений нет ни одной совместимой сборки
+var ErrNoBuildInBudget = errors.New("no compatible build fits the budget")
+
+// OptimizeOptions — параметры подбора лучшей сборки в рамках бюджета
+type OptimizeOptions struct {
+	UseCase      string
+	Budget       int                   // максимальная стоимость сборки, обязателен
+	CategoryCaps map[string]int        // категория → максимальная цена позиции
+	Pinned       []domain.ComponentRef // компоненты, которые обязательно войдут в сборку
+	Brands       map[string]string     // категория → предпочитаемый бренд
+}
+
+// OptimizeBuild ищет сборку с максимальной оценкой сценария, которая
+// укладывается в бюджет. Перебор — ветви и границы: ветка отсекается, если
+// даже лучшие оставшиеся компоненты не дадут оценку выше уже найденной.
+// Если перебор упёрся в maxVisitedBuilds, возвращается лучшая из
+// просмотренных сборок с Exact = false.
+func (s *configService) OptimizeBuild(opts OptimizeOptions) (domain.OptimizedBuild, error) {
+	if opts.Budget <= 0 {
+		return domain.OptimizedBuild{}, errors.New("budget must be positive")
+	}
+	g, err := s.newGenerator(context.Background(), GenerateOptions{
+		UseCase: opts.UseCase,
+		Budget:  opts.Budget,
+		Pinned:  opts.Pinned,
+		Brands:  opts.Brands,
+	})
+	if err != nil {
+		return domain.OptimizedBuild{}, err
+	}
+	if err := g.applyCaps(opts.CategoryCaps); err != nil {
+		return domain.OptimizedBuild{}, err
+	}
+
+	g.orderPoolsForBound()
+
+	// maxRest[i] — максимум ценности, который могут добавить пулы i..end;
+	// weightSum — ценность идеальной сборки, для нормировки в 0..1
+	maxRest := make([]float64, len(g.pools)+1)
+	var weightSum float64
+	for i := len(g.pools) - 1; i >= 0; i-- {
+		best := 0.0
+		for _, c := range g.pools[i].items {
+			if c.value > best {
+				best = c.value
+			}
+		}
+		maxRest[i] = maxRest[i+1] + best
+		weightSum += categoryWeight(g.weights, g.pools[i].category)
+	}
+
+	const eps = 1e-9
+	var (
+		best      []genCandidate
+		bestValue = -1.0
+		bestPrice int
+	)
+	w := newBuildWalker(g.pools, g.budget, maxVisitedBuilds, func(picked []genCandidate, price int) {
+		var value float64
+		for _, c := range picked {
+			value += c.value
+		}
+		if value > bestValue+eps || (value > bestValue-eps && price < bestPrice) {
+			best = append(best[:0], picked...)
+			bestValue, bestPrice = value, price
+		}
+	})
+	w.bound = func(depth, price int, value float64) bool {
+		if best == nil {
+			return false
+		}
+		upper := value + maxRest[depth]
+		if upper < bestValue-eps {
+			return true
+		}
+		// лучше не станет — имеет смысл только более дешёвая ветка
+		return upper < bestValue+eps && price+w.minRest[depth] >= bestPrice
+	}
+	w.run()
+
+	if best == nil {
+		return domain.OptimizedBuild{}, ErrNoBuildInBudget
+	}
+
+	if w.truncated {
+		log.Printf("optimize %s budget %d: search stopped after %d builds, result may be not optimal",
+			opts.UseCase, opts.Budget, w.visited)
+	}
+
+	res := g.evaluate(best, bestPrice)
+	out := domain.OptimizedBuild{
+		UseCase:    opts.UseCase,
+		Budget:     opts.Budget,
+		Name:       rankLabel(res.rank),
+		Score:      res.rank,
+		TotalPrice: bestPrice,
+		Exact:      !w.truncated,
+	}
+	if weightSum > 0 {
+		out.Value = bestValue / weightSum
+	}
+	out.Components = res.components
+	return out, nil
+}
+
+// orderPoolsForBound ставит вперёд пулы, от которых сильнее всего зависит
+// оценка: граница отсекает ветки уже на первых уровнях, и перебор реже
+// упирается в maxVisitedBuilds. Закреплённые пулы (по одному компоненту)
+// идут первыми — они не ветвятся и сразу сужают совместимость, категории
+// без веса в сценарии — последними.
+func (g *generator) orderPoolsForBound() {
+	spread := func(p genPool) float64 {
+		lo, hi := 0.0, 0.0
+		for i, c := range p.items {
+			if i == 0 || c.value < lo {
+				lo = c.value
+			}
+			if c.value > hi {
+				hi = c.value
+			}
+		}
+		if p.optional {
+			lo = 0
+		}
+		return hi - lo
+	}
+	sort.SliceStable(g.pools, func(a, b int) bool {
+		pa, pb := g.pools[a], g.pools[b]
+		if pa.pinned != pb.pinned {
+			return pa.pinned
+		}
+		return spread(pa) > spread(pb)
+	})
+}
+
+// applyCaps убирает из пулов компоненты дороже лимита своей категории.
+// Закреплённые компоненты лимиты не ограничивают.
+func (g *generator) applyCaps(caps map[string]int) error {
+	for cat, limit := range caps {
+		if limit < 0 {
+			return fmt.Errorf("invalid cap %d for %s", limit, cat)
+		}
+	}
+	for i := range g.pools {
+		p := &g.pools[i]
+		limit, ok := caps[p.category]
+		if !ok {
+			for cat, l := range caps {
+				if strings.EqualFold(cat, p.category) {
+					limit, ok = l, true
+				}
+			}
+		}
+		if !ok || p.pinned {
+			continue
+		}
+		kept := p.items[:0]
+		for _, c := range p.items {
+			if c.price <= limit {
+				kept = append(kept, c)
+			}
+		}
+		p.items = kept
+		if len(p.items) == 0 && !p.optional {
+			return fmt.Errorf("no %s within cap %d", p.category, limit)
+		}
+	}
+	return nil
+}
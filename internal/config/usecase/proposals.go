@@ -0,0 +1,160 @@
+package usecase
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"StartupPCConfigurator/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// ProposalApproval — решение администратора по предложению из магазина.
+// Keys — какие изменения принять (пусто — все, кроме конфликтующих);
+// Specs дописываются поверх. Name и Brand нужны для нового компонента.
+type ProposalApproval struct {
+	Keys  []string        `json:"keys,omitempty"`
+	Specs json.RawMessage `json:"specs,omitempty"`
+	Name  string          `json:"name,omitempty"`
+	Brand string          `json:"brand,omitempty"`
+}
+
+// ListSpecProposals возвращает предложения с фильтром по статусу и компоненту
+func (s *configService) ListSpecProposals(status string, componentID int) ([]domain.SpecProposal, error) {
+	return s.repo.ListSpecProposals(status, componentID)
+}
+
+// RejectSpecProposal отклоняет предложение, каталог не меняется
+func (s *configService) RejectSpecProposal(userID uuid.UUID, id int64) error {
+	return s.repo.ReviewSpecProposal(id, domain.ProposalRejected, userID, nil)
+}
+
+// GetComponentImages возвращает фото компонента, собранные со страниц магазинов
+func (s *configService) GetComponentImages(componentID int) ([]domain.ComponentImage, error) {
+	return s.repo.GetComponentImages(componentID)
+}
+
+// ApproveSpecProposal применяет выбранные характеристики к компоненту или,
+// если товар не сопоставлен с каталогом, создаёт новый компонент и
+// привязывает к нему страницу магазина
+func (s *configService) ApproveSpecProposal(userID uuid.UUID, id int64, a ProposalApproval) (domain.Component, error) {
+	p, err := s.repo.GetSpecProposal(id)
+	if err != nil {
+		return domain.Component{}, err
+	}
+	if p.Status != domain.ProposalPending {
+		return domain.Component{}, domain.ErrProposalReviewed
+	}
+
+	var current domain.Component
+	specs := map[string]interface{}{}
+	if p.ComponentID != nil {
+		comps, err := s.repo.GetComponentsByIDs([]int{*p.ComponentID})
+		if err != nil {
+			return domain.Component{}, err
+		}
+		c, ok := comps[*p.ComponentID]
+		if !ok {
+			return domain.Component{}, domain.ErrComponentNotFound
+		}
+		current = c
+		if len(c.Specs) > 0 {
+			if err := json.Unmarshal(c.Specs, &specs); err != nil {
+				return domain.Component{}, err
+			}
+		}
+	}
+
+	changes, err := selectChanges(p, a.Keys)
+	if err != nil {
+		return domain.Component{}, err
+	}
+	for _, ch := range changes {
+		specs[ch.Key] = ch.Proposed
+	}
+	if len(a.Specs) > 0 {
+		var extra map[string]interface{}
+		if err := json.Unmarshal(a.Specs, &extra); err != nil {
+			return domain.Component{}, &SpecValidationError{Errors: []domain.SpecError{{Key: "specs", Message: "must be a JSON object"}}}
+		}
+		for k, v := range extra {
+			specs[k] = v
+		}
+	}
+	raw, err := json.Marshal(specs)
+	if err != nil {
+		return domain.Component{}, err
+	}
+
+	var comp domain.Component
+	if p.ComponentID != nil {
+		current.Specs = raw
+		if a.Name != "" {
+			current.Name = a.Name
+		}
+		if a.Brand != "" {
+			current.Brand = a.Brand
+		}
+		if comp, err = s.UpdateComponent(current.ID, current); err != nil {
+			return domain.Component{}, err
+		}
+	} else {
+		name := a.Name
+		if name == "" {
+			name = p.Name
+		}
+		if comp, err = s.CreateComponent(domain.Component{
+			Name:     name,
+			Category: p.Category,
+			Brand:    a.Brand,
+			Specs:    raw,
+		}); err != nil {
+			return domain.Component{}, err
+		}
+		if err := s.repo.LinkShopComponent(p.ShopID, p.URL, comp.ID); err != nil {
+			return domain.Component{}, err
+		}
+	}
+
+	if err := s.repo.ReviewSpecProposal(id, domain.ProposalApproved, userID, &comp.ID); err != nil {
+		return domain.Component{}, err
+	}
+	return comp, nil
+}
+
+// selectChanges отбирает изменения по ключам. Без ключей берутся все,
+// кроме тех, по которым магазины расходятся: их администратор выбирает явно.
+func selectChanges(p domain.SpecProposal, keys []string) ([]domain.SpecChange, error) {
+	if len(keys) == 0 {
+		conflicted := map[string]bool{}
+		for _, c := range p.Conflicts {
+			conflicted[c.Key] = true
+		}
+		out := make([]domain.SpecChange, 0, len(p.Changes))
+		for _, ch := range p.Changes {
+			if !conflicted[ch.Key] {
+				out = append(out, ch)
+			}
+		}
+		return out, nil
+	}
+
+	byKey := make(map[string]domain.SpecChange, len(p.Changes))
+	for _, ch := range p.Changes {
+		byKey[ch.Key] = ch
+	}
+	out := make([]domain.SpecChange, 0, len(keys))
+	var errs []domain.SpecError
+	for _, k := range keys {
+		ch, ok := byKey[k]
+		if !ok {
+			errs = append(errs, domain.SpecError{Key: k, Message: fmt.Sprintf("proposal %d has no change for this key", p.ID)})
+			continue
+		}
+		out = append(out, ch)
+	}
+	if len(errs) > 0 {
+		return nil, &SpecValidationError{Errors: errs}
+	}
+	return out, nil
+}
@@ -0,0 +1,282 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"StartupPCConfigurator/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// revisionPrices возвращает текущие минимальные цены компонентов всех ревизий
+func (s *configService) revisionPrices(revs ...domain.ConfigurationRevision) (map[int]int, error) {
+	var ids []int
+	for _, rev := range revs {
+		for _, c := range rev.Components {
+			ids = append(ids, c.ID)
+		}
+	}
+	return s.repo.GetMinPrices(context.Background(), ids)
+}
+
+func revisionTotal(rev domain.ConfigurationRevision, prices map[int]int) int {
+	total := 0
+	for _, c := range rev.Components {
+		total += prices[c.ID] * c.Quantity
+	}
+	return total
+}
+
+// withSpecs подставляет в позиции ревизии specs из каталога
+func (s *configService) withSpecs(items []domain.ComponentRef) ([]domain.ComponentRef, error) {
+	ids := make([]int, 0, len(items))
+	for _, c := range items {
+		ids = append(ids, c.ID)
+	}
+	comps, err := s.repo.GetComponentsByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]domain.ComponentRef, len(items))
+	for i, c := range items {
+		out[i] = c
+		if comp, ok := comps[c.ID]; ok {
+			out[i].Specs = comp.Specs
+		}
+	}
+	return out, nil
+}
+
+// ListRevisions возвращает историю сборки владельца, новые ревизии первыми
+func (s *configService) ListRevisions(userID uuid.UUID, configID string) ([]domain.ConfigurationRevision, error) {
+	cfg, err := s.ownConfiguration(userID, configID)
+	if err != nil {
+		return nil, err
+	}
+	revs, err := s.repo.GetConfigurationRevisions(cfg.ID)
+	if err != nil {
+		return nil, err
+	}
+	prices, err := s.revisionPrices(revs...)
+	if err != nil {
+		return nil, err
+	}
+	for i := range revs {
+		revs[i].TotalPrice = revisionTotal(revs[i], prices)
+	}
+	return revs, nil
+}
+
+// GetRevision возвращает одну ревизию со specs компонентов
+func (s *configService) GetRevision(userID uuid.UUID, configID string, revision int) (domain.ConfigurationRevision, error) {
+	cfg, err := s.ownConfiguration(userID, configID)
+	if err != nil {
+		return domain.ConfigurationRevision{}, err
+	}
+	rev, err := s.repo.GetConfigurationRevision(cfg.ID, revision)
+	if err != nil {
+		return domain.ConfigurationRevision{}, err
+	}
+	if rev.Components, err = s.withSpecs(rev.Components); err != nil {
+		return domain.ConfigurationRevision{}, err
+	}
+	prices, err := s.revisionPrices(rev)
+	if err != nil {
+		return domain.ConfigurationRevision{}, err
+	}
+	rev.TotalPrice = revisionTotal(rev, prices)
+	return rev, nil
+}
+
+// RevisionComponentsRemovedError — ревизию не восстановить: часть её
+// компонентов удалена из каталога
+type RevisionComponentsRemovedError struct {
+	Revision   int
+	Components []domain.ComponentRef
+}
+
+func (e *RevisionComponentsRemovedError) Error() string {
+	names := make([]string, 0, len(e.Components))
+	for _, c := range e.Components {
+		names = append(names, fmt.Sprintf("%s / %s (id %d)", c.Category, c.Name, c.ID))
+	}
+	return fmt.Sprintf("revision %d contains removed components: %s", e.Revision, strings.Join(names, ", "))
+}
+
+func (e *RevisionComponentsRemovedError) Unwrap() error { return domain.ErrComponentNotFound }
+
+// RestoreRevision возвращает сборку к состоянию ревизии. Это обычная правка:
+// проверяется совместимость и создаётся новая ревизия, история не теряется.
+// Удалённые из каталога компоненты в сборку не возвращаются — такая ревизия
+// отклоняется с RevisionComponentsRemovedError.
+func (s *configService) RestoreRevision(userID uuid.UUID, configID string, revision int) (domain.Configuration, error) {
+	cfg, err := s.ownConfiguration(userID, configID)
+	if err != nil {
+		return domain.Configuration{}, err
+	}
+	rev, err := s.repo.GetConfigurationRevision(cfg.ID, revision)
+	if err != nil {
+		return domain.Configuration{}, err
+	}
+	refs := make([]domain.ComponentRef, 0, len(rev.Components))
+	var removed []domain.ComponentRef
+	for _, c := range rev.Components {
+		_, err := s.repo.GetComponentByID(c.Category, strconv.Itoa(c.ID))
+		if errors.Is(err, domain.ErrComponentNotFound) {
+			removed = append(removed, domain.ComponentRef{ID: c.ID, Name: c.Name, Category: c.Category, Brand: c.Brand})
+			continue
+		}
+		if err != nil {
+			return domain.Configuration{}, err
+		}
+		refs = append(refs, domain.ComponentRef{ID: c.ID, Name: c.Name, Category: c.Category, Quantity: c.Quantity})
+	}
+	if len(removed) > 0 {
+		return domain.Configuration{}, &RevisionComponentsRemovedError{Revision: revision, Components: removed}
+	}
+	return s.UpdateConfiguration(userID, configID, rev.Name, refs)
+}
+
+// DiffRevisions сравнивает две ревизии сборки. to = 0 — последняя ревизия.
+func (s *configService) DiffRevisions(userID uuid.UUID, configID string, from, to int, lang string) (domain.RevisionDiff, error) {
+	cfg, err := s.ownConfiguration(userID, configID)
+	if err != nil {
+		return domain.RevisionDiff{}, err
+	}
+	if to == 0 {
+		revs, err := s.repo.GetConfigurationRevisions(cfg.ID)
+		if err != nil {
+			return domain.RevisionDiff{}, err
+		}
+		if len(revs) == 0 {
+			return domain.RevisionDiff{}, domain.ErrRevisionNotFound
+		}
+		to = revs[0].Revision
+	}
+	a, err := s.repo.GetConfigurationRevision(cfg.ID, from)
+	if err != nil {
+		return domain.RevisionDiff{}, err
+	}
+	b, err := s.repo.GetConfigurationRevision(cfg.ID, to)
+	if err != nil {
+		return domain.RevisionDiff{}, err
+	}
+
+	prices, err := s.revisionPrices(a, b)
+	if err != nil {
+		return domain.RevisionDiff{}, err
+	}
+	out := domain.RevisionDiff{
+		ConfigID:       cfg.ID,
+		From:           from,
+		To:             to,
+		NameFrom:       a.Name,
+		NameTo:         b.Name,
+		Categories:     diffComponents(a.Components, b.Components),
+		TotalPriceFrom: revisionTotal(a, prices),
+		TotalPriceTo:   revisionTotal(b, prices),
+	}
+	out.TotalPriceDelta = out.TotalPriceTo - out.TotalPriceFrom
+
+	// совместимость — по действующим правилам для обеих ревизий
+	for _, side := range []struct {
+		items      []domain.ComponentRef
+		compatible *bool
+		errors     *int
+	}{
+		{a.Components, &out.CompatibleFrom, &out.ErrorsFrom},
+		{b.Components, &out.CompatibleTo, &out.ErrorsTo},
+	} {
+		items, err := s.withSpecs(side.items)
+		if err != nil {
+			return domain.RevisionDiff{}, err
+		}
+		report := CheckBuildReport(items, lang)
+		*side.compatible = report.Compatible
+		*side.errors = len(report.Errors())
+	}
+	return out, nil
+}
+
+// diffComponents раскладывает изменения по категориям. Удалённый и добавленный
+// компоненты одной категории считаются заменой; лишние — удалением или добавлением.
+func diffComponents(from, to []domain.ComponentRef) []domain.CategoryDiff {
+	byCat := func(items []domain.ComponentRef) map[string][]domain.ComponentRef {
+		m := map[string][]domain.ComponentRef{}
+		for _, c := range items {
+			cat := strings.ToLower(c.Category)
+			m[cat] = append(m[cat], c)
+		}
+		return m
+	}
+	fromCat, toCat := byCat(from), byCat(to)
+
+	cats := map[string]bool{}
+	for cat := range fromCat {
+		cats[cat] = true
+	}
+	for cat := range toCat {
+		cats[cat] = true
+	}
+
+	var out []domain.CategoryDiff
+	for cat := range cats {
+		d := domain.CategoryDiff{Category: cat}
+		toByID := map[int]domain.ComponentRef{}
+		for _, c := range toCat[cat] {
+			toByID[c.ID] = c
+		}
+		fromByID := map[int]bool{}
+		var removed, added []domain.ComponentRef
+		for _, c := range fromCat[cat] {
+			fromByID[c.ID] = true
+			next, ok := toByID[c.ID]
+			switch {
+			case !ok:
+				removed = append(removed, c)
+			case next.Quantity != c.Quantity:
+				d.QuantityChanged = append(d.QuantityChanged, domain.QuantityChange{
+					Component: next, From: c.Quantity, To: next.Quantity,
+				})
+			}
+		}
+		for _, c := range toCat[cat] {
+			if !fromByID[c.ID] {
+				added = append(added, c)
+			}
+		}
+		for len(removed) > 0 && len(added) > 0 {
+			d.Swapped = append(d.Swapped, domain.ComponentSwap{From: removed[0], To: added[0]})
+			removed, added = removed[1:], added[1:]
+		}
+		d.Removed, d.Added = removed, added
+
+		if len(d.Added)+len(d.Removed)+len(d.Swapped)+len(d.QuantityChanged) > 0 {
+			out = append(out, d)
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		a, b := categoryIndex(out[i].Category), categoryIndex(out[j].Category)
+		if a != b {
+			return a < b
+		}
+		return out[i].Category < out[j].Category
+	})
+	return out
+}
+
+// categoryIndex — место категории в domain.AllCategories; неизвестные — в конце
+func categoryIndex(cat string) int {
+	for i, c := range domain.AllCategories {
+		if string(c) == cat {
+			return i
+		}
+	}
+	return len(domain.AllCategories)
+}
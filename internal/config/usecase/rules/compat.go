@@ -0,0 +1,194 @@
+package rules
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Операторы правил совместимости
+const (
+	OpEq       = "eq"        // left == right (строки без учёта регистра)
+	OpPrefix   = "prefix"    // left начинается с right
+	OpContains = "contains"  // скаляр входит в список на другой стороне
+	OpLte      = "lte"       // left + offset ≤ right
+	OpSumLte   = "sum_lte"   // сумма всех left × quantity + offset ≤ right
+	OpCountLte = "count_lte" // число компонентов left (с учётом quantity) ≤ right
+)
+
+// Что делать, если у компонента нет нужной характеристики
+const (
+	MissingInfo  = "info" // по умолчанию: issue уровня info
+	MissingSkip  = "skip" // молча пропустить
+	MissingError = "error"
+)
+
+// CompatCondition — условие на характеристику компонента (для when / where)
+type CompatCondition struct {
+	Category string `yaml:"category" json:"category"`
+	Key      string `yaml:"key" json:"key"`
+	Op       string `yaml:"op" json:"op"` // eq | prefix
+	Value    string `yaml:"value" json:"value"`
+	Not      bool   `yaml:"not,omitempty" json:"not,omitempty"`
+}
+
+// CompatOperand — сторона правила: характеристика компонента категории
+// либо константа Value.
+type CompatOperand struct {
+	Category string           `yaml:"category,omitempty" json:"category,omitempty"`
+	Key      string           `yaml:"key,omitempty" json:"key,omitempty"`
+	Value    interface{}      `yaml:"value,omitempty" json:"value,omitempty"`
+	Default  interface{}      `yaml:"default,omitempty" json:"default,omitempty"` // для sum_lte, если характеристики нет
+	Where    *CompatCondition `yaml:"where,omitempty" json:"where,omitempty"`     // какие компоненты учитывать
+}
+
+// IsConst — операнд задан константой, а не характеристикой
+func (o CompatOperand) IsConst() bool {
+	return o.Value != nil && o.Category == ""
+}
+
+// CompatRule — декларативное правило совместимости
+type CompatRule struct {
+	ID       string            `yaml:"id" json:"id"`
+	Severity string            `yaml:"severity" json:"severity"` // error | warning | info
+	Op       string            `yaml:"op" json:"op"`
+	Left     []CompatOperand   `yaml:"left" json:"left"`
+	Right    CompatOperand     `yaml:"right" json:"right"`
+	Offset   float64           `yaml:"offset,omitempty" json:"offset,omitempty"`
+	When     []CompatCondition `yaml:"when,omitempty" json:"when,omitempty"`
+	Missing  string            `yaml:"missing,omitempty" json:"missing,omitempty"`
+	Messages map[string]string `yaml:"messages" json:"messages"`
+}
+
+// Categories — все категории, которые затрагивает правило
+func (r CompatRule) Categories() []string {
+	seen := map[string]bool{}
+	var out []string
+	add := func(c string) {
+		if c != "" && !seen[c] {
+			seen[c] = true
+			out = append(out, c)
+		}
+	}
+	for _, l := range r.Left {
+		add(l.Category)
+	}
+	add(r.Right.Category)
+	return out
+}
+
+//go:embed compat_rules.yaml
+var defaultCompatRules []byte
+
+// CompatRules — действующий набор правил. По умолчанию берётся из
+// встроенного compat_rules.yaml, сервис может подменить его через LoadCompatRules.
+var CompatRules = mustParseCompatRules(defaultCompatRules)
+
+func mustParseCompatRules(data []byte) []CompatRule {
+	rs, err := ParseCompatRules(data)
+	if err != nil {
+		panic(fmt.Sprintf("встроенные правила совместимости: %v", err))
+	}
+	return rs
+}
+
+// LoadCompatRules читает правила из YAML- или JSON-файла
+func LoadCompatRules(path string) ([]CompatRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseCompatRules(data)
+}
+
+// ParseCompatRules разбирает и валидирует набор правил.
+// JSON — подмножество YAML, поэтому отдельный парсер не нужен.
+func ParseCompatRules(data []byte) ([]CompatRule, error) {
+	var rs []CompatRule
+	if err := yaml.Unmarshal(data, &rs); err != nil {
+		return nil, err
+	}
+	ids := map[string]bool{}
+	for i := range rs {
+		if err := validateCompatRule(&rs[i]); err != nil {
+			return nil, fmt.Errorf("rule #%d (%s): %w", i, rs[i].ID, err)
+		}
+		if ids[rs[i].ID] {
+			return nil, fmt.Errorf("duplicate rule id %q", rs[i].ID)
+		}
+		ids[rs[i].ID] = true
+	}
+	return rs, nil
+}
+
+func validateCompatRule(r *CompatRule) error {
+	if r.ID == "" {
+		return fmt.Errorf("id is required")
+	}
+	switch r.Severity {
+	case "error", "warning", "info":
+	default:
+		return fmt.Errorf("unknown severity %q", r.Severity)
+	}
+	if r.Missing == "" {
+		r.Missing = MissingInfo
+	}
+	switch r.Missing {
+	case MissingInfo, MissingSkip, MissingError:
+	default:
+		return fmt.Errorf("unknown missing policy %q", r.Missing)
+	}
+	if len(r.Left) == 0 {
+		return fmt.Errorf("left operand is required")
+	}
+	switch r.Op {
+	case OpEq, OpPrefix, OpContains, OpLte:
+		if len(r.Left) != 1 {
+			return fmt.Errorf("op %s takes exactly one left operand", r.Op)
+		}
+	case OpSumLte, OpCountLte:
+	default:
+		return fmt.Errorf("unknown op %q", r.Op)
+	}
+	for _, l := range r.Left {
+		if l.Category == "" {
+			return fmt.Errorf("left operand needs a category")
+		}
+		if l.Key == "" && r.Op != OpCountLte {
+			return fmt.Errorf("left operand %s needs a key", l.Category)
+		}
+		if l.Where != nil && l.Where.Category == "" {
+			l.Where.Category = l.Category
+		}
+	}
+	if !r.Right.IsConst() && (r.Right.Category == "" || r.Right.Key == "") {
+		return fmt.Errorf("right operand needs category+key or value")
+	}
+	for _, w := range r.When {
+		if w.Category == "" || w.Key == "" {
+			return fmt.Errorf("when condition needs category and key")
+		}
+	}
+	if len(r.Messages) == 0 {
+		return fmt.Errorf("messages are required")
+	}
+	r.ID = strings.TrimSpace(r.ID)
+	return nil
+}
+
+// Match проверяет условие на значении характеристики
+func (c CompatCondition) Match(v interface{}) bool {
+	s := strings.ToUpper(fmt.Sprint(v))
+	want := strings.ToUpper(c.Value)
+	var ok bool
+	switch c.Op {
+	case "prefix":
+		ok = v != nil && strings.HasPrefix(s, want)
+	default:
+		ok = v != nil && s == want
+	}
+	return ok != c.Not
+}
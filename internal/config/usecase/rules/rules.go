@@ -1,17 +1,89 @@
 package rules
 
+import "fmt"
+
 // Расширяем ScenarioRule новыми полями Min/Max для CPU-TDP, RAM, GPU-памяти и PSU
 type ScenarioRule struct {
-	CPUSocketWhitelist             []string
-	MinCPUTDP, MaxCPUTDP           int
-	RAMType                        string
-	MinRAM, MaxRAM                 int
-	MinGPUMemory, MaxGPUMemory     int
-	MinPSUPower, MaxPSUPower       int
-	MinHDDCapacity, MaxHDDCapacity int // ёмкость HDD в ГБ
-	CaseFormFactors                []string
-	MinSSDThroughput               int      // минимальная пропускная способность, МБ/с
-	SSDFormFactors                 []string // допустимые форм-факторы: "M.2", "2.5", и т.д.
+	CPUSocketWhitelist []string `json:"cpuSocketWhitelist"`
+	MinCPUTDP          int      `json:"minCpuTdp"`
+	MaxCPUTDP          int      `json:"maxCpuTdp"`
+	RAMType            string   `json:"ramType"`
+	MinRAM             int      `json:"minRam"`
+	MaxRAM             int      `json:"maxRam"`
+	MinGPUMemory       int      `json:"minGpuMemory"`
+	MaxGPUMemory       int      `json:"maxGpuMemory"`
+	MinPSUPower        int      `json:"minPsuPower"`
+	MaxPSUPower        int      `json:"maxPsuPower"`
+	MinHDDCapacity     int      `json:"minHddCapacity"` // ёмкость HDD в ГБ
+	MaxHDDCapacity     int      `json:"maxHddCapacity"`
+	CaseFormFactors    []string `json:"caseFormFactors"`
+	MinSSDThroughput   int      `json:"minSsdThroughput"` // минимальная пропускная способность, МБ/с
+	SSDFormFactors     []string `json:"ssdFormFactors"`   // допустимые форм-факторы: "M.2", "2.5", и т.д.
+}
+
+// SpecRef — характеристика компонента, на которую опирается правило сценария
+type SpecRef struct {
+	Category string
+	Key      string
+}
+
+// SpecRefs возвращает характеристики, которые читают заданные (ненулевые) поля правила
+func (r ScenarioRule) SpecRefs() []SpecRef {
+	var out []SpecRef
+	add := func(used bool, cat string, keys ...string) {
+		if !used {
+			return
+		}
+		for _, k := range keys {
+			out = append(out, SpecRef{Category: cat, Key: k})
+		}
+	}
+	add(len(r.CPUSocketWhitelist) > 0, "cpu", "socket")
+	add(len(r.CPUSocketWhitelist) > 0, "motherboard", "socket")
+	add(len(r.CPUSocketWhitelist) > 0, "cooler", "socket")
+	add(r.MinCPUTDP > 0 || r.MaxCPUTDP > 0, "cpu", "tdp")
+	add(r.RAMType != "", "ram", "ram_type")
+	add(r.RAMType != "", "motherboard", "ram_type")
+	add(r.MinRAM > 0 || r.MaxRAM > 0, "ram", "capacity")
+	add(r.MinGPUMemory > 0 || r.MaxGPUMemory > 0, "gpu", "memory_gb")
+	add(r.MinPSUPower > 0 || r.MaxPSUPower > 0, "psu", "power")
+	add(r.MinHDDCapacity > 0 || r.MaxHDDCapacity > 0, "hdd", "capacity_gb", "interface")
+	add(len(r.CaseFormFactors) > 0, "case", "form_factor")
+	add(r.MinSSDThroughput > 0, "ssd", "max_throughput")
+	add(len(r.SSDFormFactors) > 0, "ssd", "form_factor")
+	return out
+}
+
+// Validate проверяет согласованность границ правила
+func (r ScenarioRule) Validate() error {
+	ranges := []struct {
+		name     string
+		min, max int
+	}{
+		{"cpu tdp", r.MinCPUTDP, r.MaxCPUTDP},
+		{"ram", r.MinRAM, r.MaxRAM},
+		{"gpu memory", r.MinGPUMemory, r.MaxGPUMemory},
+		{"psu power", r.MinPSUPower, r.MaxPSUPower},
+		{"hdd capacity", r.MinHDDCapacity, r.MaxHDDCapacity},
+	}
+	for _, rg := range ranges {
+		if rg.min < 0 || rg.max < 0 {
+			return fmt.Errorf("%s: negative bound", rg.name)
+		}
+		if rg.max > 0 && rg.min > rg.max {
+			return fmt.Errorf("%s: min %d > max %d", rg.name, rg.min, rg.max)
+		}
+	}
+	if r.MinSSDThroughput < 0 {
+		return fmt.Errorf("ssd throughput: negative bound")
+	}
+	if len(r.CPUSocketWhitelist) == 0 {
+		return fmt.Errorf("cpuSocketWhitelist is required")
+	}
+	if len(r.SSDFormFactors) == 0 {
+		return fmt.Errorf("ssdFormFactors is required")
+	}
+	return nil
 }
 
 var ScenarioRules = map[string]ScenarioRule{
@@ -126,3 +198,63 @@ var ScenarioRules = map[string]ScenarioRule{
 		MinHDDCapacity:   4000, MaxHDDCapacity: 16000, // роль хранилища
 	},
 }
+
+// веса одного сценария
+type Weights struct {
+	CPU float64 `json:"cpu"`
+	GPU float64 `json:"gpu"`
+	RAM float64 `json:"ram"`
+	SSD float64 `json:"ssd"`
+	HDD float64 `json:"hdd"`
+	PSU float64 `json:"psu"`
+}
+
+// глобальная карта «сценарий → веса» для rankCached
+var ScenarioWeights = map[string]Weights{
+	"office": {
+		CPU: 0.8, GPU: 0.2, RAM: 1, SSD: 0.5, HDD: 0.2, PSU: 0.5,
+	},
+	"htpc": {
+		CPU: 1.5, GPU: 0.5, RAM: 1, SSD: 1, HDD: 1, PSU: 0.5,
+	},
+	"gaming": {
+		CPU: 2.5, GPU: 5, RAM: 2, SSD: 2, HDD: 0.2, PSU: 1.2,
+	},
+	"streamer": {
+		CPU: 3.5, GPU: 3.5, RAM: 3, SSD: 2, HDD: 0.2, PSU: 1.5,
+	},
+	"design": {
+		CPU: 2.5, GPU: 4, RAM: 2, SSD: 1.5, HDD: 1, PSU: 1,
+	},
+	"video": {
+		CPU: 3.5, GPU: 4.5, RAM: 4, SSD: 2.5, HDD: 2, PSU: 1.5,
+	},
+	"cad": {
+		CPU: 4, GPU: 3, RAM: 4, SSD: 2, HDD: 0.2, PSU: 1,
+	},
+	"dev": {
+		CPU: 3, GPU: 0.8, RAM: 3, SSD: 2, HDD: 0.5, PSU: 1,
+	},
+	"enthusiast": {
+		CPU: 4, GPU: 5, RAM: 3, SSD: 1.5, HDD: 0.5, PSU: 1.2,
+	},
+	"nas": {
+		CPU: 1, GPU: 0.2, RAM: 2, SSD: 0.8, HDD: 2, PSU: 1,
+	},
+}
+
+// Validate — веса неотрицательны и хотя бы один больше нуля
+func (w Weights) Validate() error {
+	all := []float64{w.CPU, w.GPU, w.RAM, w.SSD, w.HDD, w.PSU}
+	var sum float64
+	for _, v := range all {
+		if v < 0 {
+			return fmt.Errorf("weights must be non-negative")
+		}
+		sum += v
+	}
+	if sum == 0 {
+		return fmt.Errorf("at least one weight must be positive")
+	}
+	return nil
+}
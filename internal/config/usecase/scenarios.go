@@ -0,0 +1,241 @@
+package usecase
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"sync"
+	"time"
+
+	"StartupPCConfigurator/internal/config/usecase/rules"
+	"StartupPCConfigurator/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidScenario — правила сценария не прошли валидацию
+var ErrInvalidScenario = errors.New("invalid scenario")
+
+// scenarioCacheTTL — как часто перечитывать правила из БД: их могли
+// поменять через другой экземпляр сервиса
+const scenarioCacheTTL = time.Minute
+
+var scenarioNameRe = regexp.MustCompile(`^[a-z0-9_-]{2,50}$`)
+
+// scenario — действующие правила и веса одного сценария
+type scenario struct {
+	rule    rules.ScenarioRule
+	weights rules.Weights
+	version int // 0 — встроенные правила, в БД ещё не сохранены
+}
+
+// scenarioCache держит действующие версии сценариев в памяти сервиса
+type scenarioCache struct {
+	mu       sync.RWMutex
+	items    map[string]scenario
+	loadedAt time.Time
+}
+
+// newScenarioCache заполняет кэш встроенными правилами — они работают,
+// пока правила не загружены из БД
+func newScenarioCache() *scenarioCache {
+	items := make(map[string]scenario, len(rules.ScenarioRules))
+	for name, r := range rules.ScenarioRules {
+		items[name] = scenario{rule: r, weights: rules.ScenarioWeights[name]}
+	}
+	return &scenarioCache{items: items}
+}
+
+// scenario возвращает действующие правила сценария, при необходимости
+// перечитывая их из БД
+func (s *configService) scenario(name string) (scenario, bool) {
+	s.scenarios.mu.RLock()
+	stale := time.Since(s.scenarios.loadedAt) > scenarioCacheTTL
+	sc, ok := s.scenarios.items[name]
+	s.scenarios.mu.RUnlock()
+
+	if stale {
+		if err := s.ReloadScenarios(); err != nil {
+			log.Printf("scenario rules reload: %v", err)
+		}
+		s.scenarios.mu.RLock()
+		sc, ok = s.scenarios.items[name]
+		s.scenarios.mu.RUnlock()
+	}
+	return sc, ok
+}
+
+// InitScenarios сохраняет встроенные правила в БД для сценариев, у которых
+// ещё нет ни одной версии, и загружает действующие правила в кэш
+func (s *configService) InitScenarios() error {
+	active, err := s.repo.GetActiveScenarios()
+	if err != nil {
+		return err
+	}
+	have := map[string]bool{}
+	for _, sc := range active {
+		have[sc.Name] = true
+	}
+	for name, r := range rules.ScenarioRules {
+		if have[name] {
+			continue
+		}
+		ruleJSON, _ := json.Marshal(r)
+		weightsJSON, _ := json.Marshal(rules.ScenarioWeights[name])
+		if _, err := s.repo.CreateScenarioVersion(domain.Scenario{
+			Name:    name,
+			Rule:    ruleJSON,
+			Weights: weightsJSON,
+			Comment: "встроенные правила",
+		}); err != nil {
+			return fmt.Errorf("seed scenario %s: %w", name, err)
+		}
+	}
+	return s.ReloadScenarios()
+}
+
+// ReloadScenarios перечитывает действующие версии сценариев из БД
+func (s *configService) ReloadScenarios() error {
+	active, err := s.repo.GetActiveScenarios()
+
+	s.scenarios.mu.Lock()
+	defer s.scenarios.mu.Unlock()
+	// даже при ошибке не долбим БД на каждом запросе — повторим через TTL
+	s.scenarios.loadedAt = time.Now()
+	if err != nil {
+		return err
+	}
+
+	items := make(map[string]scenario, len(rules.ScenarioRules)+len(active))
+	for name, r := range rules.ScenarioRules {
+		items[name] = scenario{rule: r, weights: rules.ScenarioWeights[name]}
+	}
+	for _, sc := range active {
+		parsed, err := decodeScenario(sc.Rule, sc.Weights)
+		if err != nil {
+			log.Printf("scenario %s v%d: %v", sc.Name, sc.Version, err)
+			continue
+		}
+		parsed.version = sc.Version
+		items[sc.Name] = parsed
+	}
+	s.scenarios.items = items
+	return nil
+}
+
+// decodeScenario разбирает и валидирует правила и веса сценария
+func decodeScenario(ruleJSON, weightsJSON json.RawMessage) (scenario, error) {
+	var sc scenario
+	if err := decodeStrict(ruleJSON, &sc.rule); err != nil {
+		return sc, fmt.Errorf("%w: rule: %v", ErrInvalidScenario, err)
+	}
+	if err := decodeStrict(weightsJSON, &sc.weights); err != nil {
+		return sc, fmt.Errorf("%w: weights: %v", ErrInvalidScenario, err)
+	}
+	if err := sc.rule.Validate(); err != nil {
+		return sc, fmt.Errorf("%w: rule: %v", ErrInvalidScenario, err)
+	}
+	if err := sc.weights.Validate(); err != nil {
+		return sc, fmt.Errorf("%w: weights: %v", ErrInvalidScenario, err)
+	}
+	// правило может опираться только на характеристики, описанные для категории
+	for _, ref := range sc.rule.SpecRefs() {
+		if !domain.HasExpectedSpec(domain.ComponentCategory(ref.Category), ref.Key) {
+			return sc, fmt.Errorf("%w: spec %s.%s is not in ExpectedSpecs", ErrInvalidScenario, ref.Category, ref.Key)
+		}
+	}
+	return sc, nil
+}
+
+func decodeStrict(data json.RawMessage, v interface{}) error {
+	if len(data) == 0 {
+		return errors.New("is required")
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+
+// ListScenarios возвращает действующие версии всех сценариев
+func (s *configService) ListScenarios() ([]domain.Scenario, error) {
+	return s.repo.GetActiveScenarios()
+}
+
+// GetScenarioVersions возвращает историю версий сценария
+func (s *configService) GetScenarioVersions(name string) ([]domain.Scenario, error) {
+	return s.repo.GetScenarioVersions(name)
+}
+
+// CreateScenario заводит новый сценарий с первой версией правил
+func (s *configService) CreateScenario(userID uuid.UUID, in domain.Scenario) (domain.Scenario, error) {
+	if !scenarioNameRe.MatchString(in.Name) {
+		return domain.Scenario{}, fmt.Errorf("%w: name must match %s", ErrInvalidScenario, scenarioNameRe)
+	}
+	if _, err := s.repo.GetScenarioVersions(in.Name); err == nil {
+		return domain.Scenario{}, domain.ErrScenarioExists
+	} else if !errors.Is(err, domain.ErrScenarioNotFound) {
+		return domain.Scenario{}, err
+	}
+	return s.saveScenario(userID, in)
+}
+
+// UpdateScenario сохраняет новую версию правил сценария. Если веса не
+// переданы, они берутся из действующей версии.
+func (s *configService) UpdateScenario(userID uuid.UUID, name string, in domain.Scenario) (domain.Scenario, error) {
+	versions, err := s.repo.GetScenarioVersions(name)
+	if err != nil {
+		return domain.Scenario{}, err
+	}
+	in.Name = name
+	if len(in.Weights) == 0 {
+		for _, v := range versions {
+			if v.Active {
+				in.Weights = v.Weights
+			}
+		}
+	}
+	return s.saveScenario(userID, in)
+}
+
+func (s *configService) saveScenario(userID uuid.UUID, in domain.Scenario) (domain.Scenario, error) {
+	if _, err := decodeScenario(in.Rule, in.Weights); err != nil {
+		return domain.Scenario{}, err
+	}
+	in.CreatedBy = &userID
+	saved, err := s.repo.CreateScenarioVersion(in)
+	if err != nil {
+		return domain.Scenario{}, err
+	}
+	return saved, s.ReloadScenarios()
+}
+
+// RollbackScenario делает действующей одну из прежних версий сценария
+func (s *configService) RollbackScenario(name string, version int) (domain.Scenario, error) {
+	versions, err := s.repo.GetScenarioVersions(name)
+	if err != nil {
+		return domain.Scenario{}, err
+	}
+	for _, v := range versions {
+		if v.Version != version {
+			continue
+		}
+		// старая версия могла стать невалидной после смены ExpectedSpecs
+		if _, err := decodeScenario(v.Rule, v.Weights); err != nil {
+			return domain.Scenario{}, err
+		}
+		saved, err := s.repo.ActivateScenarioVersion(name, version)
+		if err != nil {
+			return domain.Scenario{}, err
+		}
+		return saved, s.ReloadScenarios()
+	}
+	return domain.Scenario{}, domain.ErrScenarioNotFound
+}
+
+// IsSuperuser проверяет права администратора
+func (s *configService) IsSuperuser(userID uuid.UUID) (bool, error) {
+	return s.repo.IsSuperuser(userID)
+}
@@ -0,0 +1,175 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"time"
+
+	"StartupPCConfigurator/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// shareSlugBytes — длина случайной части ссылки: 128 бит, перебором не угадать
+const shareSlugBytes = 16
+
+// ErrInvalidShareExpiry — срок действия ссылки уже прошёл
+var ErrInvalidShareExpiry = errors.New("expiresAt must be in the future")
+
+func newShareSlug() (string, error) {
+	b := make([]byte, shareSlugBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// ownConfiguration возвращает сборку, если она принадлежит пользователю
+func (s *configService) ownConfiguration(userID uuid.UUID, configID string) (domain.Configuration, error) {
+	cfg, err := s.repo.GetConfigurationByID(configID)
+	if err != nil {
+		return domain.Configuration{}, err
+	}
+	if cfg.UserID != userID {
+		return domain.Configuration{}, domain.ErrForbidden
+	}
+	return cfg, nil
+}
+
+// CreateShare публикует сборку по ссылке только для чтения.
+// expiresAt = nil — ссылка бессрочная.
+func (s *configService) CreateShare(userID uuid.UUID, configID string, expiresAt *time.Time) (domain.ConfigurationShare, error) {
+	cfg, err := s.ownConfiguration(userID, configID)
+	if err != nil {
+		return domain.ConfigurationShare{}, err
+	}
+	if expiresAt != nil {
+		if !expiresAt.After(time.Now()) {
+			return domain.ConfigurationShare{}, ErrInvalidShareExpiry
+		}
+		utc := expiresAt.UTC()
+		expiresAt = &utc
+	}
+	slug, err := newShareSlug()
+	if err != nil {
+		return domain.ConfigurationShare{}, err
+	}
+	return s.repo.CreateShare(domain.ConfigurationShare{
+		ConfigID:  cfg.ID,
+		Slug:      slug,
+		CreatedBy: userID,
+		ExpiresAt: expiresAt,
+	})
+}
+
+// ListShares возвращает ссылки на сборку владельца, включая отозванные
+func (s *configService) ListShares(userID uuid.UUID, configID string) ([]domain.ConfigurationShare, error) {
+	cfg, err := s.ownConfiguration(userID, configID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetConfigurationShares(cfg.ID)
+}
+
+// RevokeShare отзывает ссылку; отозвать может только владелец сборки
+func (s *configService) RevokeShare(userID uuid.UUID, slug string) error {
+	sh, err := s.repo.GetShareBySlug(slug)
+	if err != nil {
+		return err
+	}
+	if _, err := s.ownConfiguration(userID, strconv.Itoa(sh.ConfigID)); err != nil {
+		return err
+	}
+	return s.repo.RevokeShare(slug)
+}
+
+// activeShare находит действующую ссылку и сборку, на которую она ведёт
+func (s *configService) activeShare(slug string) (domain.ConfigurationShare, domain.Configuration, error) {
+	sh, err := s.repo.GetShareBySlug(slug)
+	if err != nil {
+		return sh, domain.Configuration{}, err
+	}
+	if !sh.Active(time.Now().UTC()) {
+		return sh, domain.Configuration{}, domain.ErrShareExpired
+	}
+	cfg, err := s.repo.GetConfigurationByID(strconv.Itoa(sh.ConfigID))
+	if errors.Is(err, domain.ErrConfigNotFound) {
+		return sh, cfg, domain.ErrShareNotFound
+	}
+	return sh, cfg, err
+}
+
+// GetSharedConfiguration открывает сборку по ссылке: с текущими минимальными
+// ценами и проверкой совместимости по действующим правилам
+func (s *configService) GetSharedConfiguration(slug, lang string) (domain.SharedConfiguration, error) {
+	sh, cfg, err := s.activeShare(slug)
+	if err != nil {
+		return domain.SharedConfiguration{}, err
+	}
+
+	ids := make([]int, 0, len(cfg.Components))
+	for _, c := range cfg.Components {
+		ids = append(ids, c.ID)
+	}
+	offers, err := s.repo.GetCheapestOffers(context.Background(), ids)
+	if err != nil {
+		return domain.SharedConfiguration{}, err
+	}
+
+	out := domain.SharedConfiguration{
+		Slug:          sh.Slug,
+		Name:          cfg.Name,
+		Components:    make([]domain.PricedComponent, 0, len(cfg.Components)),
+		Compatibility: CheckBuildReport(cfg.Components, lang),
+		ExpiresAt:     sh.ExpiresAt,
+		UpdatedAt:     cfg.UpdatedAt,
+	}
+	for _, c := range cfg.Components {
+		qty := c.Quantity
+		if qty < 1 {
+			qty = 1
+		}
+		pc := domain.PricedComponent{
+			Component: domain.Component{
+				ID:       c.ID,
+				Name:     c.Name,
+				Category: c.Category,
+				Brand:    c.Brand,
+				Specs:    c.Specs,
+			},
+			Quantity: qty,
+		}
+		if o, ok := offers[c.ID]; ok {
+			pc.UnitPrice = o.Price
+			pc.Price = o.Price * qty
+			pc.ShopID = o.ShopID
+			pc.ShopCode = o.ShopCode
+			pc.ShopName = o.ShopName
+			pc.URL = o.URL
+		}
+		out.TotalPrice += pc.Price
+		out.Components = append(out.Components, pc)
+	}
+	return out, nil
+}
+
+// ForkSharedConfiguration копирует сборку по ссылке в сборки пользователя.
+// Копия сохраняется как есть, даже если правила совместимости с тех пор изменились.
+func (s *configService) ForkSharedConfiguration(userID uuid.UUID, slug, name string) (domain.Configuration, error) {
+	_, cfg, err := s.activeShare(slug)
+	if err != nil {
+		return domain.Configuration{}, err
+	}
+	if name == "" {
+		name = cfg.Name + " (копия)"
+	}
+	fork, err := s.repo.CreateConfiguration(userID, name, cfg.Components)
+	if err != nil {
+		return domain.Configuration{}, err
+	}
+	s.fillSavedTotalPrice(&fork)
+	return fork, nil
+}
@@ -13,7 +13,7 @@ const (
 	CategoryGPU           ComponentCategory = "gpu"
 	CategoryPSU           ComponentCategory = "psu"
 	CategoryCase          ComponentCategory = "case"
-	CategoryCooler        ComponentCategory = "cpu_cooler"
+	CategoryCooler        ComponentCategory = "cooler"
 	CategorySSD           ComponentCategory = "ssd"
 	CategoryHDD           ComponentCategory = "hdd"
 	CategoryCaseFan       ComponentCategory = "case_fan"
@@ -58,18 +58,20 @@ func IsValidCategory(input string) bool {
 	return ok
 }
 
-// ExpectedSpecs описывает обязательные поля в specs для каждой категории
+// ExpectedSpecs описывает обязательные поля в specs для каждой категории.
+// Ключи совпадают с тем, что лежит в каталоге (db/init/init.sql) и что читают
+// правила совместимости и сценариев.
 var ExpectedSpecs = map[ComponentCategory][]string{
-	CategoryCPU:           {"socket", "tdp", "supported_ram_type", "max_ram_freq"},
-	CategoryMotherboard:   {"socket", "ram_type", "form_factor"},
-	CategoryRAM:           {"ram_type", "frequency", "capacity"},
-	CategoryGPU:           {"length_mm", "power_draw"},
-	CategoryPSU:           {"power", "pcie_connectors"},
-	CategoryCase:          {"form_factor_support", "gpu_max_length", "cooler_max_height"},
-	CategoryCooler:        {"supported_sockets", "height_mm"},
-	CategorySSD:           {"interface", "form_factor", "nvme"},
-	CategoryHDD:           {"interface", "rpm", "capacity"},
-	CategoryCaseFan:       {"diameter", "rpm", "airflow", "connector_type"},
+	CategoryCPU:           {"socket", "tdp", "cores", "threads", "power_draw"},
+	CategoryMotherboard:   {"socket", "ram_type", "form_factor", "memory_slots", "m2_slots", "sata_ports", "pcie_version"},
+	CategoryRAM:           {"ram_type", "frequency", "capacity", "modules"},
+	CategoryGPU:           {"length_mm", "power_draw", "memory_gb", "interface"},
+	CategoryPSU:           {"power", "form_factor"},
+	CategoryCase:          {"form_factor", "max_motherboard_form_factors", "gpu_max_length", "cooler_max_height", "psu_form_factor", "drive_bays_2_5", "drive_bays_3_5"},
+	CategoryCooler:        {"socket", "height_mm"},
+	CategorySSD:           {"interface", "form_factor", "capacity_gb", "max_throughput"},
+	CategoryHDD:           {"interface", "form_factor", "rpm", "capacity_gb"},
+	CategoryCaseFan:       {"size_mm", "rpm", "power_draw"},
 	CategoryWiFiAdapter:   {"interface", "wifi_standard"},
 	CategoryOpticalDrive:  {"interface", "form_factor"},
 	CategorySoundCard:     {"interface", "channels"},
@@ -77,6 +79,16 @@ var ExpectedSpecs = map[ComponentCategory][]string{
 	CategoryRGBController: {"interface", "channels", "software_support"},
 }
 
+// HasExpectedSpec — ключ описан в ExpectedSpecs для категории
+func HasExpectedSpec(category ComponentCategory, key string) bool {
+	for _, k := range ExpectedSpecs[category] {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
 // ValidateSpecs проверяет, что в specs есть все ожидаемые поля для заданной категории
 func ValidateSpecs(category ComponentCategory, specs map[string]interface{}) []string {
 	missing := []string{}
@@ -43,6 +43,31 @@ type Component struct {
 	UpdatedAt time.Time       `json:"updated_at"`
 }
 
+// ConfigurationRef — краткая ссылка на сборку (например, в ошибке удаления компонента)
+type ConfigurationRef struct {
+	ID     int       `json:"id"`
+	Name   string    `json:"name"`
+	UserID uuid.UUID `json:"userId"`
+}
+
+// ComponentImportRow — результат импорта одной строки каталога
+type ComponentImportRow struct {
+	Row    int         `json:"row"` // номер строки во входных данных, с 1
+	ID     int         `json:"id,omitempty"`
+	Name   string      `json:"name"`
+	Status string      `json:"status"` // created | updated | restored | error
+	Errors []SpecError `json:"errors,omitempty"`
+}
+
+// ComponentImportReport — итог массового импорта компонентов
+type ComponentImportReport struct {
+	DryRun  bool                 `json:"dryRun"`
+	Created int                  `json:"created"`
+	Updated int                  `json:"updated"`
+	Failed  int                  `json:"failed"`
+	Rows    []ComponentImportRow `json:"rows"`
+}
+
 // Это то, что мы получаем в CreateConfigRequest/UpdateConfigRequest
 
 type ComponentRef struct {
@@ -50,7 +75,8 @@ type ComponentRef struct {
 	Name     string          `json:"name"`
 	Category string          `json:"category"`
 	Brand    string          `json:"brand,omitempty"`
-	Specs    json.RawMessage `gorm:"type:jsonb"       json:"specs"`
+	Specs    json.RawMessage `gorm:"type:jsonb"       json:"specs,omitempty"`
+	Quantity int             `json:"quantity,omitempty"` // сколько штук в сборке (0 = 1)
 }
 
 // Структура «Конфигурация» (сборка)
@@ -59,26 +85,66 @@ type Configuration struct {
 	UserID     uuid.UUID      `db:"user_id" json:"UserID"`
 	Name       string         `db:"name" json:"Name"`
 	Components []ComponentRef `json:"components"`
+	TotalPrice int            `json:"totalPrice"` // сумма минимальных цен × количество
 	CreatedAt  time.Time      `db:"created_at" json:"CreatedAt"`
 	UpdatedAt  time.Time      `db:"updated_at" json:"UpdatedAt"`
 }
 
 var (
-	ErrConfigNotFound = errors.New("configuration not found")
-	ErrForbidden      = errors.New("forbidden")
+	ErrConfigNotFound    = errors.New("configuration not found")
+	ErrForbidden         = errors.New("forbidden")
+	ErrScenarioNotFound  = errors.New("scenario not found")
+	ErrScenarioExists    = errors.New("scenario already exists")
+	ErrComponentNotFound = errors.New("component not found")
+	ErrComponentInUse    = errors.New("component is used in configurations")
+	ErrShareNotFound     = errors.New("share link not found")
+	ErrShareExpired      = errors.New("share link has expired or was revoked")
+	ErrRevisionNotFound  = errors.New("revision not found")
+	ErrProposalNotFound  = errors.New("spec proposal not found")
+	ErrProposalReviewed  = errors.New("spec proposal has already been reviewed")
+	ErrShopNotFound      = errors.New("shop not found")
+	ErrJobNotFound       = errors.New("job not found")
 )
 
+// AvailabilityStatus — наличие товара в магазине, приведённое к перечислению
+type AvailabilityStatus string
+
+const (
+	AvailabilityInStock    AvailabilityStatus = "in_stock"
+	AvailabilityLimited    AvailabilityStatus = "limited" // мало, заканчивается
+	AvailabilityPreorder   AvailabilityStatus = "preorder"
+	AvailabilityOutOfStock AvailabilityStatus = "out_of_stock"
+	AvailabilityUnknown    AvailabilityStatus = "unknown" // текст не распознан
+)
+
+// Available — товар можно купить сейчас
+func (s AvailabilityStatus) Available() bool {
+	return s == AvailabilityInStock || s == AvailabilityLimited
+}
+
 type Offer struct {
-	ID           int64   `json:"-"`
-	ComponentID  string  `json:"componentId"`
-	ShopID       int64   `json:"shopId"`
-	ShopCode     string  `json:"shopCode"`
-	ShopName     string  `json:"shopName"`
-	Price        float64 `json:"price"`
-	Currency     string  `json:"currency"`
-	Availability string  `json:"availability"`
-	URL          string  `json:"url"`
-	FetchedAt    string  `json:"fetchedAt"`
+	ID                 int64              `json:"-"`
+	ComponentID        string             `json:"componentId"`
+	ShopID             int64              `json:"shopId"`
+	ShopCode           string             `json:"shopCode"`
+	ShopName           string             `json:"shopName"`
+	Price              float64            `json:"price"`
+	Currency           string             `json:"currency"`
+	Availability       string             `json:"availability"` // текст как у магазина
+	AvailabilityStatus AvailabilityStatus `json:"availabilityStatus"`
+	StoreCount         *int               `json:"storeCount,omitempty"` // «в наличии в 3 магазинах»
+	URL                string             `json:"url"`
+	FetchedAt          string             `json:"fetchedAt"`
+}
+
+// CheapestOffer — самое дешёвое предложение по компоненту
+type CheapestOffer struct {
+	ComponentID int    `json:"componentId"`
+	ShopID      int64  `json:"shopId"`
+	ShopCode    string `json:"shopCode"`
+	ShopName    string `json:"shopName"`
+	Price       int    `json:"price"` // рубли, как в GetMinPrices
+	URL         string `json:"url"`
 }
 
 // OffersFilter описывает фильтры/параметры для запроса
@@ -87,6 +153,83 @@ type OffersFilter struct {
 	Sort        string // "priceAsc" | "priceDesc" | ...
 }
 
+// PriceImportSkip — строка прайс-листа, которая не попала в offers
+type PriceImportSkip struct {
+	Row         int    `json:"row"` // номер строки в файле, с 1
+	ComponentID string `json:"componentId,omitempty"`
+	ShopCode    string `json:"shopCode,omitempty"`
+	Reason      string `json:"reason"`
+}
+
+// PriceChange — цена из прайс-листа отличается от сохранённой
+type PriceChange struct {
+	ComponentID string  `json:"componentId"`
+	ShopCode    string  `json:"shopCode"`
+	OldPrice    float64 `json:"oldPrice"`
+	NewPrice    float64 `json:"newPrice"`
+	Currency    string  `json:"currency"`
+}
+
+// PriceImportReport — итог импорта прайс-листа
+type PriceImportReport struct {
+	JobID             int64             `json:"jobId,omitempty"`
+	DryRun            bool              `json:"dryRun"`
+	Format            string            `json:"format"`          // csv | xlsx
+	Sheet             string            `json:"sheet,omitempty"` // только для xlsx
+	Rows              int               `json:"rows"`            // строк с данными, без заголовка
+	Imported          int               `json:"imported"`
+	Skipped           int               `json:"skipped"`
+	UnknownShops      []string          `json:"unknownShops"`
+	UnknownComponents []string          `json:"unknownComponents"`
+	PriceChanges      []PriceChange     `json:"priceChanges"`
+	SkippedRows       []PriceImportSkip `json:"skippedRows"`
+}
+
+// Типы и статусы задач update_jobs
+const (
+	JobKindShopUpdate  = "shop_update"
+	JobKindPriceImport = "price_import"
+
+	JobRunning = "running"
+	JobDone    = "done"
+	JobFailed  = "failed"
+)
+
+// ImportJob — импорт прайс-листа, записанный в update_jobs
+type ImportJob struct {
+	ID            int64              `json:"id"`
+	Ref           string             `json:"ref,omitempty"` // id, выданный importer'ом до постановки в очередь
+	Status        string             `json:"status"`
+	FileName      string             `json:"fileName"`
+	ShopCode      string             `json:"shopCode,omitempty"`
+	DryRun        bool               `json:"dryRun"`
+	RowsTotal     int                `json:"rowsTotal"`
+	RowsProcessed int                `json:"rowsProcessed"`
+	Imported      int                `json:"imported"`
+	Skipped       int                `json:"skipped"`
+	Error         string             `json:"error,omitempty"`
+	Report        *PriceImportReport `json:"report,omitempty"` // только в GET /offers/imports/:id
+	CreatedAt     time.Time          `json:"createdAt"`
+	StartedAt     *time.Time         `json:"startedAt,omitempty"`
+	FinishedAt    *time.Time         `json:"finishedAt,omitempty"`
+}
+
+// Finished — задача завершена, успешно или нет
+func (j ImportJob) Finished() bool {
+	return j.Status == JobDone || j.Status == JobFailed
+}
+
+// OutboxEvent — событие, записанное в outbox одной транзакцией с изменением
+// данных; relay агрегатора публикует его в RabbitMQ
+type OutboxEvent struct {
+	ID         int64
+	EventID    string // uuid: по нему получатели отсекают повторную доставку
+	RoutingKey string
+	Payload    json.RawMessage
+	Attempts   int
+	CreatedAt  time.Time
+}
+
 type UpdateEvent struct {
 	ShopID   string `json:"shopId"`
 	Action   string `json:"action"`
@@ -99,6 +242,43 @@ type CompatibilityFilter struct {
 	Specs         map[string]interface{}
 }
 
+// CompatibilitySeverity — насколько серьёзна проблема совместимости
+type CompatibilitySeverity string
+
+const (
+	SeverityError   CompatibilitySeverity = "error"   // сборка не заработает
+	SeverityWarning CompatibilitySeverity = "warning" // заработает, но с ограничениями
+	SeverityInfo    CompatibilitySeverity = "info"    // проверить не удалось (нет данных)
+)
+
+// CompatibilityIssue — одна найденная проблема между компонентами сборки
+type CompatibilityIssue struct {
+	RuleID       string                `json:"ruleId"`
+	Severity     CompatibilitySeverity `json:"severity"`
+	ComponentIDs []int                 `json:"componentIds"`
+	Categories   []string              `json:"categories"`
+	SpecKeys     []string              `json:"specKeys"`
+	Message      string                `json:"message"`
+}
+
+// CompatibilityReport — результат проверки сборки.
+// Compatible = true, если нет ни одной проблемы уровня error.
+type CompatibilityReport struct {
+	Compatible bool                 `json:"compatible"`
+	Issues     []CompatibilityIssue `json:"issues"`
+}
+
+// Errors возвращает только проблемы уровня error
+func (r CompatibilityReport) Errors() []CompatibilityIssue {
+	var out []CompatibilityIssue
+	for _, is := range r.Issues {
+		if is.Severity == SeverityError {
+			out = append(out, is)
+		}
+	}
+	return out
+}
+
 // UseCase — описание сценария сборки
 type UseCase struct {
 	ID          int    `json:"id"`
@@ -106,28 +286,224 @@ type UseCase struct {
 	Description string `json:"description"`
 }
 
+// Scenario — одна версия правил сценария сборки. Rule и Weights хранятся
+// в JSONB и разбираются в rules.ScenarioRule / rules.Weights.
+type Scenario struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Version     int             `json:"version"`
+	Active      bool            `json:"active"`
+	Rule        json.RawMessage `json:"rule"`
+	Weights     json.RawMessage `json:"weights"`
+	Comment     string          `json:"comment,omitempty"`
+	CreatedBy   *uuid.UUID      `json:"createdBy,omitempty"`
+	CreatedAt   time.Time       `json:"createdAt"`
+}
+
+// Типы уведомлений
+const (
+	NotificationPriceChanged = "price_changed"
+	NotificationBackInStock  = "back_in_stock"
+	NotificationOutOfStock   = "out_of_stock"
+	NotificationConfigPrice  = "configuration_price"
+)
+
 type Notification struct {
-	ID          uuid.UUID `db:"id" json:"id"`
-	UserID      uuid.UUID `db:"user_id" json:"userId"`
-	ComponentID string    `db:"component_id" json:"componentId"`
-	ShopID      int64     `db:"shop_id" json:"shopId"`
-	OldPrice    float64   `db:"old_price" json:"oldPrice"`
-	NewPrice    float64   `db:"new_price" json:"newPrice"`
-	IsRead      bool      `db:"is_read" json:"isRead"`
-	CreatedAt   time.Time `db:"created_at" json:"createdAt"`
+	ID           uuid.UUID          `db:"id" json:"id"`
+	UserID       uuid.UUID          `db:"user_id" json:"userId"`
+	Type         string             `db:"type" json:"type"`
+	ComponentID  string             `db:"component_id" json:"componentId"`
+	ShopID       int64              `db:"shop_id" json:"shopId"`
+	OldPrice     float64            `db:"old_price" json:"oldPrice"`
+	NewPrice     float64            `db:"new_price" json:"newPrice"`
+	Availability AvailabilityStatus `db:"availability" json:"availability,omitempty"` // для уведомлений о наличии
+	ConfigID     *int               `db:"config_id" json:"configId,omitempty"`        // для уведомлений по сборке
+	Details      json.RawMessage    `db:"details" json:"details,omitempty"`           // ConfigurationAlert
+	IsRead       bool               `db:"is_read" json:"isRead"`
+	CreatedAt    time.Time          `db:"created_at" json:"createdAt"`
+}
+
+// Направления подписки на цену
+const (
+	DirectionDrop = "drop" // только снижение цены
+	DirectionAny  = "any"  // любое изменение
+)
+
+// Подписка на наличие
+const (
+	StockAlertBackInStock = "back_in_stock" // товар снова появился в продаже
+	StockAlertAny         = "any"           // появился или закончился
+)
+
+// Subscription — подписка на цену и наличие компонента с условиями уведомления.
+// Пустые условия — уведомление о любом снижении цены.
+type Subscription struct {
+	UserID         uuid.UUID `json:"-"`
+	ComponentID    string    `json:"componentId"`
+	PriceAlerts    bool      `json:"priceAlerts"`              // уведомлять об изменении цены
+	TargetPrice    *float64  `json:"targetPrice,omitempty"`    // цена должна стать не выше
+	MinDropPercent *float64  `json:"minDropPercent,omitempty"` // минимальное изменение цены, %
+	Direction      string    `json:"direction"`                // drop | any
+	StockAlert     string    `json:"stockAlert,omitempty"`     // back_in_stock | any; пусто — без уведомлений о наличии
+	ShopID         *int64    `json:"shopId,omitempty"`         // только этот магазин
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+// Matches — изменение цены в магазине shopID удовлетворяет условиям подписки.
+// oldPrice = 0 — у магазина раньше не было цены.
+func (s Subscription) Matches(shopID int64, oldPrice, newPrice float64) bool {
+	if !s.PriceAlerts || (s.ShopID != nil && *s.ShopID != shopID) {
+		return false
+	}
+	if newPrice == oldPrice || newPrice <= 0 {
+		return false
+	}
+	if s.TargetPrice != nil && newPrice > *s.TargetPrice {
+		return false
+	}
+	if oldPrice <= 0 {
+		// новое предложение: интересно, если попало в целевую цену
+		return s.TargetPrice != nil || s.Direction == DirectionAny
+	}
+	if s.Direction != DirectionAny && newPrice > oldPrice {
+		return false
+	}
+	if s.MinDropPercent != nil {
+		change := (oldPrice - newPrice) / oldPrice * 100
+		if change < 0 {
+			change = -change
+		}
+		if change < *s.MinDropPercent {
+			return false
+		}
+	}
+	return true
+}
+
+// MatchesStock — товар в магазине shopID появился (inStock) или закончился,
+// и подписка просит об этом сообщить
+func (s Subscription) MatchesStock(shopID int64, inStock bool) bool {
+	if s.ShopID != nil && *s.ShopID != shopID {
+		return false
+	}
+	switch s.StockAlert {
+	case StockAlertAny:
+		return true
+	case StockAlertBackInStock:
+		return inStock
+	}
+	return false
+}
+
+// Типы событий потока GET /notifications/stream
+const (
+	NotificationEventCreated = "notification" // Data — Notification
+	NotificationEventUnread  = "unread"       // Data — {"unread": N}
+)
+
+// NotificationEvent — событие для подключённых клиентов. ID — позиция в
+// Redis Stream пользователя, клиент возвращает её в Last-Event-ID.
+type NotificationEvent struct {
+	ID     string          `json:"id"`
+	UserID uuid.UUID       `json:"userId"`
+	Type   string          `json:"type"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// ConfigurationWatch — слежение за общей стоимостью сохранённой сборки
+type ConfigurationWatch struct {
+	UserID         uuid.UUID       `json:"-"`
+	ConfigID       int             `json:"configId"`
+	ConfigName     string          `json:"configName"`
+	Budget         *float64        `json:"budget,omitempty"`         // сообщить, когда стоимость пересечёт бюджет
+	MinDropPercent *float64        `json:"minDropPercent,omitempty"` // сообщить о снижении стоимости на столько %
+	LastTotal      float64         `json:"lastTotal"`                // стоимость при последнем пересчёте
+	BaselineTotal  float64         `json:"baselineTotal"`            // от неё считается снижение
+	BaselinePrices map[int]float64 `json:"-"`                        // цены позиций на момент BaselineTotal
+	CreatedAt      time.Time       `json:"createdAt"`
+	UpdatedAt      time.Time       `json:"updatedAt"`
+}
+
+// ConfigurationPriceItem — позиция сборки с минимальной ценой (0 — нет предложений)
+type ConfigurationPriceItem struct {
+	ComponentID int     `json:"componentId"`
+	Name        string  `json:"name"`
+	Category    string  `json:"category"`
+	Quantity    int     `json:"quantity"`
+	Price       float64 `json:"price"`
+}
+
+// Причины уведомления по сборке
+const (
+	ConfigAlertBudgetReached  = "budget_reached"  // стоимость опустилась до бюджета
+	ConfigAlertBudgetExceeded = "budget_exceeded" // стоимость снова выше бюджета
+	ConfigAlertPriceDrop      = "price_drop"      // снижение на MinDropPercent
+)
+
+// ConfigurationPriceChange — как изменилась цена позиции сборки
+type ConfigurationPriceChange struct {
+	ComponentID int     `json:"componentId"`
+	Name        string  `json:"name"`
+	Category    string  `json:"category"`
+	Quantity    int     `json:"quantity"`
+	OldPrice    float64 `json:"oldPrice"`
+	NewPrice    float64 `json:"newPrice"`
+}
+
+// ConfigurationAlert — детали уведомления по сборке
+type ConfigurationAlert struct {
+	ConfigID   int                        `json:"configId"`
+	ConfigName string                     `json:"configName"`
+	Reasons    []string                   `json:"reasons"`
+	Budget     *float64                   `json:"budget,omitempty"`
+	OldTotal   float64                    `json:"oldTotal"`
+	NewTotal   float64                    `json:"newTotal"`
+	Changes    []ConfigurationPriceChange `json:"changes"`
 }
 
 // NotificationResponse — структура, отдаваемая в теле GET /notifications
 type NotificationResponse struct {
-	ID                uuid.UUID `json:"id"`
-	ComponentID       string    `json:"componentId"`
-	ComponentName     string    `json:"componentName"`
-	ComponentCategory string    `json:"componentCategory"`
-	ShopID            int64     `json:"shopId"`
-	OldPrice          float64   `json:"oldPrice"`
-	NewPrice          float64   `json:"newPrice"`
-	IsRead            bool      `json:"isRead"`
-	CreatedAt         time.Time `json:"createdAt"`
+	ID                uuid.UUID       `json:"id"`
+	ComponentID       string          `json:"componentId"`
+	ComponentName     string          `json:"componentName"`
+	ComponentCategory string          `json:"componentCategory"`
+	Type              string          `json:"type"` // price_changed | back_in_stock | out_of_stock | configuration_price
+	ShopID            int64           `json:"shopId"`
+	ShopName          string          `json:"shopName,omitempty"`
+	OldPrice          float64         `json:"oldPrice"`
+	NewPrice          float64         `json:"newPrice"`
+	Availability      string          `json:"availability,omitempty"`
+	ConfigID          *int            `json:"configId,omitempty"`
+	Details           json.RawMessage `json:"details,omitempty"` // для configuration_price — ConfigurationAlert
+	IsRead            bool            `json:"isRead"`
+	CreatedAt         time.Time       `json:"createdAt"`
+}
+
+// Режимы email-уведомлений
+const (
+	EmailModeOff     = "off"
+	EmailModeInstant = "instant" // письмо на каждое уведомление
+	EmailModeDaily   = "daily"   // сводка раз в сутки
+	EmailModeWeekly  = "weekly"  // сводка раз в неделю
+)
+
+// EmailSettings — настройки email-канала пользователя
+type EmailSettings struct {
+	UserID       uuid.UUID `json:"-"`
+	Mode         string    `json:"mode"`
+	LastDigestAt time.Time `json:"lastDigestAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+// EmailBatch — неотправленные уведомления пользователя, которым пора уйти
+// письмом: по одному (instant) или сводкой с момента прошлой (daily, weekly)
+type EmailBatch struct {
+	UserID       uuid.UUID
+	Email        string
+	Name         string
+	Mode         string
+	LastDigestAt time.Time
+	Items        []NotificationResponse
 }
 
 // PagedNotifications — обёртка с метаданными пагинации
@@ -140,6 +516,168 @@ type PagedNotifications struct {
 
 // NamedBuild — сборка с названием
 type NamedBuild struct {
-	Name       string      `json:"name"`
-	Components []Component `json:"components"`
+	Name       string            `json:"name"`
+	Score      int               `json:"score"`      // ранг сборки для сценария (rankCached)
+	TotalPrice int               `json:"totalPrice"` // сумма минимальных цен с учётом количества
+	Components []PricedComponent `json:"components"`
+}
+
+// PricedComponent — компонент сборки с ценой и магазином, где он дешевле всего
+type PricedComponent struct {
+	Component
+	Quantity  int    `json:"quantity"`
+	UnitPrice int    `json:"unitPrice"`
+	Price     int    `json:"price"` // UnitPrice × Quantity
+	ShopID    int64  `json:"shopId,omitempty"`
+	ShopCode  string `json:"shopCode,omitempty"`
+	ShopName  string `json:"shopName,omitempty"`
+	URL       string `json:"url,omitempty"`
+}
+
+// OptimizedBuild — лучшая по сценарию сборка в рамках бюджета
+type OptimizedBuild struct {
+	UseCase    string  `json:"usecase"`
+	Budget     int     `json:"budget"`
+	Name       string  `json:"name"`
+	Score      int     `json:"score"` // ранг сборки (rankCached)
+	Value      float64 `json:"value"` // целевая функция оптимизатора, 0..1
+	TotalPrice int     `json:"totalPrice"`
+	// Exact — перебор завершён полностью; false — остановлен по лимиту,
+	// сборка лучшая из просмотренных
+	Exact      bool              `json:"exact"`
+	Components []PricedComponent `json:"components"`
+}
+
+// ConfigurationShare — публичная ссылка на сборку (только чтение)
+type ConfigurationShare struct {
+	ID        int        `json:"id"`
+	ConfigID  int        `json:"configId"`
+	Slug      string     `json:"slug"`
+	CreatedBy uuid.UUID  `json:"-"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"` // nil — бессрочно
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// Active — ссылкой можно пользоваться: не отозвана и не истекла
+func (s ConfigurationShare) Active(now time.Time) bool {
+	return s.RevokedAt == nil && (s.ExpiresAt == nil || now.Before(*s.ExpiresAt))
+}
+
+// SharedConfiguration — сборка, открытая по ссылке: с текущими ценами
+// и совместимостью по действующим правилам
+type SharedConfiguration struct {
+	Slug          string              `json:"slug"`
+	Name          string              `json:"name"`
+	Components    []PricedComponent   `json:"components"`
+	TotalPrice    int                 `json:"totalPrice"`
+	Compatibility CompatibilityReport `json:"compatibility"`
+	ExpiresAt     *time.Time          `json:"expiresAt,omitempty"`
+	UpdatedAt     time.Time           `json:"updatedAt"`
+}
+
+// ConfigurationRevision — неизменяемый снимок сборки после создания или правки
+type ConfigurationRevision struct {
+	ConfigID   int            `json:"configId"`
+	Revision   int            `json:"revision"` // 1, 2, ... в пределах сборки
+	Name       string         `json:"name"`
+	Components []ComponentRef `json:"components"`
+	TotalPrice int            `json:"totalPrice"` // по текущим минимальным ценам
+	CreatedBy  *uuid.UUID     `json:"-"`
+	CreatedAt  time.Time      `json:"createdAt"`
+}
+
+// ComponentSwap — в категории один компонент заменён другим
+type ComponentSwap struct {
+	From ComponentRef `json:"from"`
+	To   ComponentRef `json:"to"`
+}
+
+// QuantityChange — у компонента изменилось количество
+type QuantityChange struct {
+	Component ComponentRef `json:"component"`
+	From      int          `json:"from"`
+	To        int          `json:"to"`
+}
+
+// CategoryDiff — изменения в одной категории между ревизиями
+type CategoryDiff struct {
+	Category        string           `json:"category"`
+	Added           []ComponentRef   `json:"added,omitempty"`
+	Removed         []ComponentRef   `json:"removed,omitempty"`
+	Swapped         []ComponentSwap  `json:"swapped,omitempty"`
+	QuantityChanged []QuantityChange `json:"quantityChanged,omitempty"`
+}
+
+// RevisionDiff — разница между двумя ревизиями сборки. Цены и совместимость
+// считаются по текущим данным для обеих ревизий.
+type RevisionDiff struct {
+	ConfigID        int            `json:"configId"`
+	From            int            `json:"from"`
+	To              int            `json:"to"`
+	NameFrom        string         `json:"nameFrom"`
+	NameTo          string         `json:"nameTo"`
+	Categories      []CategoryDiff `json:"categories"`
+	TotalPriceFrom  int            `json:"totalPriceFrom"`
+	TotalPriceTo    int            `json:"totalPriceTo"`
+	TotalPriceDelta int            `json:"totalPriceDelta"`
+	CompatibleFrom  bool           `json:"compatibleFrom"`
+	CompatibleTo    bool           `json:"compatibleTo"`
+	ErrorsFrom      int            `json:"errorsFrom"` // issues уровня error
+	ErrorsTo        int            `json:"errorsTo"`
+}
+
+// Статусы предложений характеристик
+const (
+	ProposalPending  = "pending"
+	ProposalApproved = "approved"
+	ProposalRejected = "rejected"
+)
+
+// SpecRow — строка характеристик как на странице магазина
+type SpecRow struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// SpecChange — значение из магазина, которого нет в каталоге или которое
+// отличается от каталожного
+type SpecChange struct {
+	Key      string      `json:"key"`
+	Current  interface{} `json:"current,omitempty"`
+	Proposed interface{} `json:"proposed"`
+}
+
+// SpecConflict — другой магазин предлагает для того же ключа другое значение
+type SpecConflict struct {
+	Key        string      `json:"key"`
+	ShopCode   string      `json:"shopCode"`
+	Value      interface{} `json:"value"`
+	ProposalID int64       `json:"proposalId"`
+}
+
+// SpecProposal — характеристики со страницы магазина, ждущие проверки.
+// Без ComponentID — товар не сопоставлен с каталогом, предлагается новый компонент.
+type SpecProposal struct {
+	ID          int64          `json:"id"`
+	ComponentID *int           `json:"componentId"`
+	ShopID      int64          `json:"shopId"`
+	ShopCode    string         `json:"shopCode"`
+	URL         string         `json:"url"`
+	Category    string         `json:"category"`
+	Name        string         `json:"name"`
+	Changes     []SpecChange   `json:"changes"`
+	Conflicts   []SpecConflict `json:"conflicts"`
+	Unmapped    []SpecRow      `json:"unmapped,omitempty"` // строки без правила сопоставления
+	Status      string         `json:"status"`
+	CreatedAt   time.Time      `json:"createdAt"`
+	UpdatedAt   time.Time      `json:"updatedAt"`
+	ReviewedAt  *time.Time     `json:"reviewedAt,omitempty"`
+}
+
+// ComponentImage — фото товара со страницы магазина
+type ComponentImage struct {
+	URL      string `json:"url"`
+	ShopCode string `json:"shopCode"`
+	IsMain   bool   `json:"isMain"`
 }
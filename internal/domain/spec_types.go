@@ -0,0 +1,142 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Типизированные specs по категориям. Числа — в единицах схемы SpecTypes;
+// 0 или пустое значение — характеристика не указана или не разобрана.
+
+type CPUSpecs struct {
+	Socket       string `json:"socket"`
+	TDP          int    `json:"tdp"`
+	Cores        int    `json:"cores"`
+	Threads      int    `json:"threads"`
+	PowerDraw    int    `json:"power_draw"`
+	CoolerHeight int    `json:"cooler_height"`
+}
+
+type MotherboardSpecs struct {
+	Socket      string `json:"socket"`
+	RAMType     string `json:"ram_type"`
+	FormFactor  string `json:"form_factor"`
+	MaxMemoryGB int    `json:"max_memory_gb"`
+	MemorySlots int    `json:"memory_slots"`
+	M2Slots     int    `json:"m2_slots"`
+	SATAPorts   int    `json:"sata_ports"`
+	PCIeVersion string `json:"pcie_version"`
+}
+
+type RAMSpecs struct {
+	RAMType   string  `json:"ram_type"`
+	Frequency int     `json:"frequency"`
+	Capacity  int     `json:"capacity"`
+	Modules   int     `json:"modules"`
+	Voltage   float64 `json:"voltage"`
+}
+
+type GPUSpecs struct {
+	LengthMM  int    `json:"length_mm"`
+	HeightMM  int    `json:"height_mm"`
+	PowerDraw int    `json:"power_draw"`
+	MemoryGB  int    `json:"memory_gb"`
+	Interface string `json:"interface"`
+}
+
+type PSUSpecs struct {
+	Power      int    `json:"power"`
+	FormFactor string `json:"form_factor"`
+	Efficiency string `json:"efficiency"`
+	Modular    bool   `json:"modular"`
+	LengthMM   int    `json:"length_mm"`
+}
+
+type CaseSpecs struct {
+	FormFactor                string   `json:"form_factor"`
+	MaxMotherboardFormFactors []string `json:"max_motherboard_form_factors"`
+	GPUMaxLength              int      `json:"gpu_max_length"`
+	CoolerMaxHeight           int      `json:"cooler_max_height"`
+	MaxPSULength              int      `json:"max_psu_length"`
+	PSUFormFactor             string   `json:"psu_form_factor"`
+	DriveBays25               int      `json:"drive_bays_2_5"`
+	DriveBays35               int      `json:"drive_bays_3_5"`
+	FanMounts                 int      `json:"fan_mounts"`
+}
+
+type CoolerSpecs struct {
+	Socket   []string `json:"socket"`
+	HeightMM int      `json:"height_mm"`
+	MaxTDP   int      `json:"max_tdp"`
+}
+
+type SSDSpecs struct {
+	Interface     string `json:"interface"`
+	FormFactor    string `json:"form_factor"`
+	CapacityGB    int    `json:"capacity_gb"`
+	MaxThroughput int    `json:"max_throughput"`
+	M2Key         string `json:"m2_key"`
+	PowerDraw     int    `json:"power_draw"`
+}
+
+type HDDSpecs struct {
+	Interface  string `json:"interface"`
+	FormFactor string `json:"form_factor"`
+	CapacityGB int    `json:"capacity_gb"`
+	RPM        int    `json:"rpm"`
+	PowerDraw  int    `json:"power_draw"`
+}
+
+type CaseFanSpecs struct {
+	SizeMM    int `json:"size_mm"`
+	RPM       int `json:"rpm"`
+	PowerDraw int `json:"power_draw"`
+}
+
+// specStructs — конструктор типизированных specs для категории
+var specStructs = map[ComponentCategory]func() interface{}{
+	CategoryCPU:         func() interface{} { return &CPUSpecs{} },
+	CategoryMotherboard: func() interface{} { return &MotherboardSpecs{} },
+	CategoryRAM:         func() interface{} { return &RAMSpecs{} },
+	CategoryGPU:         func() interface{} { return &GPUSpecs{} },
+	CategoryPSU:         func() interface{} { return &PSUSpecs{} },
+	CategoryCase:        func() interface{} { return &CaseSpecs{} },
+	CategoryCooler:      func() interface{} { return &CoolerSpecs{} },
+	CategorySSD:         func() interface{} { return &SSDSpecs{} },
+	CategoryHDD:         func() interface{} { return &HDDSpecs{} },
+	CategoryCaseFan:     func() interface{} { return &CaseFanSpecs{} },
+}
+
+// DecodeSpecs разбирает specs в типизированную структуру категории
+// (*CPUSpecs, *GPUSpecs, ...). Для категорий без схемы возвращает
+// map[string]interface{}. Ошибки не мешают разбору остальных полей.
+func DecodeSpecs(category ComponentCategory, raw json.RawMessage) (interface{}, []SpecError) {
+	newSpecs, ok := specStructs[category]
+	if !ok {
+		specs, errs := ParseSpecs(category, raw)
+		return specs, errs
+	}
+	out := newSpecs()
+	errs := DecodeSpecsInto(category, raw, out)
+	return out, errs
+}
+
+// DecodeSpecsInto разбирает specs в переданную структуру: значения
+// нормализуются (NormalizeSpecs), ошибочные поля остаются нулевыми
+func DecodeSpecsInto(category ComponentCategory, raw json.RawMessage, out interface{}) []SpecError {
+	specs, errs := ParseSpecs(category, raw)
+	if specs == nil {
+		return errs
+	}
+	for _, e := range errs {
+		delete(specs, e.Key)
+	}
+	data, err := json.Marshal(specs)
+	if err == nil {
+		err = json.Unmarshal(data, out)
+	}
+	if err != nil {
+		errs = append(errs, SpecError{Key: "specs", Message: fmt.Sprintf("cannot decode: %v", err)})
+	}
+	return errs
+}
@@ -0,0 +1,351 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SpecKind — тип значения характеристики в specs
+type SpecKind string
+
+const (
+	SpecInt     SpecKind = "int"     // целое число (такие ключи попадают в INT generated-колонки)
+	SpecNumber  SpecKind = "number"  // любое число
+	SpecString  SpecKind = "string"  // строка
+	SpecBool    SpecKind = "bool"    // true / false
+	SpecStrings SpecKind = "strings" // список строк
+)
+
+// SpecType — ожидаемый тип характеристики, единица измерения для чисел
+// и допустимые значения для строк
+type SpecType struct {
+	Kind SpecKind
+	Unit string   // каноническая единица: число в specs хранится в ней
+	Enum []string // пусто — любое значение; сравнение без учёта регистра
+}
+
+// Допустимые значения перечислимых характеристик
+var (
+	SocketValues          = []string{"AM4", "AM5", "LGA1200", "LGA1700", "LGA1851"}
+	RAMTypeValues         = []string{"DDR4", "DDR5"}
+	BoardFormFactorValues = []string{"E-ATX", "ATX", "Micro-ATX", "Mini-ITX"}
+	PSUFormFactorValues   = []string{"ATX", "SFX", "SFX-L"}
+	PCIeValues            = []string{"PCIe 3.0", "PCIe 4.0", "PCIe 5.0"}
+	SSDFormFactorValues   = []string{"M.2", "2.5"}
+	HDDFormFactorValues   = []string{"2.5", "3.5"}
+)
+
+// Единицы измерения характеристик
+const (
+	UnitWatt = "W"
+	UnitMM   = "mm"
+	UnitGB   = "GB"
+	UnitMHz  = "MHz"
+	UnitMBps = "MB/s"
+	UnitVolt = "V"
+	UnitRPM  = "rpm"
+)
+
+// unitScales — множители для единиц, которые встречаются в specs,
+// относительно канонической единицы характеристики
+var unitScales = map[string]map[string]float64{
+	UnitWatt: {"w": 1, "вт": 1, "kw": 1000},
+	UnitMM:   {"mm": 1, "мм": 1, "cm": 10, "см": 10},
+	UnitGB:   {"gb": 1, "гб": 1, "tb": 1000, "тб": 1000},
+	UnitMHz:  {"mhz": 1, "мгц": 1, "mt/s": 1, "ghz": 1000, "ггц": 1000},
+	UnitMBps: {"mb/s": 1, "мб/с": 1, "gb/s": 1000, "гб/с": 1000},
+	UnitVolt: {"v": 1, "в": 1, "mv": 0.001},
+	UnitRPM:  {"rpm": 1, "об/мин": 1},
+}
+
+// enumAliases — распространённые написания, которые не сводятся
+// к допустимому значению сменой регистра
+var enumAliases = map[string]string{
+	"MATX": "Micro-ATX",
+	"UATX": "Micro-ATX",
+	"ITX":  "Mini-ITX",
+	"M2":   "M.2",
+}
+
+// SpecTypes — схема specs по категориям: типы, единицы и допустимые
+// значения известных характеристик. Ключи, которых здесь нет, не проверяются.
+var SpecTypes = map[ComponentCategory]map[string]SpecType{
+	CategoryCPU: {
+		"socket":        {Kind: SpecString, Enum: SocketValues},
+		"tdp":           {Kind: SpecInt, Unit: UnitWatt},
+		"cores":         {Kind: SpecInt},
+		"threads":       {Kind: SpecInt},
+		"power_draw":    {Kind: SpecInt, Unit: UnitWatt},
+		"cooler_height": {Kind: SpecInt, Unit: UnitMM},
+	},
+	CategoryMotherboard: {
+		"socket":        {Kind: SpecString, Enum: SocketValues},
+		"ram_type":      {Kind: SpecString, Enum: RAMTypeValues},
+		"form_factor":   {Kind: SpecString, Enum: BoardFormFactorValues},
+		"max_memory_gb": {Kind: SpecInt, Unit: UnitGB},
+		"memory_slots":  {Kind: SpecInt},
+		"m2_slots":      {Kind: SpecInt},
+		"sata_ports":    {Kind: SpecInt},
+		"pcie_version":  {Kind: SpecString, Enum: PCIeValues},
+	},
+	CategoryRAM: {
+		"ram_type":  {Kind: SpecString, Enum: RAMTypeValues},
+		"frequency": {Kind: SpecInt, Unit: UnitMHz},
+		"capacity":  {Kind: SpecInt, Unit: UnitGB},
+		"modules":   {Kind: SpecInt},
+		"voltage":   {Kind: SpecNumber, Unit: UnitVolt},
+	},
+	CategoryGPU: {
+		"length_mm":  {Kind: SpecInt, Unit: UnitMM},
+		"height_mm":  {Kind: SpecInt, Unit: UnitMM},
+		"power_draw": {Kind: SpecInt, Unit: UnitWatt},
+		"memory_gb":  {Kind: SpecInt, Unit: UnitGB},
+		"interface":  {Kind: SpecString, Enum: PCIeValues},
+	},
+	CategoryPSU: {
+		"power":       {Kind: SpecInt, Unit: UnitWatt},
+		"form_factor": {Kind: SpecString, Enum: PSUFormFactorValues},
+		"efficiency":  {Kind: SpecString},
+		"modular":     {Kind: SpecBool},
+		"length_mm":   {Kind: SpecInt, Unit: UnitMM},
+	},
+	CategoryCase: {
+		"form_factor":                  {Kind: SpecString, Enum: BoardFormFactorValues},
+		"max_motherboard_form_factors": {Kind: SpecStrings, Enum: BoardFormFactorValues},
+		"gpu_max_length":               {Kind: SpecInt, Unit: UnitMM},
+		"cooler_max_height":            {Kind: SpecInt, Unit: UnitMM},
+		"max_psu_length":               {Kind: SpecInt, Unit: UnitMM},
+		"psu_form_factor":              {Kind: SpecString, Enum: PSUFormFactorValues},
+		"drive_bays_2_5":               {Kind: SpecInt},
+		"drive_bays_3_5":               {Kind: SpecInt},
+		"fan_mounts":                   {Kind: SpecInt},
+	},
+	CategoryCooler: {
+		"socket":    {Kind: SpecStrings, Enum: SocketValues},
+		"height_mm": {Kind: SpecInt, Unit: UnitMM},
+		"max_tdp":   {Kind: SpecInt, Unit: UnitWatt},
+	},
+	CategorySSD: {
+		"interface":      {Kind: SpecString},
+		"form_factor":    {Kind: SpecString, Enum: SSDFormFactorValues},
+		"capacity_gb":    {Kind: SpecInt, Unit: UnitGB},
+		"max_throughput": {Kind: SpecInt, Unit: UnitMBps},
+		"m2_key":         {Kind: SpecString},
+		"power_draw":     {Kind: SpecInt, Unit: UnitWatt},
+	},
+	CategoryHDD: {
+		"interface":   {Kind: SpecString},
+		"form_factor": {Kind: SpecString, Enum: HDDFormFactorValues},
+		"capacity_gb": {Kind: SpecInt, Unit: UnitGB},
+		"rpm":         {Kind: SpecInt, Unit: UnitRPM},
+		"power_draw":  {Kind: SpecInt, Unit: UnitWatt},
+	},
+	CategoryCaseFan: {
+		"size_mm":    {Kind: SpecInt, Unit: UnitMM},
+		"rpm":        {Kind: SpecInt, Unit: UnitRPM},
+		"power_draw": {Kind: SpecInt, Unit: UnitWatt},
+	},
+}
+
+// SpecError — ошибка в одной характеристике компонента
+type SpecError struct {
+	Key     string `json:"key"`
+	Message string `json:"message"`
+}
+
+func (e SpecError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Key, e.Message)
+}
+
+// ParseSpecs разбирает specs компонента, приводит их к каноническому виду
+// (NormalizeSpecs) и проверяет обязательные поля (ValidateSpecs)
+func ParseSpecs(category ComponentCategory, raw json.RawMessage) (map[string]interface{}, []SpecError) {
+	var specs map[string]interface{}
+	if err := json.Unmarshal(raw, &specs); err != nil || specs == nil {
+		return nil, []SpecError{{Key: "specs", Message: "must be a JSON object"}}
+	}
+	norm, errs := NormalizeSpecs(category, specs)
+	for _, key := range ValidateSpecs(category, norm) {
+		errs = append(errs, SpecError{Key: key, Message: "is required"})
+	}
+	sortSpecErrors(errs)
+	return norm, errs
+}
+
+// CheckSpecs проверяет specs компонента: обязательные поля (ValidateSpecs),
+// типы значений и допустимые значения перечислений
+func CheckSpecs(category ComponentCategory, specs map[string]interface{}) []SpecError {
+	_, errs := NormalizeSpecs(category, specs)
+	for _, key := range ValidateSpecs(category, specs) {
+		errs = append(errs, SpecError{Key: key, Message: "is required"})
+	}
+	sortSpecErrors(errs)
+	return errs
+}
+
+// NormalizeSpecs приводит известные характеристики к каноническому виду:
+// числа из строк ("65", "65 W", "1 TB") — к числу в единице схемы,
+// перечисления — к написанию из списка допустимых значений ("am4" → "AM4"),
+// списки строк — к JSON-массиву. Значения, которые привести не удалось,
+// остаются как были и попадают в ошибки. Исходная map не меняется.
+func NormalizeSpecs(category ComponentCategory, specs map[string]interface{}) (map[string]interface{}, []SpecError) {
+	out := make(map[string]interface{}, len(specs))
+	var errs []SpecError
+	types := SpecTypes[category]
+	for key, val := range specs {
+		t, ok := types[key]
+		if !ok {
+			out[key] = val
+			continue
+		}
+		norm, msg := normalizeSpecValue(t, val)
+		if msg != "" {
+			errs = append(errs, SpecError{Key: key, Message: msg})
+			out[key] = val
+			continue
+		}
+		out[key] = norm
+	}
+	sortSpecErrors(errs)
+	return out, errs
+}
+
+func sortSpecErrors(errs []SpecError) {
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Key < errs[j].Key })
+}
+
+func normalizeSpecValue(t SpecType, val interface{}) (interface{}, string) {
+	switch t.Kind {
+	case SpecInt, SpecNumber:
+		f, msg := specNumber(t.Unit, val)
+		if msg != "" {
+			return nil, msg
+		}
+		if f < 0 {
+			return nil, "must not be negative"
+		}
+		if t.Kind == SpecInt {
+			// 1.5 TB → 1500 GB — целое; погрешность float после умножения отбрасываем
+			r := math.Round(f)
+			if math.Abs(f-r) > 1e-9 {
+				return nil, "must be an integer"
+			}
+			f = r
+		}
+		return f, ""
+	case SpecBool:
+		switch v := val.(type) {
+		case bool:
+			return v, ""
+		case string:
+			switch strings.ToLower(strings.TrimSpace(v)) {
+			case "true", "yes", "да", "1":
+				return true, ""
+			case "false", "no", "нет", "0":
+				return false, ""
+			}
+		}
+		return nil, "must be true or false"
+	case SpecString:
+		s, ok := val.(string)
+		if !ok {
+			return nil, "must be a string"
+		}
+		return canonicalEnum(t.Enum, s)
+	case SpecStrings:
+		var items []interface{}
+		switch v := val.(type) {
+		case string:
+			for _, part := range strings.Split(v, ",") {
+				items = append(items, part)
+			}
+		case []interface{}:
+			items = v
+		default:
+			return nil, "must be a list of strings"
+		}
+		if len(items) == 0 {
+			return nil, "must not be empty"
+		}
+		out := make([]interface{}, 0, len(items))
+		for _, item := range items {
+			s, ok := item.(string)
+			if !ok {
+				return nil, "must be a list of strings"
+			}
+			norm, msg := canonicalEnum(t.Enum, s)
+			if msg != "" {
+				return nil, msg
+			}
+			out = append(out, norm)
+		}
+		return out, ""
+	}
+	return val, ""
+}
+
+var numberWithUnitRe = regexp.MustCompile(`^([-+]?\d+(?:[.,]\d+)?)\s*(.*)$`)
+
+// specNumber разбирает число или строку вида "65", "65W", "1.5 TB"
+// и переводит значение в единицу unit
+func specNumber(unit string, val interface{}) (float64, string) {
+	switch v := val.(type) {
+	case float64:
+		return v, ""
+	case string:
+		m := numberWithUnitRe.FindStringSubmatch(strings.TrimSpace(v))
+		if m == nil {
+			return 0, "must be a number"
+		}
+		f, err := strconv.ParseFloat(strings.Replace(m[1], ",", ".", 1), 64)
+		if err != nil {
+			return 0, "must be a number"
+		}
+		suffix := strings.ToLower(strings.TrimSpace(m[2]))
+		if suffix == "" {
+			return f, ""
+		}
+		if scale, ok := unitScales[unit][suffix]; ok {
+			return f * scale, ""
+		}
+		if unit == "" {
+			return 0, "must be a number"
+		}
+		return 0, fmt.Sprintf("unknown unit %q, expected %s", m[2], unit)
+	}
+	return 0, "must be a number"
+}
+
+// canonicalEnum возвращает допустимое значение в написании из enum
+func canonicalEnum(enum []string, s string) (string, string) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", "must not be empty"
+	}
+	if len(enum) == 0 {
+		return s, ""
+	}
+	key := enumKey(s)
+	if alias, ok := enumAliases[key]; ok {
+		key = enumKey(alias)
+	}
+	for _, v := range enum {
+		if enumKey(v) == key {
+			return v, ""
+		}
+	}
+	return "", fmt.Sprintf("%q is not one of %s", s, strings.Join(enum, ", "))
+}
+
+var enumKeyReplacer = strings.NewReplacer(" ", "", "-", "", "_", "", `"`, "", "″", "")
+
+// enumKey — форма для сравнения: без регистра, пробелов, дефисов и кавычек
+// ("micro atx" = "Micro-ATX", `3.5"` = "3.5")
+func enumKey(s string) string {
+	return strings.ToUpper(enumKeyReplacer.Replace(s))
+}
@@ -0,0 +1,68 @@
+package email
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ErrBadToken — ссылка отписки подделана или повреждена
+var ErrBadToken = errors.New("invalid unsubscribe token")
+
+// Links строит ссылки отписки для писем. Токен — "<userID>:<componentID>"
+// с HMAC-подписью; пустой componentID — отключить письма совсем. Токены не
+// истекают: ссылка из старого письма должна работать.
+type Links struct {
+	secret  []byte
+	baseURL string // публичный адрес gateway, например http://localhost:8080
+}
+
+func NewLinks(secret, baseURL string) *Links {
+	return &Links{secret: []byte(secret), baseURL: strings.TrimRight(baseURL, "/")}
+}
+
+// Unsubscribe — ссылка отписки от компонента
+func (l *Links) Unsubscribe(userID uuid.UUID, componentID string) string {
+	return l.baseURL + "/unsubscribe?token=" + url.QueryEscape(l.token(userID, componentID))
+}
+
+// DisableEmails — ссылка отключения писем
+func (l *Links) DisableEmails(userID uuid.UUID) string {
+	return l.Unsubscribe(userID, "")
+}
+
+func (l *Links) token(userID uuid.UUID, componentID string) string {
+	payload := userID.String() + ":" + componentID
+	return base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." +
+		base64.RawURLEncoding.EncodeToString(l.sign(payload))
+}
+
+func (l *Links) sign(payload string) []byte {
+	mac := hmac.New(sha256.New, l.secret)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+// Parse проверяет подпись токена и возвращает пользователя и компонент
+func (l *Links) Parse(token string) (uuid.UUID, string, error) {
+	rawPayload, rawSig, ok := strings.Cut(token, ".")
+	if !ok {
+		return uuid.Nil, "", ErrBadToken
+	}
+	payload, err1 := base64.RawURLEncoding.DecodeString(rawPayload)
+	sig, err2 := base64.RawURLEncoding.DecodeString(rawSig)
+	if err1 != nil || err2 != nil || !hmac.Equal(sig, l.sign(string(payload))) {
+		return uuid.Nil, "", ErrBadToken
+	}
+	user, componentID, _ := strings.Cut(string(payload), ":")
+	userID, err := uuid.Parse(user)
+	if err != nil {
+		return uuid.Nil, "", ErrBadToken
+	}
+	return userID, componentID, nil
+}
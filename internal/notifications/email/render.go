@@ -0,0 +1,220 @@
+package email
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"math"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+
+	"StartupPCConfigurator/internal/domain"
+)
+
+// ErrNothingToSend — после сведения изменений писать не о чем
+var ErrNothingToSend = errors.New("email: nothing to send")
+
+//go:embed templates/*
+var templatesFS embed.FS
+
+var (
+	textTmpl = texttemplate.Must(texttemplate.ParseFS(templatesFS, "templates/notification.txt"))
+	htmlTmpl = htmltemplate.Must(htmltemplate.ParseFS(templatesFS, "templates/notification.html"))
+)
+
+// view — данные шаблонов; строки собираются здесь, шаблоны только раскладывают их
+type view struct {
+	Subject    string
+	Name       string
+	Intro      string
+	Items      []viewItem
+	Schedule   string
+	DisableURL string
+}
+
+type viewItem struct {
+	Title          string
+	Line           string
+	Details        []string
+	UnsubscribeURL string
+}
+
+// Render собирает письмо по пачке уведомлений: одно уведомление в режиме
+// instant или сводку в режимах daily/weekly
+func Render(b domain.EmailBatch, links *Links) (Message, error) {
+	items := b.Items
+	v := view{Name: b.Name, DisableURL: links.DisableEmails(b.UserID)}
+	switch b.Mode {
+	case domain.EmailModeInstant:
+		v.Schedule = "сразу после изменений"
+	case domain.EmailModeDaily:
+		v.Schedule = "раз в день"
+	case domain.EmailModeWeekly:
+		v.Schedule = "раз в неделю"
+	}
+
+	if b.Mode == domain.EmailModeInstant && len(items) == 1 {
+		v.Subject = subject(items[0])
+		v.Intro = "Новое уведомление по вашим подпискам:"
+	} else {
+		items = collapsePriceChanges(items)
+		period := "день"
+		if b.Mode == domain.EmailModeWeekly {
+			period = "неделю"
+		}
+		v.Subject = fmt.Sprintf("Сводка цен за %s: изменений — %d", period, len(items))
+		v.Intro = fmt.Sprintf("Что изменилось с %s:", b.LastDigestAt.Format("02.01.2006 15:04"))
+	}
+	if len(items) == 0 {
+		return Message{}, ErrNothingToSend
+	}
+	for _, n := range items {
+		v.Items = append(v.Items, describe(n, b, links))
+	}
+
+	var text, html bytes.Buffer
+	if err := textTmpl.Execute(&text, v); err != nil {
+		return Message{}, err
+	}
+	if err := htmlTmpl.Execute(&html, v); err != nil {
+		return Message{}, err
+	}
+	return Message{
+		To:      b.Email,
+		Subject: v.Subject,
+		Text:    text.String(),
+		HTML:    html.String(),
+		Headers: map[string]string{
+			// RFC 8058: почтовый клиент отписывает одной кнопкой (POST на ссылку)
+			"List-Unsubscribe":      "<" + v.DisableURL + ">",
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		},
+	}, nil
+}
+
+func subject(n domain.NotificationResponse) string {
+	switch n.Type {
+	case domain.NotificationBackInStock:
+		return n.ComponentName + " снова в наличии"
+	case domain.NotificationOutOfStock:
+		return n.ComponentName + " закончился"
+	case domain.NotificationConfigPrice:
+		if a, ok := configAlert(n); ok {
+			return fmt.Sprintf("Сборка «%s»: %s", a.ConfigName, formatPrice(a.NewTotal))
+		}
+		return "Изменилась стоимость сборки"
+	default:
+		return fmt.Sprintf("Цена на %s: %s", n.ComponentName, formatPrice(n.NewPrice))
+	}
+}
+
+func describe(n domain.NotificationResponse, b domain.EmailBatch, links *Links) viewItem {
+	it := viewItem{Title: n.ComponentName}
+	shop := ""
+	if n.ShopName != "" {
+		shop = " (" + n.ShopName + ")"
+	}
+	switch n.Type {
+	case domain.NotificationBackInStock:
+		it.Line = "Снова в наличии: " + formatPrice(n.NewPrice) + shop
+	case domain.NotificationOutOfStock:
+		it.Line = "Закончился" + shop
+	case domain.NotificationConfigPrice:
+		a, ok := configAlert(n)
+		if !ok {
+			it.Line = fmt.Sprintf("Стоимость сборки: %s → %s", formatPrice(n.OldPrice), formatPrice(n.NewPrice))
+			return it
+		}
+		it.Title = "Сборка «" + a.ConfigName + "»"
+		it.Line = fmt.Sprintf("Стоимость: %s → %s", formatPrice(a.OldTotal), formatPrice(a.NewTotal))
+		for _, r := range a.Reasons {
+			switch r {
+			case domain.ConfigAlertBudgetReached:
+				it.Line += " — уложилась в бюджет " + formatPrice(*a.Budget)
+			case domain.ConfigAlertBudgetExceeded:
+				it.Line += " — снова дороже бюджета " + formatPrice(*a.Budget)
+			}
+		}
+		for _, c := range a.Changes {
+			qty := ""
+			if c.Quantity > 1 {
+				qty = fmt.Sprintf(" ×%d", c.Quantity)
+			}
+			it.Details = append(it.Details, fmt.Sprintf("%s%s: %s → %s", c.Name, qty, formatPrice(c.OldPrice), formatPrice(c.NewPrice)))
+		}
+		// сборка — не подписка на компонент, отписка только общая
+		return it
+	default:
+		verb := "Цена выросла"
+		if n.NewPrice < n.OldPrice {
+			verb = "Цена снизилась"
+		}
+		it.Line = fmt.Sprintf("%s: %s → %s%s", verb, formatPrice(n.OldPrice), formatPrice(n.NewPrice), shop)
+	}
+	it.UnsubscribeURL = links.Unsubscribe(b.UserID, n.ComponentID)
+	return it
+}
+
+func configAlert(n domain.NotificationResponse) (domain.ConfigurationAlert, bool) {
+	var a domain.ConfigurationAlert
+	if len(n.Details) == 0 || json.Unmarshal(n.Details, &a) != nil {
+		return a, false
+	}
+	return a, true
+}
+
+// collapsePriceChanges сводит несколько изменений цены одного компонента в
+// одном магазине к одному: от первой старой цены к последней новой. Если
+// цена вернулась к исходной, изменение пропадает.
+func collapsePriceChanges(items []domain.NotificationResponse) []domain.NotificationResponse {
+	type key struct {
+		componentID string
+		shopID      int64
+	}
+	pos := map[key]int{}
+	out := make([]domain.NotificationResponse, 0, len(items))
+	for _, n := range items {
+		if n.Type != domain.NotificationPriceChanged && n.Type != "" {
+			out = append(out, n)
+			continue
+		}
+		k := key{n.ComponentID, n.ShopID}
+		if i, ok := pos[k]; ok {
+			out[i].NewPrice = n.NewPrice
+			out[i].CreatedAt = n.CreatedAt
+			continue
+		}
+		pos[k] = len(out)
+		out = append(out, n)
+	}
+	kept := out[:0]
+	for _, n := range out {
+		if (n.Type == domain.NotificationPriceChanged || n.Type == "") && n.OldPrice == n.NewPrice {
+			continue
+		}
+		kept = append(kept, n)
+	}
+	return kept
+}
+
+// formatPrice — «12 990 ₽»; копейки только если они есть
+func formatPrice(p float64) string {
+	p = math.Round(p*100) / 100
+	whole := int64(p)
+	s := strconv.FormatInt(whole, 10)
+	var b strings.Builder
+	for i, r := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteRune(' ')
+		}
+		b.WriteRune(r)
+	}
+	if frac := math.Round((p - float64(whole)) * 100); frac != 0 {
+		fmt.Fprintf(&b, ",%02d", int64(math.Abs(frac)))
+	}
+	return b.String() + " ₽"
+}
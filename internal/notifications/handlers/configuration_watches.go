@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"StartupPCConfigurator/internal/domain"
+	"StartupPCConfigurator/internal/notifications/usecase"
+)
+
+// POST /configuration-watches
+func (h *Handler) WatchConfiguration(c *gin.Context) {
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+	userID, ok := userIDVal.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID type"})
+		return
+	}
+	// configId и условия: budget, minDropPercent
+	var body domain.ConfigurationWatch
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	body.UserID = userID
+	w, err := h.uc.WatchConfiguration(c.Request.Context(), body)
+	switch {
+	case errors.Is(err, usecase.ErrInvalidWatch):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, domain.ErrConfigNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "configuration not found"})
+		return
+	case errors.Is(err, domain.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "not owner of configuration"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, w)
+}
+
+// GET /configuration-watches
+func (h *Handler) ListConfigurationWatches(c *gin.Context) {
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+	userID, ok := userIDVal.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID type"})
+		return
+	}
+	watches, err := h.uc.ListConfigurationWatches(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"watches": watches})
+}
+
+// DELETE /configuration-watches/:configId
+func (h *Handler) UnwatchConfiguration(c *gin.Context) {
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+	userID, ok := userIDVal.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID type"})
+		return
+	}
+	configID, err := strconv.Atoi(c.Param("configId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid configuration ID"})
+		return
+	}
+	if err := h.uc.UnwatchConfiguration(c.Request.Context(), userID, configID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
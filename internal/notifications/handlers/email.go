@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"StartupPCConfigurator/internal/domain"
+	"StartupPCConfigurator/internal/notifications/email"
+	"StartupPCConfigurator/internal/notifications/usecase"
+)
+
+// GET /notifications/email-settings
+func (h *Handler) GetEmailSettings(c *gin.Context) {
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+	userID, ok := userIDVal.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID type"})
+		return
+	}
+	s, err := h.uc.GetEmailSettings(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, s)
+}
+
+// PUT /notifications/email-settings
+func (h *Handler) UpdateEmailSettings(c *gin.Context) {
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+	userID, ok := userIDVal.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID type"})
+		return
+	}
+	var body struct {
+		Mode string `json:"mode" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	s, err := h.uc.UpdateEmailSettings(c.Request.Context(), userID, body.Mode)
+	switch {
+	case errors.Is(err, usecase.ErrInvalidEmailMode):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, s)
+}
+
+// UnsubscribeLinkHandler обслуживает ссылки отписки из писем: без JWT,
+// пользователь и компонент берутся из подписанного токена
+type UnsubscribeLinkHandler struct {
+	uc    usecase.NotificationUseCase
+	links *email.Links
+}
+
+func NewUnsubscribeLinkHandler(uc usecase.NotificationUseCase, links *email.Links) *UnsubscribeLinkHandler {
+	return &UnsubscribeLinkHandler{uc: uc, links: links}
+}
+
+var unsubscribePage = template.Must(template.New("unsubscribe").Parse(`<!DOCTYPE html>
+<html lang="ru"><head><meta charset="utf-8"><title>Отписка</title></head>
+<body style="font-family:Arial,Helvetica,sans-serif;padding:40px;text-align:center;"><p>{{.Text}}</p>
+{{- if .Token}}
+<form method="post" action="?token={{.Token}}"><button type="submit" style="padding:8px 24px;">{{.Button}}</button></form>
+{{- end}}</body></html>`))
+
+type unsubscribeView struct {
+	Text   string
+	Token  string // непустой — показать кнопку подтверждения
+	Button string
+}
+
+// GET /unsubscribe?token=... — только страница с кнопкой подтверждения:
+// ссылки из писем открывают почтовые сканеры и предзагрузка, по GET ничего
+// не меняется
+func (h *UnsubscribeLinkHandler) Confirm(c *gin.Context) {
+	token := c.Query("token")
+	_, componentID, err := h.links.Parse(token)
+	if err != nil {
+		h.page(c, http.StatusBadRequest, unsubscribeView{Text: "Ссылка недействительна."})
+		return
+	}
+	v := unsubscribeView{
+		Text:   "Отписаться от уведомлений по этому товару?",
+		Token:  token,
+		Button: "Отписаться",
+	}
+	if componentID == "" {
+		v.Text, v.Button = "Отключить письма с уведомлениями? Уведомления в приложении продолжат приходить.", "Отключить письма"
+	}
+	h.page(c, http.StatusOK, v)
+}
+
+// POST /unsubscribe?token=... — кнопка со страницы подтверждения или
+// отписка одной кнопкой из почтового клиента (List-Unsubscribe-Post, RFC 8058)
+func (h *UnsubscribeLinkHandler) Unsubscribe(c *gin.Context) {
+	userID, componentID, err := h.links.Parse(c.Query("token"))
+	if err != nil {
+		h.reply(c, http.StatusBadRequest, "Ссылка недействительна.")
+		return
+	}
+	ctx := c.Request.Context()
+	if componentID == "" {
+		if _, err := h.uc.UpdateEmailSettings(ctx, userID, domain.EmailModeOff); err != nil {
+			h.reply(c, http.StatusInternalServerError, "Не удалось отключить письма, попробуйте позже.")
+			return
+		}
+		h.reply(c, http.StatusOK, "Письма отключены. Уведомления в приложении продолжат приходить.")
+		return
+	}
+	if err := h.uc.Unsubscribe(ctx, userID, componentID); err != nil {
+		h.reply(c, http.StatusInternalServerError, "Не удалось отписаться, попробуйте позже.")
+		return
+	}
+	h.reply(c, http.StatusOK, "Вы отписались от уведомлений по этому товару.")
+}
+
+// reply отвечает почтовому клиенту текстом, браузеру — страницей
+func (h *UnsubscribeLinkHandler) reply(c *gin.Context, status int, text string) {
+	if c.PostForm("List-Unsubscribe") == "One-Click" {
+		c.String(status, text)
+		return
+	}
+	h.page(c, status, unsubscribeView{Text: text})
+}
+
+func (h *UnsubscribeLinkHandler) page(c *gin.Context, status int, v unsubscribeView) {
+	c.Status(status)
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	unsubscribePage.Execute(c.Writer, v)
+}
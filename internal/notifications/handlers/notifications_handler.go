@@ -1,12 +1,14 @@
 package handlers
 
 import (
+	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"log"
 	"net/http"
 	"strconv"
 
+	"StartupPCConfigurator/internal/domain"
 	"StartupPCConfigurator/internal/notifications/usecase"
 )
 
@@ -106,11 +108,10 @@ func (h *Handler) Subscribe(c *gin.Context) {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
 		return
 	}
-	var body struct {
-		ComponentID string `json:"componentId"`
-	}
-	if err := c.BindJSON(&body); err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+	// componentId и условия: priceAlerts, targetPrice, minDropPercent, direction, stockAlert, shopId
+	body := domain.Subscription{PriceAlerts: true}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 	userID, ok := userIDVal.(uuid.UUID)
@@ -118,16 +119,46 @@ func (h *Handler) Subscribe(c *gin.Context) {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID type"})
 		return
 	}
-	if err := h.uc.Subscribe(c.Request.Context(), userID, body.ComponentID); err != nil {
+	body.UserID = userID
+	sub, err := h.uc.Subscribe(c.Request.Context(), body)
+	switch {
+	case errors.Is(err, usecase.ErrInvalidSubscription):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, domain.ErrShopNotFound):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "shop not found"})
+		return
+	case err != nil:
 		c.JSON(500, gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusCreated, gin.H{
-		"componentId": body.ComponentID,
-		"subscribed":  true,
+		"componentId":  sub.ComponentID,
+		"subscribed":   true,
+		"subscription": sub,
 	})
 }
 
+// GET /subscriptions
+func (h *Handler) ListSubscriptions(c *gin.Context) {
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+	userID, ok := userIDVal.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID type"})
+		return
+	}
+	subs, err := h.uc.ListSubscriptions(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"subscriptions": subs})
+}
+
 // DELETE /subscriptions/:componentId
 func (h *Handler) Unsubscribe(c *gin.Context) {
 	userIDVal, exists := c.Get("user_id")
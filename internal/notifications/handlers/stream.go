@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"StartupPCConfigurator/internal/domain"
+	"StartupPCConfigurator/internal/notifications/push"
+	"StartupPCConfigurator/internal/notifications/usecase"
+)
+
+// streamHeartbeat — как часто слать комментарий, чтобы прокси не закрывали
+// простаивающее соединение
+const streamHeartbeat = 25 * time.Second
+
+type StreamHandler struct {
+	uc  usecase.NotificationUseCase
+	hub *push.Hub
+}
+
+func NewStreamHandler(uc usecase.NotificationUseCase, hub *push.Hub) *StreamHandler {
+	return &StreamHandler{uc: uc, hub: hub}
+}
+
+// Stream обрабатывает GET /notifications/stream — Server-Sent Events.
+// События: notification (новое уведомление) и unread (счётчик непрочитанных).
+// При переподключении клиент передаёт Last-Event-ID (заголовок или
+// ?lastEventId=) и получает пропущенные события.
+func (h *StreamHandler) Stream(c *gin.Context) {
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+	userID, ok := userIDVal.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID type"})
+		return
+	}
+	lastID := c.GetHeader("Last-Event-ID")
+	if lastID == "" {
+		lastID = c.Query("lastEventId")
+	}
+	if lastID != "" && !push.ValidID(lastID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid Last-Event-ID"})
+		return
+	}
+
+	// подписываемся до чтения истории, чтобы не потерять события между ними
+	client, ok := h.hub.Subscribe(userID)
+	if !ok {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "service is shutting down"})
+		return
+	}
+	defer client.Close()
+
+	ctx := c.Request.Context()
+	var missed []domain.NotificationEvent
+	if lastID != "" {
+		var err error
+		if missed, err = h.uc.EventsSince(ctx, userID, lastID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	unread, err := h.uc.GetUnreadCount(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	w := c.Writer
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	for _, ev := range missed {
+		writeEvent(w, ev)
+		lastID = ev.ID
+	}
+	// текущий счётчик без id: Last-Event-ID клиента не меняется
+	fmt.Fprintf(w, "event: %s\ndata: {\"unread\":%d}\n\n", domain.NotificationEventUnread, unread)
+	w.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-client.C:
+			if !ok {
+				return
+			}
+			if lastID != "" && !push.After(ev.ID, lastID) {
+				continue // уже отправлено из истории
+			}
+			writeEvent(w, ev)
+			w.Flush()
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+			w.Flush()
+		}
+	}
+}
+
+func writeEvent(w gin.ResponseWriter, ev domain.NotificationEvent) {
+	fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", ev.ID, ev.Type, ev.Data)
+}
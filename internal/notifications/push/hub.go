@@ -0,0 +1,130 @@
+package push
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"StartupPCConfigurator/internal/domain"
+	"StartupPCConfigurator/internal/notifications/repository"
+)
+
+// clientBuffer — сколько событий ждут медленного клиента; переполнение
+// закрывает поток, и клиент переподключается с Last-Event-ID
+const clientBuffer = 64
+
+// Hub раздаёт события из Redis pub/sub клиентам, подключённым к этому
+// экземпляру сервиса. Каждый экземпляр получает все события и отдаёт
+// только своим подключениям.
+type Hub struct {
+	events  repository.NotificationEvents
+	logger  *log.Logger
+	mu      sync.Mutex
+	clients map[uuid.UUID]map[*Client]struct{}
+	closed  bool
+}
+
+// Client — одно подключение к потоку. C закрывается, когда поток
+// прерван: сервис останавливается или клиент не успевает читать.
+type Client struct {
+	C      <-chan domain.NotificationEvent
+	ch     chan domain.NotificationEvent
+	userID uuid.UUID
+	hub    *Hub
+}
+
+func NewHub(events repository.NotificationEvents, logger *log.Logger) *Hub {
+	return &Hub{events: events, logger: logger, clients: map[uuid.UUID]map[*Client]struct{}{}}
+}
+
+// Run слушает события, пока не отменён ctx, затем закрывает все подключения
+func (h *Hub) Run(ctx context.Context) {
+	for ev := range h.events.Listen(ctx) {
+		h.dispatch(ev)
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for _, set := range h.clients {
+		for c := range set {
+			close(c.ch)
+		}
+	}
+	h.clients = nil
+}
+
+func (h *Hub) dispatch(ev domain.NotificationEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for c := range h.clients[ev.UserID] {
+		select {
+		case c.ch <- ev:
+		default:
+			h.logger.Printf("notification stream of %s is too slow, disconnected", ev.UserID)
+			h.remove(c)
+			close(c.ch)
+		}
+	}
+}
+
+// Subscribe подключает клиента пользователя; ok = false — сервис останавливается
+func (h *Hub) Subscribe(userID uuid.UUID) (c *Client, ok bool) {
+	ch := make(chan domain.NotificationEvent, clientBuffer)
+	c = &Client{C: ch, ch: ch, userID: userID, hub: h}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil, false
+	}
+	if h.clients[userID] == nil {
+		h.clients[userID] = map[*Client]struct{}{}
+	}
+	h.clients[userID][c] = struct{}{}
+	return c, true
+}
+
+// Close отключает клиента
+func (c *Client) Close() {
+	c.hub.mu.Lock()
+	defer c.hub.mu.Unlock()
+	if _, ok := c.hub.clients[c.userID][c]; ok {
+		c.hub.remove(c)
+		close(c.ch)
+	}
+}
+
+func (h *Hub) remove(c *Client) {
+	delete(h.clients[c.userID], c)
+	if len(h.clients[c.userID]) == 0 {
+		delete(h.clients, c.userID)
+	}
+}
+
+// ValidID — id имеет вид ID записи Redis Stream: <ms>-<seq>
+func ValidID(id string) bool {
+	ms, seq, ok := strings.Cut(id, "-")
+	if !ok {
+		return false
+	}
+	_, err1 := strconv.ParseUint(ms, 10, 64)
+	_, err2 := strconv.ParseUint(seq, 10, 64)
+	return err1 == nil && err2 == nil
+}
+
+// After — событие с ID a идёт в потоке позже, чем b (оба — ValidID)
+func After(a, b string) bool {
+	ams, aseq, _ := strings.Cut(a, "-")
+	bms, bseq, _ := strings.Cut(b, "-")
+	am, _ := strconv.ParseUint(ams, 10, 64)
+	bm, _ := strconv.ParseUint(bms, 10, 64)
+	if am != bm {
+		return am > bm
+	}
+	as, _ := strconv.ParseUint(aseq, 10, 64)
+	bs, _ := strconv.ParseUint(bseq, 10, 64)
+	return as > bs
+}
@@ -3,23 +3,26 @@ package rabbitmq
 import (
 	aggUc "StartupPCConfigurator/internal/aggregator/usecase"
 	notifUC "StartupPCConfigurator/internal/notifications/usecase"
+	mq "StartupPCConfigurator/pkg/rabbitmq"
 	"context"
-	"encoding/json"
-	"github.com/streadway/amqp"
 	"log"
 )
 
-func StartNotificationsConsumer(ch *amqp.Channel, uc notifUC.NotificationUseCase, logger *log.Logger) error {
-	q, _ := ch.QueueDeclare("price.changed", true, false, false, false, nil)
-	msgs, _ := ch.Consume(q.Name, "", true, false, false, false, nil)
-	for d := range msgs {
-		var msg aggUc.PriceChangedMsg
-		if err := json.Unmarshal(d.Body, &msg); err != nil {
-			logger.Println("Bad message:", err)
-			continue
-		}
+// StartNotificationsConsumer слушает "price.changed", пока не отменён ctx, переживая переподключения.
+// Если уведомления не разослались, событие повторяется, затем уходит в price.changed.dlq.
+func StartNotificationsConsumer(ctx context.Context, conn *mq.Conn, uc notifUC.NotificationUseCase, logger *log.Logger) {
+	cfg := mq.ConsumerConfig{Queue: "price.changed", Prefetch: 4, Logger: logger}
+	mq.Run(ctx, conn, cfg, mq.JSON(func(ctx context.Context, msg aggUc.PriceChangedMsg) error {
 		// on each price.change, уведомляем всех подписавшихся пользователей
-		uc.HandlePriceChange(context.Background(), msg)
-	}
-	return nil
+		return uc.HandlePriceChange(ctx, msg)
+	}))
+}
+
+// StartAvailabilityConsumer слушает "availability.changed": уведомления
+// «снова в наличии» и «закончился» для подписок на наличие
+func StartAvailabilityConsumer(ctx context.Context, conn *mq.Conn, uc notifUC.NotificationUseCase, logger *log.Logger) {
+	cfg := mq.ConsumerConfig{Queue: aggUc.RoutingKeyAvailabilityChanged, Logger: logger}
+	mq.Run(ctx, conn, cfg, mq.JSON(func(ctx context.Context, msg aggUc.AvailabilityChangedMsg) error {
+		return uc.HandleAvailabilityChange(ctx, msg)
+	}))
 }
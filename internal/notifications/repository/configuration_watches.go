@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"StartupPCConfigurator/internal/domain"
+	"github.com/google/uuid"
+)
+
+// ErrWatchChanged — watch was updated concurrently since it was read
+var ErrWatchChanged = errors.New("configuration watch changed concurrently")
+
+const configurationWatchColumns = `w.user_id, w.config_id, c.name, w.budget, w.min_drop_percent,
+       w.last_total, w.baseline_total, w.baseline_prices, w.created_at, w.updated_at`
+
+func scanConfigurationWatch(rows *sql.Rows) (domain.ConfigurationWatch, error) {
+	var (
+		w            domain.ConfigurationWatch
+		budget, drop sql.NullFloat64
+		prices       []byte
+	)
+	if err := rows.Scan(&w.UserID, &w.ConfigID, &w.ConfigName, &budget, &drop,
+		&w.LastTotal, &w.BaselineTotal, &prices, &w.CreatedAt, &w.UpdatedAt); err != nil {
+		return w, err
+	}
+	if budget.Valid {
+		w.Budget = &budget.Float64
+	}
+	if drop.Valid {
+		w.MinDropPercent = &drop.Float64
+	}
+	w.BaselinePrices = map[int]float64{}
+	if len(prices) > 0 {
+		if err := json.Unmarshal(prices, &w.BaselinePrices); err != nil {
+			return w, err
+		}
+	}
+	return w, nil
+}
+
+func (r *repoImpl) queryConfigurationWatches(ctx context.Context, query string, args ...interface{}) ([]domain.ConfigurationWatch, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []domain.ConfigurationWatch{}
+	for rows.Next() {
+		w, err := scanConfigurationWatch(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, w)
+	}
+	return out, rows.Err()
+}
+
+// GetConfigurationOwner returns the owner and name of a saved configuration
+func (r *repoImpl) GetConfigurationOwner(ctx context.Context, configID int) (uuid.UUID, string, error) {
+	var (
+		owner uuid.UUID
+		name  string
+	)
+	err := r.db.QueryRowContext(ctx,
+		`SELECT user_id, name FROM configurations WHERE id = $1`, configID,
+	).Scan(&owner, &name)
+	if err == sql.ErrNoRows {
+		return uuid.Nil, "", domain.ErrConfigNotFound
+	}
+	return owner, name, err
+}
+
+// GetConfigurationPrices returns the configuration's components with their
+// cheapest offer price, skipping out_of_stock and preorder offers (0 when
+// there is none)
+func (r *repoImpl) GetConfigurationPrices(ctx context.Context, configID int) ([]domain.ConfigurationPriceItem, error) {
+	const query = `
+SELECT cc.component_id, c.name, c.category, SUM(cc.quantity)::int,
+       COALESCE((SELECT MIN(o.price) FROM offers o
+                  WHERE o.component_id = cc.component_id
+                    AND o.availability_status NOT IN ('out_of_stock', 'preorder')), 0)
+  FROM configuration_components cc
+  JOIN components c ON c.id = cc.component_id
+ WHERE cc.config_id = $1
+ GROUP BY cc.component_id, c.name, c.category
+ ORDER BY c.category, cc.component_id
+`
+	rows, err := r.db.QueryContext(ctx, query, configID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []domain.ConfigurationPriceItem{}
+	for rows.Next() {
+		var it domain.ConfigurationPriceItem
+		if err := rows.Scan(&it.ComponentID, &it.Name, &it.Category, &it.Quantity, &it.Price); err != nil {
+			return nil, err
+		}
+		out = append(out, it)
+	}
+	return out, rows.Err()
+}
+
+// SaveConfigurationWatch creates the watch or replaces its conditions and
+// baseline. Returns the watch's creation and update times.
+func (r *repoImpl) SaveConfigurationWatch(ctx context.Context, w domain.ConfigurationWatch) (time.Time, time.Time, error) {
+	prices, err := json.Marshal(w.BaselinePrices)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	var createdAt, updatedAt time.Time
+	err = r.db.QueryRowContext(ctx,
+		`INSERT INTO configuration_watches(user_id, config_id, budget, min_drop_percent, last_total, baseline_total, baseline_prices)
+     VALUES($1, $2, $3, $4, $5, $6, $7)
+     ON CONFLICT (user_id, config_id) DO UPDATE
+        SET budget           = EXCLUDED.budget,
+            min_drop_percent = EXCLUDED.min_drop_percent,
+            last_total       = EXCLUDED.last_total,
+            baseline_total   = EXCLUDED.baseline_total,
+            baseline_prices  = EXCLUDED.baseline_prices,
+            updated_at       = NOW()
+     RETURNING created_at, updated_at`,
+		w.UserID, w.ConfigID, w.Budget, w.MinDropPercent, w.LastTotal, w.BaselineTotal, prices,
+	).Scan(&createdAt, &updatedAt)
+	return createdAt, updatedAt, err
+}
+
+// ListConfigurationWatches returns the user's configuration watches, newest first
+func (r *repoImpl) ListConfigurationWatches(ctx context.Context, userID uuid.UUID) ([]domain.ConfigurationWatch, error) {
+	return r.queryConfigurationWatches(ctx, `
+SELECT `+configurationWatchColumns+`
+  FROM configuration_watches w
+  JOIN configurations c ON c.id = w.config_id
+ WHERE w.user_id = $1
+ ORDER BY w.created_at DESC, w.id DESC`, userID)
+}
+
+// GetConfigurationWatches returns watches of every configuration containing the component
+func (r *repoImpl) GetConfigurationWatches(ctx context.Context, componentID string) ([]domain.ConfigurationWatch, error) {
+	return r.queryConfigurationWatches(ctx, `
+SELECT `+configurationWatchColumns+`
+  FROM configuration_watches w
+  JOIN configurations c ON c.id = w.config_id
+ WHERE w.config_id IN (
+       SELECT config_id FROM configuration_components WHERE component_id::text = $1)`, componentID)
+}
+
+// UpdateConfigurationWatch stores the recomputed totals and, if n is not nil,
+// the notification in one transaction. The watch must not have changed since
+// it was read (w.UpdatedAt), otherwise ErrWatchChanged is returned.
+func (r *repoImpl) UpdateConfigurationWatch(ctx context.Context, w domain.ConfigurationWatch, n *domain.Notification) error {
+	prices, err := json.Marshal(w.BaselinePrices)
+	if err != nil {
+		return err
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+UPDATE configuration_watches
+   SET last_total = $3, baseline_total = $4, baseline_prices = $5, updated_at = NOW()
+ WHERE user_id = $1 AND config_id = $2 AND updated_at = $6`,
+		w.UserID, w.ConfigID, w.LastTotal, w.BaselineTotal, prices, w.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	if cnt, err := res.RowsAffected(); err != nil {
+		return err
+	} else if cnt == 0 {
+		return ErrWatchChanged
+	}
+	if n != nil {
+		if err := insertNotification(ctx, tx, *n); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// DeleteConfigurationWatch removes the user's watch of the configuration
+func (r *repoImpl) DeleteConfigurationWatch(ctx context.Context, userID uuid.UUID, configID int) error {
+	_, err := r.db.ExecContext(ctx,
+		`DELETE FROM configuration_watches WHERE user_id = $1 AND config_id = $2`, userID, configID)
+	return err
+}
@@ -0,0 +1,234 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"StartupPCConfigurator/internal/domain"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// emailBatchMax caps how many notifications go into one digest; the rest wait for the next one
+const emailBatchMax = 200
+
+// GetEmailSettings returns the user's email settings; "off" if never set
+func (r *repoImpl) GetEmailSettings(ctx context.Context, userID uuid.UUID) (domain.EmailSettings, error) {
+	s := domain.EmailSettings{UserID: userID, Mode: domain.EmailModeOff}
+	err := r.db.QueryRowContext(ctx,
+		`SELECT mode, last_digest_at, updated_at FROM email_settings WHERE user_id = $1`, userID,
+	).Scan(&s.Mode, &s.LastDigestAt, &s.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return s, nil
+	}
+	return s, err
+}
+
+// SaveEmailSettings sets the email mode. Switching on from "off" starts from
+// now: earlier notifications are not emailed.
+func (r *repoImpl) SaveEmailSettings(ctx context.Context, userID uuid.UUID, mode string) (domain.EmailSettings, error) {
+	s := domain.EmailSettings{UserID: userID}
+	err := r.db.QueryRowContext(ctx,
+		`INSERT INTO email_settings(user_id, mode)
+     VALUES($1, $2)
+     ON CONFLICT (user_id) DO UPDATE
+        SET mode           = EXCLUDED.mode,
+            since          = CASE WHEN email_settings.mode = 'off' THEN NOW() ELSE email_settings.since END,
+            last_digest_at = CASE WHEN email_settings.mode = 'off' THEN NOW() ELSE email_settings.last_digest_at END,
+            updated_at     = NOW()
+     RETURNING mode, last_digest_at, updated_at`,
+		userID, mode,
+	).Scan(&s.Mode, &s.LastDigestAt, &s.UpdatedAt)
+	return s, err
+}
+
+// emailClaimTTL is how long a replica owns a due user while sending; if it
+// dies mid-send the user becomes due again after that
+const emailClaimTTL = 5 * time.Minute
+
+// pendingEmailFilter selects notifications still to be emailed. Notifications
+// of hard-deleted components are skipped: there is nothing to render for them.
+const pendingEmailFilter = `
+       n.user_id = s.user_id AND n.emailed_at IS NULL AND n.created_at >= s.since
+   AND EXISTS (SELECT 1 FROM components c WHERE c.id = n.component_id::integer)`
+
+// DeliverEmails claims users whose emails are due (other replicas skip them
+// until the claim expires), calls send for each and marks what was delivered
+// right after a successful send. No transaction or row lock is held while
+// mail is sent. Instant mode sends every notification separately; digests
+// send everything pending at once and move last_digest_at even when there was
+// nothing to send. A failed send leaves the user's notifications pending; the
+// other users are still processed.
+func (r *repoImpl) DeliverEmails(ctx context.Context, limit int, send func(domain.EmailBatch) error) (int, error) {
+	users, err := r.claimEmailUsers(ctx, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	var errs []error
+	for _, d := range users {
+		n, err := r.deliverUserEmails(ctx, d.batch, d.since, send)
+		sent += n
+		if err != nil {
+			errs = append(errs, fmt.Errorf("user %s: %w", d.batch.UserID, err))
+		}
+	}
+	return sent, errors.Join(errs...)
+}
+
+type dueEmailUser struct {
+	batch domain.EmailBatch
+	since time.Time
+}
+
+// claimEmailUsers marks up to limit due users as taken by this replica
+func (r *repoImpl) claimEmailUsers(ctx context.Context, limit int) ([]dueEmailUser, error) {
+	query := `
+WITH due AS (
+SELECT s.user_id
+  FROM email_settings s
+ WHERE (s.claimed_until IS NULL OR s.claimed_until < NOW())
+   AND ((s.mode = 'instant' AND EXISTS (SELECT 1 FROM notifications n WHERE` + pendingEmailFilter + `))
+    OR (s.mode = 'daily'  AND s.last_digest_at <= NOW() - INTERVAL '1 day')
+    OR (s.mode = 'weekly' AND s.last_digest_at <= NOW() - INTERVAL '7 days'))
+ ORDER BY s.last_digest_at
+ LIMIT $1
+   FOR UPDATE OF s SKIP LOCKED
+)
+UPDATE email_settings s
+   SET claimed_until = NOW() + $2::int * INTERVAL '1 second'
+  FROM due, users u
+ WHERE s.user_id = due.user_id AND u.id = s.user_id
+RETURNING s.user_id, u.email, u.name, s.mode, s.since, s.last_digest_at`
+	rows, err := r.db.QueryContext(ctx, query, limit, int(emailClaimTTL/time.Second))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []dueEmailUser
+	for rows.Next() {
+		var d dueEmailUser
+		if err := rows.Scan(&d.batch.UserID, &d.batch.Email, &d.batch.Name, &d.batch.Mode, &d.since, &d.batch.LastDigestAt); err != nil {
+			return nil, err
+		}
+		users = append(users, d)
+	}
+	return users, rows.Err()
+}
+
+// deliverUserEmails sends the user's pending notifications and releases the
+// claim. Every successful send is recorded before the next one starts.
+func (r *repoImpl) deliverUserEmails(ctx context.Context, batch domain.EmailBatch, since time.Time, send func(domain.EmailBatch) error) (int, error) {
+	// claim is released on every path; on failure the user is due again next tick
+	defer r.db.ExecContext(context.WithoutCancel(ctx),
+		`UPDATE email_settings SET claimed_until = NULL WHERE user_id = $1`, batch.UserID)
+
+	items, err := r.pendingEmailItems(ctx, batch.UserID, since)
+	if err != nil {
+		return 0, err
+	}
+	batch.Items = items
+
+	if batch.Mode == domain.EmailModeInstant {
+		sent := 0
+		for _, item := range items {
+			one := batch
+			one.Items = []domain.NotificationResponse{item}
+			if err := send(one); err != nil {
+				return sent, err
+			}
+			sent++
+			if err := r.markEmailed(ctx, batch.UserID, []string{item.ID.String()}, false); err != nil {
+				return sent, err
+			}
+		}
+		return sent, nil
+	}
+
+	sent := 0
+	if len(items) > 0 {
+		if err := send(batch); err != nil {
+			return 0, err
+		}
+		sent = 1
+	}
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID.String())
+	}
+	return sent, r.markEmailed(ctx, batch.UserID, ids, true)
+}
+
+// markEmailed records delivered notifications and, for digests, moves
+// last_digest_at, in one short transaction
+func (r *repoImpl) markEmailed(ctx context.Context, userID uuid.UUID, ids []string, digest bool) error {
+	// the mail is already out: do not lose the mark to a cancelled ctx
+	ctx = context.WithoutCancel(ctx)
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if len(ids) > 0 {
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE notifications SET emailed_at = NOW() WHERE id = ANY($1::uuid[])`, pq.Array(ids)); err != nil {
+			return err
+		}
+	}
+	if digest {
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE email_settings SET last_digest_at = NOW() WHERE user_id = $1`, userID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// pendingEmailItems returns the notifications still to be emailed; the filter
+// matches pendingEmailFilter, so a user found due always has something here
+func (r *repoImpl) pendingEmailItems(ctx context.Context, userID uuid.UUID, since time.Time) ([]domain.NotificationResponse, error) {
+	const query = `
+SELECT n.id, n.component_id, c.name, c.category, n.type, n.shop_id, COALESCE(sh.name, ''),
+       n.old_price, n.new_price, COALESCE(n.availability, ''), n.config_id, n.details,
+       n.is_read, n.created_at
+  FROM notifications n
+  JOIN components c ON c.id = n.component_id::integer
+  LEFT JOIN shops sh ON sh.id = n.shop_id
+ WHERE n.user_id = $1 AND n.emailed_at IS NULL AND n.created_at >= $2
+ ORDER BY n.created_at
+ LIMIT $3`
+	rows, err := r.db.QueryContext(ctx, query, userID, since, emailBatchMax)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.NotificationResponse
+	for rows.Next() {
+		var (
+			nr       domain.NotificationResponse
+			configID sql.NullInt64
+			details  []byte
+		)
+		if err := rows.Scan(&nr.ID, &nr.ComponentID, &nr.ComponentName, &nr.ComponentCategory,
+			&nr.Type, &nr.ShopID, &nr.ShopName, &nr.OldPrice, &nr.NewPrice, &nr.Availability,
+			&configID, &details, &nr.IsRead, &nr.CreatedAt); err != nil {
+			return nil, err
+		}
+		if configID.Valid {
+			id := int(configID.Int64)
+			nr.ConfigID = &id
+		}
+		if len(details) > 0 {
+			nr.Details = details
+		}
+		out = append(out, nr)
+	}
+	return out, rows.Err()
+}
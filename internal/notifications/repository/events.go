@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"StartupPCConfigurator/internal/domain"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+// Redis layout of the notification stream
+const (
+	eventsChannel   = "notifications:events" // pub/sub channel shared by all replicas
+	eventsStreamLen = 500                    // events kept per user for resume
+	eventsStreamTTL = 24 * time.Hour         // idle users' streams expire
+	eventsReplayMax = 500
+)
+
+// NotificationEvents defines Redis operations for pushing events to connected clients
+type NotificationEvents interface {
+	// Publish appends the event to the user's stream and fans it out to
+	// every replica. Returns the event with its stream ID set.
+	Publish(ctx context.Context, ev domain.NotificationEvent) (domain.NotificationEvent, error)
+	// Since returns the user's events after lastID, oldest first
+	Since(ctx context.Context, userID uuid.UUID, lastID string) ([]domain.NotificationEvent, error)
+	// Listen delivers events published by any replica until ctx is done
+	Listen(ctx context.Context) <-chan domain.NotificationEvent
+}
+
+// eventsImpl implements NotificationEvents using Redis Streams and pub/sub
+type eventsImpl struct {
+	client *redis.Client
+	logger *log.Logger
+}
+
+// NewNotificationEvents constructs NotificationEvents with given Redis client
+func NewNotificationEvents(client *redis.Client, logger *log.Logger) NotificationEvents {
+	return &eventsImpl{client: client, logger: logger}
+}
+
+func eventsStreamKey(userID uuid.UUID) string {
+	return fmt.Sprintf("notifications:%s:events", userID.String())
+}
+
+// Publish appends the event to the user's stream and fans it out to every replica
+func (e *eventsImpl) Publish(ctx context.Context, ev domain.NotificationEvent) (domain.NotificationEvent, error) {
+	key := eventsStreamKey(ev.UserID)
+	id, err := e.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: key,
+		MaxLen: eventsStreamLen,
+		Approx: true,
+		Values: map[string]interface{}{"type": ev.Type, "data": string(ev.Data)},
+	}).Result()
+	if err != nil {
+		return ev, err
+	}
+	ev.ID = id
+
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return ev, err
+	}
+	pipe := e.client.Pipeline()
+	pipe.Expire(ctx, key, eventsStreamTTL)
+	pipe.Publish(ctx, eventsChannel, payload)
+	_, err = pipe.Exec(ctx)
+	return ev, err
+}
+
+// Since returns the user's events after lastID, oldest first
+func (e *eventsImpl) Since(ctx context.Context, userID uuid.UUID, lastID string) ([]domain.NotificationEvent, error) {
+	msgs, err := e.client.XRangeN(ctx, eventsStreamKey(userID), lastID, "+", eventsReplayMax+1).Result()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]domain.NotificationEvent, 0, len(msgs))
+	for _, m := range msgs {
+		if m.ID == lastID { // XRANGE start is inclusive
+			continue
+		}
+		typ, _ := m.Values["type"].(string)
+		data, _ := m.Values["data"].(string)
+		out = append(out, domain.NotificationEvent{ID: m.ID, UserID: userID, Type: typ, Data: json.RawMessage(data)})
+	}
+	return out, nil
+}
+
+// Listen delivers events published by any replica until ctx is done.
+// The subscription reconnects by itself if Redis goes away.
+func (e *eventsImpl) Listen(ctx context.Context) <-chan domain.NotificationEvent {
+	out := make(chan domain.NotificationEvent, 256)
+	sub := e.client.Subscribe(ctx, eventsChannel)
+	go func() {
+		defer close(out)
+		defer sub.Close()
+		msgs := sub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case m, ok := <-msgs:
+				if !ok {
+					return
+				}
+				var ev domain.NotificationEvent
+				if err := json.Unmarshal([]byte(m.Payload), &ev); err != nil {
+					e.logger.Printf("notification event: bad payload: %v", err)
+					continue
+				}
+				select {
+				case out <- ev:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out
+}
@@ -3,26 +3,47 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"StartupPCConfigurator/internal/domain"
 	"github.com/go-redis/redis/v8"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 // NotificationRepository defines DB operations for notifications
 type NotificationRepository interface {
-	GetSubscribers(ctx context.Context, componentID string) ([]uuid.UUID, error)
+	GetSubscriptions(ctx context.Context, componentID string) ([]domain.Subscription, error)
+	ListSubscriptions(ctx context.Context, userID uuid.UUID) ([]domain.Subscription, error)
 	CreateNotification(ctx context.Context, n domain.Notification) error
 	ListNotifications(ctx context.Context, userID uuid.UUID, page, pageSize int) ([]domain.NotificationResponse, int, error)
 	MarkAsRead(ctx context.Context, userID, notifID uuid.UUID) error
-	Subscribe(ctx context.Context, userID uuid.UUID, componentID string) error
+	Subscribe(ctx context.Context, s domain.Subscription) (time.Time, error)
 	Unsubscribe(ctx context.Context, userID uuid.UUID, componentID string) error
 	SubscribedMap(ctx context.Context,
 		userID uuid.UUID,
 		ids []string) (map[string]bool, error)
+
+	// уведомления по событию с отсечением повторной доставки по event_id
+	SaveEventNotifications(ctx context.Context, consumer, eventID string, ns []domain.Notification) (bool, error)
+
+	// слежение за стоимостью сборки (configuration_watches.go)
+	GetConfigurationOwner(ctx context.Context, configID int) (uuid.UUID, string, error)
+	GetConfigurationPrices(ctx context.Context, configID int) ([]domain.ConfigurationPriceItem, error)
+	SaveConfigurationWatch(ctx context.Context, w domain.ConfigurationWatch) (time.Time, time.Time, error)
+	ListConfigurationWatches(ctx context.Context, userID uuid.UUID) ([]domain.ConfigurationWatch, error)
+	GetConfigurationWatches(ctx context.Context, componentID string) ([]domain.ConfigurationWatch, error)
+	UpdateConfigurationWatch(ctx context.Context, w domain.ConfigurationWatch, n *domain.Notification) error
+	DeleteConfigurationWatch(ctx context.Context, userID uuid.UUID, configID int) error
+
+	// email-канал (email.go)
+	GetEmailSettings(ctx context.Context, userID uuid.UUID) (domain.EmailSettings, error)
+	SaveEmailSettings(ctx context.Context, userID uuid.UUID, mode string) (domain.EmailSettings, error)
+	DeliverEmails(ctx context.Context, limit int, send func(domain.EmailBatch) error) (int, error)
 }
 
 // repoImpl implements NotificationRepository using PostgreSQL
@@ -35,41 +56,118 @@ func NewNotificationRepository(db *sql.DB) NotificationRepository {
 	return &repoImpl{db: db}
 }
 
-// GetSubscribers returns user IDs subscribed to given component
-func (r *repoImpl) GetSubscribers(ctx context.Context, componentID string) ([]uuid.UUID, error) {
-	const query = `
-SELECT user_id
-FROM subscriptions
-WHERE component_id = $1
-`
-	rows, err := r.db.QueryContext(ctx, query, componentID)
+const subscriptionColumns = `user_id, component_id, price_alerts, target_price, min_drop_percent, direction, stock_alert, shop_id, created_at`
+
+func scanSubscription(rows *sql.Rows) (domain.Subscription, error) {
+	var (
+		s            domain.Subscription
+		target, drop sql.NullFloat64
+		shopID       sql.NullInt64
+	)
+	if err := rows.Scan(&s.UserID, &s.ComponentID, &s.PriceAlerts, &target, &drop, &s.Direction, &s.StockAlert, &shopID, &s.CreatedAt); err != nil {
+		return s, err
+	}
+	if target.Valid {
+		s.TargetPrice = &target.Float64
+	}
+	if drop.Valid {
+		s.MinDropPercent = &drop.Float64
+	}
+	if shopID.Valid {
+		s.ShopID = &shopID.Int64
+	}
+	return s, nil
+}
+
+func (r *repoImpl) querySubscriptions(ctx context.Context, query string, args ...interface{}) ([]domain.Subscription, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var users []uuid.UUID
+	out := []domain.Subscription{}
 	for rows.Next() {
-		var u uuid.UUID
-		if err := rows.Scan(&u); err != nil {
+		s, err := scanSubscription(rows)
+		if err != nil {
 			return nil, err
 		}
-		users = append(users, u)
+		out = append(out, s)
 	}
-	return users, rows.Err()
+	return out, rows.Err()
+}
+
+// GetSubscriptions returns subscriptions to the component together with their conditions
+func (r *repoImpl) GetSubscriptions(ctx context.Context, componentID string) ([]domain.Subscription, error) {
+	return r.querySubscriptions(ctx,
+		`SELECT `+subscriptionColumns+` FROM subscriptions WHERE component_id = $1`, componentID)
+}
+
+// ListSubscriptions returns the user's subscriptions, newest first
+func (r *repoImpl) ListSubscriptions(ctx context.Context, userID uuid.UUID) ([]domain.Subscription, error) {
+	return r.querySubscriptions(ctx,
+		`SELECT `+subscriptionColumns+` FROM subscriptions WHERE user_id = $1 ORDER BY created_at DESC, id DESC`, userID)
+}
+
+// SaveEventNotifications records the event as handled by the consumer and
+// inserts its notifications in one transaction. Returns false without
+// inserting anything if the event was already handled; a concurrent
+// delivery of the same event waits on the processed_events row and gets
+// false once the first one commits. An empty eventID disables the check.
+func (r *repoImpl) SaveEventNotifications(ctx context.Context, consumer, eventID string, ns []domain.Notification) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	if eventID != "" {
+		res, err := tx.ExecContext(ctx, `
+INSERT INTO processed_events (consumer, event_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING
+`, consumer, eventID)
+		if err != nil {
+			return false, err
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return false, err
+		} else if n == 0 {
+			return false, nil
+		}
+	}
+	for _, n := range ns {
+		if err := insertNotification(ctx, tx, n); err != nil {
+			return false, err
+		}
+	}
+	return true, tx.Commit()
 }
 
 // CreateNotification inserts a new notification record
 func (r *repoImpl) CreateNotification(ctx context.Context, n domain.Notification) error {
+	return insertNotification(ctx, r.db, n)
+}
+
+// execer is satisfied by both *sql.DB and *sql.Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+func insertNotification(ctx context.Context, db execer, n domain.Notification) error {
 	const query = `
 INSERT INTO notifications
-  (id, user_id, component_id, shop_id, old_price, new_price, is_read, created_at)
+  (id, user_id, type, component_id, shop_id, old_price, new_price, availability, config_id, details, is_read, created_at)
 VALUES
-  ($1, $2, $3, $4, $5, $6, $7, $8)
+  ($1, $2, COALESCE(NULLIF($3, ''), 'price_changed'), $4, $5, $6, $7, NULLIF($8, ''), $9, $10, $11, $12)
 `
-	_, err := r.db.ExecContext(ctx, query,
-		n.ID, n.UserID, n.ComponentID, n.ShopID,
-		n.OldPrice, n.NewPrice, n.IsRead, n.CreatedAt,
+	var details interface{}
+	if len(n.Details) > 0 {
+		details = []byte(n.Details)
+	}
+	_, err := db.ExecContext(ctx, query,
+		n.ID, n.UserID, n.Type, n.ComponentID, n.ShopID,
+		n.OldPrice, n.NewPrice, string(n.Availability), n.ConfigID, details, n.IsRead, n.CreatedAt,
 	)
 	return err
 }
@@ -96,9 +194,13 @@ func (r *repoImpl) ListNotifications(
         n.component_id,
         c.name           AS component_name,
         c.category       AS component_category,
+        n.type,
         n.shop_id,
         n.old_price,
         n.new_price,
+        COALESCE(n.availability, ''),
+        n.config_id,
+        n.details,
         n.is_read,
         n.created_at
     FROM notifications n
@@ -118,21 +220,36 @@ func (r *repoImpl) ListNotifications(
 
 	var out []domain.NotificationResponse
 	for rows.Next() {
-		var nr domain.NotificationResponse
+		var (
+			nr       domain.NotificationResponse
+			configID sql.NullInt64
+			details  []byte
+		)
 		// у нас структура NotificationResponse: ID, ComponentID, ComponentName, ComponentCategory, ShopID, OldPrice, NewPrice, IsRead, CreatedAt
 		if err := rows.Scan(
 			&nr.ID,
 			&nr.ComponentID,
 			&nr.ComponentName,
 			&nr.ComponentCategory,
+			&nr.Type,
 			&nr.ShopID,
 			&nr.OldPrice,
 			&nr.NewPrice,
+			&nr.Availability,
+			&configID,
+			&details,
 			&nr.IsRead,
 			&nr.CreatedAt,
 		); err != nil {
 			return nil, 0, err
 		}
+		if configID.Valid {
+			id := int(configID.Int64)
+			nr.ConfigID = &id
+		}
+		if len(details) > 0 {
+			nr.Details = details
+		}
 		out = append(out, nr)
 	}
 	if err := rows.Err(); err != nil {
@@ -153,12 +270,28 @@ WHERE id = $1 AND user_id = $2
 	return err
 }
 
-func (r *repoImpl) Subscribe(ctx context.Context, userID uuid.UUID, componentID string) error {
-	_, err := r.db.ExecContext(ctx,
-		`INSERT INTO subscriptions(user_id,component_id) VALUES($1,$2)
-     ON CONFLICT DO NOTHING`,
-		userID, componentID)
-	return err
+// Subscribe creates the subscription or replaces the conditions of an existing one.
+// Returns the subscription's creation time.
+func (r *repoImpl) Subscribe(ctx context.Context, s domain.Subscription) (time.Time, error) {
+	var createdAt time.Time
+	err := r.db.QueryRowContext(ctx,
+		`INSERT INTO subscriptions(user_id, component_id, price_alerts, target_price, min_drop_percent, direction, stock_alert, shop_id)
+     VALUES($1, $2, $3, $4, $5, $6, $7, $8)
+     ON CONFLICT (user_id, component_id) DO UPDATE
+        SET price_alerts     = EXCLUDED.price_alerts,
+            target_price     = EXCLUDED.target_price,
+            min_drop_percent = EXCLUDED.min_drop_percent,
+            direction        = EXCLUDED.direction,
+            stock_alert      = EXCLUDED.stock_alert,
+            shop_id          = EXCLUDED.shop_id
+     RETURNING created_at`,
+		s.UserID, s.ComponentID, s.PriceAlerts, s.TargetPrice, s.MinDropPercent, s.Direction, s.StockAlert, s.ShopID,
+	).Scan(&createdAt)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23503" { // foreign_key_violation: нет такого магазина
+		return time.Time{}, domain.ErrShopNotFound
+	}
+	return createdAt, err
 }
 
 func (r *repoImpl) Unsubscribe(ctx context.Context, userID uuid.UUID, componentID string) error {
@@ -212,7 +345,7 @@ func (r *repoImpl) SubscribedMap(
 
 // NotificationCache defines Redis operations for unread counts
 type NotificationCache interface {
-	IncrementUnread(ctx context.Context, userID uuid.UUID) error
+	IncrementUnread(ctx context.Context, userID uuid.UUID) (int, error)
 	GetUnreadCount(ctx context.Context, userID uuid.UUID) (int, error)
 	ResetUnread(ctx context.Context, userID uuid.UUID) error
 }
@@ -227,10 +360,11 @@ func NewNotificationCache(client *redis.Client) NotificationCache {
 	return &cacheImpl{client: client}
 }
 
-// IncrementUnread increases unread counter for a user
-func (c *cacheImpl) IncrementUnread(ctx context.Context, userID uuid.UUID) error {
+// IncrementUnread increases unread counter for a user and returns the new value
+func (c *cacheImpl) IncrementUnread(ctx context.Context, userID uuid.UUID) (int, error) {
 	key := fmt.Sprintf("notifications:%s:unread", userID.String())
-	return c.client.Incr(ctx, key).Err()
+	cnt, err := c.client.Incr(ctx, key).Result()
+	return int(cnt), err
 }
 
 // GetUnreadCount retrieves the unread count for a user
@@ -0,0 +1,216 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+
+	"StartupPCConfigurator/internal/domain"
+)
+
+// ErrInvalidWatch — некорректные условия слежения за сборкой
+var ErrInvalidWatch = errors.New("invalid configuration watch")
+
+// validateWatch проверяет условия слежения за сборкой
+func validateWatch(w domain.ConfigurationWatch) error {
+	if w.ConfigID <= 0 {
+		return fmt.Errorf("%w: configId is required", ErrInvalidWatch)
+	}
+	if w.Budget == nil && w.MinDropPercent == nil {
+		return fmt.Errorf("%w: set budget or minDropPercent", ErrInvalidWatch)
+	}
+	if w.Budget != nil && *w.Budget <= 0 {
+		return fmt.Errorf("%w: budget must be positive", ErrInvalidWatch)
+	}
+	if w.MinDropPercent != nil && (*w.MinDropPercent <= 0 || *w.MinDropPercent >= 100) {
+		return fmt.Errorf("%w: minDropPercent must be between 0 and 100", ErrInvalidWatch)
+	}
+	return nil
+}
+
+// priceTotal — стоимость сборки по минимальным ценам; ok = false, если
+// у какой-то позиции нет предложений и стоимость посчитать нельзя
+func priceTotal(items []domain.ConfigurationPriceItem) (total float64, ok bool) {
+	if len(items) == 0 {
+		return 0, false
+	}
+	for _, it := range items {
+		if it.Price <= 0 {
+			return 0, false
+		}
+		total += it.Price * float64(it.Quantity)
+	}
+	return math.Round(total*100) / 100, true
+}
+
+func itemPrices(items []domain.ConfigurationPriceItem) map[int]float64 {
+	m := make(map[int]float64, len(items))
+	for _, it := range items {
+		m[it.ComponentID] = it.Price
+	}
+	return m
+}
+
+// evaluateWatch пересчитывает слежение по текущим ценам сборки. Бюджет
+// срабатывает, когда стоимость переходит через него (в любую сторону),
+// снижение — когда стоимость упала на MinDropPercent от базовой. После
+// уведомления и при росте стоимости базой становятся текущие цены.
+// Возвращает новое состояние, уведомление (или nil) и признак изменений.
+func evaluateWatch(w domain.ConfigurationWatch, items []domain.ConfigurationPriceItem,
+) (domain.ConfigurationWatch, *domain.ConfigurationAlert, bool) {
+	total, ok := priceTotal(items)
+	if !ok {
+		return w, nil, false
+	}
+	next := w
+	next.LastTotal = total
+	if w.BaselineTotal <= 0 {
+		// база ещё не посчитана: при создании не у всех позиций были цены
+		next.BaselineTotal, next.BaselinePrices = total, itemPrices(items)
+		return next, nil, true
+	}
+
+	var reasons []string
+	if w.Budget != nil && w.LastTotal > 0 {
+		switch budget := *w.Budget; {
+		case w.LastTotal > budget && total <= budget:
+			reasons = append(reasons, domain.ConfigAlertBudgetReached)
+		case w.LastTotal <= budget && total > budget:
+			reasons = append(reasons, domain.ConfigAlertBudgetExceeded)
+		}
+	}
+	if w.MinDropPercent != nil && total < w.BaselineTotal &&
+		(w.BaselineTotal-total)/w.BaselineTotal*100 >= *w.MinDropPercent {
+		reasons = append(reasons, domain.ConfigAlertPriceDrop)
+	}
+
+	if len(reasons) == 0 {
+		if total > w.BaselineTotal {
+			next.BaselineTotal, next.BaselinePrices = total, itemPrices(items)
+		}
+		return next, nil, next.LastTotal != w.LastTotal || next.BaselineTotal != w.BaselineTotal
+	}
+
+	alert := &domain.ConfigurationAlert{
+		ConfigID:   w.ConfigID,
+		ConfigName: w.ConfigName,
+		Reasons:    reasons,
+		Budget:     w.Budget,
+		OldTotal:   w.BaselineTotal,
+		NewTotal:   total,
+		Changes:    []domain.ConfigurationPriceChange{},
+	}
+	for _, it := range items {
+		old, known := w.BaselinePrices[it.ComponentID]
+		if known && old == it.Price {
+			continue
+		}
+		alert.Changes = append(alert.Changes, domain.ConfigurationPriceChange{
+			ComponentID: it.ComponentID,
+			Name:        it.Name,
+			Category:    it.Category,
+			Quantity:    it.Quantity,
+			OldPrice:    old,
+			NewPrice:    it.Price,
+		})
+	}
+	next.BaselineTotal, next.BaselinePrices = total, itemPrices(items)
+	return next, alert, true
+}
+
+// handleConfigurationWatches пересчитывает стоимость сборок, в которые входит
+// компонент, и уведомляет их владельцев. Вызывается и при смене цены, и при
+// смене наличия: товары, которых нет в продаже, в стоимость не входят.
+// Ошибка возвращается, чтобы событие обработалось повторно: пересчёт идёт от
+// сохранённого состояния, поэтому повтор не дублирует уже отправленные
+// уведомления.
+func (uc *notificationUseCase) handleConfigurationWatches(ctx context.Context, componentID string, shopID int64) error {
+	watches, err := uc.repo.GetConfigurationWatches(ctx, componentID)
+	if err != nil {
+		return fmt.Errorf("get configuration watches: %w", err)
+	}
+	prices := map[int][]domain.ConfigurationPriceItem{}
+	for _, w := range watches {
+		items, cached := prices[w.ConfigID]
+		if !cached {
+			if items, err = uc.repo.GetConfigurationPrices(ctx, w.ConfigID); err != nil {
+				return fmt.Errorf("configuration %d prices: %w", w.ConfigID, err)
+			}
+			prices[w.ConfigID] = items
+		}
+
+		next, alert, changed := evaluateWatch(w, items)
+		if !changed {
+			continue
+		}
+		var notif *domain.Notification
+		if alert != nil {
+			details, err := json.Marshal(alert)
+			if err != nil {
+				return err
+			}
+			configID := w.ConfigID
+			notif = &domain.Notification{
+				ID:          uuid.New(),
+				UserID:      w.UserID,
+				Type:        domain.NotificationConfigPrice,
+				ComponentID: componentID,
+				ShopID:      shopID,
+				OldPrice:    alert.OldTotal,
+				NewPrice:    alert.NewTotal,
+				ConfigID:    &configID,
+				Details:     details,
+				CreatedAt:   time.Now(),
+			}
+		}
+		if err := uc.repo.UpdateConfigurationWatch(ctx, next, notif); err != nil {
+			return fmt.Errorf("configuration %d watch: %w", w.ConfigID, err)
+		}
+		if notif != nil {
+			uc.delivered(ctx, *notif)
+		}
+	}
+	return nil
+}
+
+// WatchConfiguration начинает слежение за стоимостью своей сборки или меняет
+// его условия; базой становятся текущие цены
+func (uc *notificationUseCase) WatchConfiguration(ctx context.Context, w domain.ConfigurationWatch) (domain.ConfigurationWatch, error) {
+	if err := validateWatch(w); err != nil {
+		return domain.ConfigurationWatch{}, err
+	}
+	owner, name, err := uc.repo.GetConfigurationOwner(ctx, w.ConfigID)
+	if err != nil {
+		return domain.ConfigurationWatch{}, err
+	}
+	if owner != w.UserID {
+		return domain.ConfigurationWatch{}, domain.ErrForbidden
+	}
+	items, err := uc.repo.GetConfigurationPrices(ctx, w.ConfigID)
+	if err != nil {
+		return domain.ConfigurationWatch{}, err
+	}
+
+	w.ConfigName = name
+	w.LastTotal, w.BaselineTotal, w.BaselinePrices = 0, 0, map[int]float64{}
+	if total, ok := priceTotal(items); ok {
+		w.LastTotal, w.BaselineTotal, w.BaselinePrices = total, total, itemPrices(items)
+	}
+	if w.CreatedAt, w.UpdatedAt, err = uc.repo.SaveConfigurationWatch(ctx, w); err != nil {
+		return domain.ConfigurationWatch{}, err
+	}
+	return w, nil
+}
+
+func (uc *notificationUseCase) ListConfigurationWatches(ctx context.Context, userID uuid.UUID) ([]domain.ConfigurationWatch, error) {
+	return uc.repo.ListConfigurationWatches(ctx, userID)
+}
+
+func (uc *notificationUseCase) UnwatchConfiguration(ctx context.Context, userID uuid.UUID, configID int) error {
+	return uc.repo.DeleteConfigurationWatch(ctx, userID, configID)
+}
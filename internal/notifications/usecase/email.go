@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"StartupPCConfigurator/internal/domain"
+	"StartupPCConfigurator/internal/notifications/email"
+	"StartupPCConfigurator/internal/notifications/repository"
+)
+
+// ErrInvalidEmailMode — неизвестный режим email-уведомлений
+var ErrInvalidEmailMode = errors.New("invalid email mode")
+
+func (uc *notificationUseCase) GetEmailSettings(ctx context.Context, userID uuid.UUID) (domain.EmailSettings, error) {
+	return uc.repo.GetEmailSettings(ctx, userID)
+}
+
+// UpdateEmailSettings переключает режим писем: off, instant, daily, weekly
+func (uc *notificationUseCase) UpdateEmailSettings(ctx context.Context, userID uuid.UUID, mode string) (domain.EmailSettings, error) {
+	switch mode = strings.ToLower(strings.TrimSpace(mode)); mode {
+	case domain.EmailModeOff, domain.EmailModeInstant, domain.EmailModeDaily, domain.EmailModeWeekly:
+	default:
+		return domain.EmailSettings{}, fmt.Errorf("%w: mode must be one of off, instant, daily, weekly", ErrInvalidEmailMode)
+	}
+	return uc.repo.SaveEmailSettings(ctx, userID, mode)
+}
+
+// Параметры рассылки
+const (
+	emailUsersBatch = 20
+	emailInterval   = time.Minute
+)
+
+// EmailDispatcher отправляет письма по уведомлениям: instant — сразу (с
+// задержкой до минуты), daily/weekly — сводкой, когда подошёл срок
+type EmailDispatcher struct {
+	repo   repository.NotificationRepository
+	sender email.Sender
+	links  *email.Links
+	logger *log.Logger
+}
+
+func NewEmailDispatcher(repo repository.NotificationRepository, sender email.Sender, links *email.Links, logger *log.Logger) *EmailDispatcher {
+	return &EmailDispatcher{repo: repo, sender: sender, links: links, logger: logger}
+}
+
+// Run рассылает письма, пока не отменён ctx
+func (d *EmailDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(emailInterval)
+	defer ticker.Stop()
+	for {
+		// полная пачка пользователей — возможно, есть ещё, берём следующую сразу
+		for {
+			n, err := d.repo.DeliverEmails(ctx, emailUsersBatch, func(b domain.EmailBatch) error {
+				msg, err := email.Render(b, d.links)
+				if errors.Is(err, email.ErrNothingToSend) {
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+				return d.sender.Send(ctx, msg)
+			})
+			if err != nil && ctx.Err() == nil {
+				d.logger.Printf("email delivery: %d sent, failed: %v", n, err)
+			}
+			if err != nil || n < emailUsersBatch {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
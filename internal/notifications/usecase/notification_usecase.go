@@ -16,6 +16,9 @@ type NotificationUseCase interface {
 	// Обработка события изменения цены
 	HandlePriceChange(ctx context.Context, msg usecase.PriceChangedMsg) error
 
+	// Обработка события появления или окончания товара
+	HandleAvailabilityChange(ctx context.Context, msg usecase.AvailabilityChangedMsg) error
+
 	// Получение числа непрочитанных уведомлений для пользователя
 	GetUnreadCount(ctx context.Context, userID uuid.UUID) (int, error)
 
@@ -25,18 +28,36 @@ type NotificationUseCase interface {
 	// Отметить уведомление как прочитанное
 	MarkAsRead(ctx context.Context, userID, notifID uuid.UUID) error
 
-	Subscribe(ctx context.Context, userID uuid.UUID, componentID string) error
+	// Subscribe создаёт подписку или меняет её условия
+	Subscribe(ctx context.Context, sub domain.Subscription) (domain.Subscription, error)
+
+	ListSubscriptions(ctx context.Context, userID uuid.UUID) ([]domain.Subscription, error)
 
 	Unsubscribe(ctx context.Context, userID uuid.UUID, componentID string) error
 
 	CheckSubscribed(ctx context.Context,
 		userID uuid.UUID, ids []string) (map[string]bool, error)
+
+	// WatchConfiguration начинает слежение за стоимостью сборки или меняет его условия
+	WatchConfiguration(ctx context.Context, w domain.ConfigurationWatch) (domain.ConfigurationWatch, error)
+
+	ListConfigurationWatches(ctx context.Context, userID uuid.UUID) ([]domain.ConfigurationWatch, error)
+
+	UnwatchConfiguration(ctx context.Context, userID uuid.UUID, configID int) error
+
+	// EventsSince — события потока после lastID (Last-Event-ID)
+	EventsSince(ctx context.Context, userID uuid.UUID, lastID string) ([]domain.NotificationEvent, error)
+
+	// настройки email-канала
+	GetEmailSettings(ctx context.Context, userID uuid.UUID) (domain.EmailSettings, error)
+	UpdateEmailSettings(ctx context.Context, userID uuid.UUID, mode string) (domain.EmailSettings, error)
 }
 
 // notificationUseCase реализует NotificationUseCase
 type notificationUseCase struct {
 	repo   repository.NotificationRepository
 	cache  repository.NotificationCache
+	events repository.NotificationEvents
 	logger *log.Logger
 }
 
@@ -44,36 +65,109 @@ type notificationUseCase struct {
 func NewNotificationUseCase(
 	repo repository.NotificationRepository,
 	cache repository.NotificationCache,
+	events repository.NotificationEvents,
 	logger *log.Logger,
 ) NotificationUseCase {
-	return &notificationUseCase{repo: repo, cache: cache, logger: logger}
+	return &notificationUseCase{repo: repo, cache: cache, events: events, logger: logger}
 }
 
-// HandlePriceChange сохраняет уведомления о смене цены и увеличивает кеш-счётчик
+// priceChangedConsumer — имя получателя в processed_events
+const priceChangedConsumer = "notifications.price_changed"
+
+// HandlePriceChange пересчитывает стоимость сборок с этим компонентом, за
+// которыми следят пользователи, и сохраняет уведомления о смене цены тем
+// подписчикам, чьи условия выполнены. Повторно доставленное событие (тот же
+// EventID) уведомлений не создаёт; любая ошибка возвращает событие на повтор.
 func (uc *notificationUseCase) HandlePriceChange(ctx context.Context, msg usecase.PriceChangedMsg) error {
-	users, err := uc.repo.GetSubscribers(ctx, msg.ComponentID)
+	// пересчёт сборок идёт от сохранённого состояния и при повторе ничего не
+	// дублирует, поэтому processed_events ему не нужен
+	if err := uc.handleConfigurationWatches(ctx, msg.ComponentID, msg.ShopID); err != nil {
+		uc.logger.Printf("configuration watches: %v", err)
+		return err
+	}
+
+	subs, err := uc.repo.GetSubscriptions(ctx, msg.ComponentID)
 	if err != nil {
-		uc.logger.Printf("GetSubscribers error: %v", err)
+		uc.logger.Printf("GetSubscriptions error: %v", err)
 		return err
 	}
-	for _, userID := range users {
-		notif := domain.Notification{
+	var notifs []domain.Notification
+	for _, sub := range subs {
+		// целевая цена, порог изменения, направление, магазин
+		if !sub.Matches(msg.ShopID, msg.OldPrice, msg.NewPrice) {
+			continue
+		}
+		notifs = append(notifs, domain.Notification{
 			ID:          uuid.New(),
-			UserID:      userID,
+			UserID:      sub.UserID,
+			Type:        domain.NotificationPriceChanged,
 			ComponentID: msg.ComponentID,
 			ShopID:      msg.ShopID,
 			OldPrice:    msg.OldPrice,
 			NewPrice:    msg.NewPrice,
 			IsRead:      false,
 			CreatedAt:   time.Now(),
-		}
-		if err := uc.repo.CreateNotification(ctx, notif); err != nil {
-			uc.logger.Printf("CreateNotification error: %v", err)
+		})
+	}
+	return uc.saveEventNotifications(ctx, priceChangedConsumer, "price.changed", msg.EventID, notifs)
+}
+
+// availabilityChangedConsumer — имя получателя в processed_events
+const availabilityChangedConsumer = "notifications.availability_changed"
+
+// HandleAvailabilityChange пересчитывает стоимость сборок с компонентом и
+// уведомляет подписчиков на наличие: товар снова в продаже или закончился.
+// Повторно доставленное событие уведомлений о наличии не создаёт.
+func (uc *notificationUseCase) HandleAvailabilityChange(ctx context.Context, msg usecase.AvailabilityChangedMsg) error {
+	if err := uc.handleConfigurationWatches(ctx, msg.ComponentID, msg.ShopID); err != nil {
+		uc.logger.Printf("configuration watches: %v", err)
+		return err
+	}
+
+	subs, err := uc.repo.GetSubscriptions(ctx, msg.ComponentID)
+	if err != nil {
+		uc.logger.Printf("GetSubscriptions error: %v", err)
+		return err
+	}
+	kind := domain.NotificationOutOfStock
+	if msg.InStock {
+		kind = domain.NotificationBackInStock
+	}
+	var notifs []domain.Notification
+	for _, sub := range subs {
+		if !sub.MatchesStock(msg.ShopID, msg.InStock) {
 			continue
 		}
-		if err := uc.cache.IncrementUnread(ctx, userID); err != nil {
-			uc.logger.Printf("IncrementUnread error: %v", err)
-		}
+		notifs = append(notifs, domain.Notification{
+			ID:           uuid.New(),
+			UserID:       sub.UserID,
+			Type:         kind,
+			ComponentID:  msg.ComponentID,
+			ShopID:       msg.ShopID,
+			OldPrice:     msg.Price,
+			NewPrice:     msg.Price,
+			Availability: msg.NewStatus,
+			CreatedAt:    time.Now(),
+		})
+	}
+	return uc.saveEventNotifications(ctx, availabilityChangedConsumer, "availability.changed", msg.EventID, notifs)
+}
+
+// saveEventNotifications сохраняет уведомления по событию вместе с отметкой
+// в processed_events одной транзакцией и только после коммита обновляет
+// счётчики и поток клиентов
+func (uc *notificationUseCase) saveEventNotifications(ctx context.Context, consumer, event, eventID string, notifs []domain.Notification) error {
+	saved, err := uc.repo.SaveEventNotifications(ctx, consumer, eventID, notifs)
+	if err != nil {
+		uc.logger.Printf("%s %s: save notifications: %v", event, eventID, err)
+		return err
+	}
+	if !saved {
+		uc.logger.Printf("%s %s already processed, skipped", event, eventID)
+		return nil
+	}
+	for _, n := range notifs {
+		uc.delivered(ctx, n)
 	}
 	return nil
 }
@@ -113,12 +207,26 @@ func (uc *notificationUseCase) MarkAsRead(ctx context.Context, userID, notifID u
 	}
 	if err := uc.cache.ResetUnread(ctx, userID); err != nil {
 		uc.logger.Printf("ResetUnread error: %v", err)
+		return nil
 	}
+	uc.publish(ctx, userID, domain.NotificationEventUnread, map[string]int{"unread": 0})
 	return nil
 }
 
-func (uc *notificationUseCase) Subscribe(ctx context.Context, userID uuid.UUID, componentID string) error {
-	return uc.repo.Subscribe(ctx, userID, componentID)
+func (uc *notificationUseCase) Subscribe(ctx context.Context, sub domain.Subscription) (domain.Subscription, error) {
+	if err := validateSubscription(&sub); err != nil {
+		return domain.Subscription{}, err
+	}
+	createdAt, err := uc.repo.Subscribe(ctx, sub)
+	if err != nil {
+		return domain.Subscription{}, err
+	}
+	sub.CreatedAt = createdAt
+	return sub, nil
+}
+
+func (uc *notificationUseCase) ListSubscriptions(ctx context.Context, userID uuid.UUID) ([]domain.Subscription, error) {
+	return uc.repo.ListSubscriptions(ctx, userID)
 }
 func (uc *notificationUseCase) Unsubscribe(ctx context.Context, userID uuid.UUID, componentID string) error {
 	return uc.repo.Unsubscribe(ctx, userID, componentID)
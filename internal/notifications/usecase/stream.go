@@ -0,0 +1,42 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+
+	"StartupPCConfigurator/internal/domain"
+)
+
+// delivered вызывается после сохранения уведомления: увеличивает счётчик
+// непрочитанных и отправляет уведомление и новый счётчик в поток клиента.
+// Ошибки только логируются — уведомление уже в БД.
+func (uc *notificationUseCase) delivered(ctx context.Context, n domain.Notification) {
+	cnt, err := uc.cache.IncrementUnread(ctx, n.UserID)
+	if err != nil {
+		uc.logger.Printf("IncrementUnread error: %v", err)
+	}
+	uc.publish(ctx, n.UserID, domain.NotificationEventCreated, n)
+	if err == nil {
+		uc.publish(ctx, n.UserID, domain.NotificationEventUnread, map[string]int{"unread": cnt})
+	}
+}
+
+func (uc *notificationUseCase) publish(ctx context.Context, userID uuid.UUID, kind string, data interface{}) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		uc.logger.Printf("notification event %s: %v", kind, err)
+		return
+	}
+	ev := domain.NotificationEvent{UserID: userID, Type: kind, Data: payload}
+	if _, err := uc.events.Publish(ctx, ev); err != nil {
+		uc.logger.Printf("publish notification event %s: %v", kind, err)
+	}
+}
+
+// EventsSince возвращает события пользователя после lastID — для
+// возобновления потока по Last-Event-ID
+func (uc *notificationUseCase) EventsSince(ctx context.Context, userID uuid.UUID, lastID string) ([]domain.NotificationEvent, error) {
+	return uc.events.Since(ctx, userID, lastID)
+}
@@ -0,0 +1,45 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"StartupPCConfigurator/internal/domain"
+)
+
+// ErrInvalidSubscription — некорректные условия подписки
+var ErrInvalidSubscription = errors.New("invalid subscription")
+
+// validateSubscription проверяет условия и проставляет значения по умолчанию
+func validateSubscription(s *domain.Subscription) error {
+	s.ComponentID = strings.TrimSpace(s.ComponentID)
+	if s.ComponentID == "" {
+		return fmt.Errorf("%w: componentId is required", ErrInvalidSubscription)
+	}
+	switch s.Direction = strings.ToLower(strings.TrimSpace(s.Direction)); s.Direction {
+	case "":
+		s.Direction = domain.DirectionDrop
+	case domain.DirectionDrop, domain.DirectionAny:
+	default:
+		return fmt.Errorf("%w: direction must be %q or %q", ErrInvalidSubscription, domain.DirectionDrop, domain.DirectionAny)
+	}
+	if s.TargetPrice != nil && *s.TargetPrice <= 0 {
+		return fmt.Errorf("%w: targetPrice must be positive", ErrInvalidSubscription)
+	}
+	if s.MinDropPercent != nil && (*s.MinDropPercent <= 0 || *s.MinDropPercent >= 100) {
+		return fmt.Errorf("%w: minDropPercent must be between 0 and 100", ErrInvalidSubscription)
+	}
+	switch s.StockAlert = strings.ToLower(strings.TrimSpace(s.StockAlert)); s.StockAlert {
+	case "", domain.StockAlertBackInStock, domain.StockAlertAny:
+	default:
+		return fmt.Errorf("%w: stockAlert must be %q or %q", ErrInvalidSubscription, domain.StockAlertBackInStock, domain.StockAlertAny)
+	}
+	if !s.PriceAlerts && s.StockAlert == "" {
+		return fmt.Errorf("%w: enable priceAlerts or stockAlert", ErrInvalidSubscription)
+	}
+	if s.ShopID != nil && *s.ShopID <= 0 {
+		return fmt.Errorf("%w: shopId must be positive", ErrInvalidSubscription)
+	}
+	return nil
+}
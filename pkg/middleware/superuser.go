@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequireSuperuser пропускает только суперпользователей. Ставится после
+// AuthMiddleware; isSuperuser проверяет флаг users.is_superuser.
+func RequireSuperuser(isSuperuser func(uuid.UUID) (bool, error)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		raw, exists := c.Get("user_id")
+		userID, ok := raw.(uuid.UUID)
+		if !exists || !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		allowed, err := isSuperuser(userID)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to check permissions"})
+			return
+		}
+		if !allowed {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "superuser only"})
+			return
+		}
+
+		c.Next()
+	}
+}
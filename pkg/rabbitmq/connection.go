@@ -0,0 +1,208 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+// Задержки переподключения к брокеру
+const (
+	reconnectMinDelay = time.Second
+	reconnectMaxDelay = 30 * time.Second
+)
+
+// ErrClosed — соединение закрыто через Close
+var ErrClosed = errors.New("rabbitmq: connection closed")
+
+// Topology объявляет обменники, очереди и привязки; вызывается после каждого подключения
+type Topology func(ch *amqp.Channel) error
+
+// Conn — соединение с брокером, которое само восстанавливается после обрыва
+// и заново объявляет зарегистрированную топологию
+type Conn struct {
+	url    string
+	logger *log.Logger
+
+	mu       sync.Mutex
+	conn     *amqp.Connection // nil, пока идёт переподключение
+	ready    chan struct{}    // закрывается, когда conn готов
+	topology []Topology
+	closed   bool
+}
+
+// Dial подключается к брокеру, повторяя попытки, пока не отменён ctx, и
+// дальше держит соединение: после обрыва переподключается до отмены ctx или Close.
+// Закрыть соединение нужно самому через Close.
+func Dial(ctx context.Context, url string, logger *log.Logger, topology ...Topology) (*Conn, error) {
+	if logger == nil {
+		logger = log.Default()
+	}
+	c := &Conn{url: url, logger: logger, ready: make(chan struct{}), topology: topology}
+	conn, err := c.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	go c.watch(ctx, conn)
+	return c, nil
+}
+
+// Channel открывает канал, при необходимости дожидаясь переподключения
+func (c *Conn) Channel(ctx context.Context) (*amqp.Channel, error) {
+	for {
+		c.mu.Lock()
+		conn, ready, closed := c.conn, c.ready, c.closed
+		c.mu.Unlock()
+		if closed {
+			return nil, ErrClosed
+		}
+		if conn != nil {
+			ch, err := conn.Channel()
+			if err == nil {
+				return ch, nil
+			}
+			if !conn.IsClosed() {
+				return nil, err
+			}
+			// соединение только что оборвалось — watch вот-вот начнёт переподключение
+			ready = nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ready:
+		case <-time.After(reconnectMinDelay):
+		}
+	}
+}
+
+// Close закрывает соединение и останавливает переподключение
+func (c *Conn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	if c.conn != nil {
+		return c.conn.Close()
+	}
+	return nil
+}
+
+// connect дозванивается до брокера с нарастающей задержкой и объявляет топологию
+func (c *Conn) connect(ctx context.Context) (*amqp.Connection, error) {
+	delay := reconnectMinDelay
+	for {
+		conn, err := amqp.Dial(c.url)
+		if err == nil {
+			if err = c.declare(conn); err == nil {
+				c.mu.Lock()
+				if c.closed {
+					c.mu.Unlock()
+					conn.Close()
+					return nil, ErrClosed
+				}
+				c.conn = conn
+				close(c.ready)
+				c.mu.Unlock()
+				c.logger.Println("rabbitmq: connected")
+				return conn, nil
+			}
+			conn.Close()
+		}
+		c.logger.Printf("rabbitmq: connect failed, retry in %s: %v", delay, err)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > reconnectMaxDelay {
+			delay = reconnectMaxDelay
+		}
+	}
+}
+
+func (c *Conn) declare(conn *amqp.Connection) error {
+	if len(c.topology) == 0 {
+		return nil
+	}
+	ch, err := conn.Channel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+	for _, declare := range c.topology {
+		if err := declare(ch); err != nil {
+			return fmt.Errorf("declare topology: %w", err)
+		}
+	}
+	return nil
+}
+
+// watch ждёт обрыва соединения и переподключается
+func (c *Conn) watch(ctx context.Context, conn *amqp.Connection) {
+	for {
+		closes := conn.NotifyClose(make(chan *amqp.Error, 1))
+		select {
+		case <-ctx.Done():
+			// соединение закрывает владелец через Close: потребителям ещё нужно подтвердить начатое
+			return
+		case err := <-closes:
+			c.mu.Lock()
+			if c.closed {
+				c.mu.Unlock()
+				return
+			}
+			c.conn = nil
+			c.ready = make(chan struct{})
+			c.mu.Unlock()
+			c.logger.Printf("rabbitmq: connection lost, reconnecting: %v", err)
+
+			var cerr error
+			if conn, cerr = c.connect(ctx); cerr != nil {
+				return
+			}
+		}
+	}
+}
+
+// Run держит потребителя запущенным: после обрыва соединения или канала
+// открывает новый канал, заново объявляет очереди и продолжает чтение.
+// Возвращается, когда отменён ctx или соединение закрыто.
+func Run(ctx context.Context, c *Conn, cfg ConsumerConfig, h Handler) {
+	cfg.setDefaults()
+	delay := reconnectMinDelay
+	for {
+		ch, err := c.Channel(ctx)
+		if ctx.Err() != nil || errors.Is(err, ErrClosed) {
+			return
+		}
+		started := time.Now()
+		if err == nil {
+			err = Consume(ctx, ch, cfg, h)
+			ch.Close()
+			if ctx.Err() != nil {
+				return
+			}
+		}
+		// долго проработавший потребитель перезапускаем сразу
+		if time.Since(started) > reconnectMaxDelay {
+			delay = reconnectMinDelay
+		}
+		cfg.Logger.Printf("rabbitmq: %s consumer interrupted, restart in %s: %v", cfg.Queue, delay, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > reconnectMaxDelay {
+			delay = reconnectMaxDelay
+		}
+	}
+}
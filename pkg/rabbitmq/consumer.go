@@ -0,0 +1,292 @@
+// Package rabbitmq — общий рантайм потребителей RabbitMQ для сервисов:
+// ручные ack, повторы с задержкой, dead-letter очередь и мягкая остановка.
+package rabbitmq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+// Заголовки, которые рантайм добавляет к сообщениям
+const (
+	HeaderRetryCount = "x-retry-count" // сколько раз обработка уже падала
+	HeaderLastError  = "x-last-error"  // текст последней ошибки
+	HeaderFailedAt   = "x-failed-at"   // когда сообщение ушло в DLQ
+)
+
+// ErrDeliveriesClosed — канал доставки закрылся: обычно соединение с брокером
+// потеряно, потребителя нужно запустить заново
+var ErrDeliveriesClosed = errors.New("rabbitmq: deliveries channel closed")
+
+// Handler обрабатывает одно сообщение. nil — ack; ошибка — повтор или,
+// для Permanent, сразу dead-letter очередь.
+type Handler func(ctx context.Context, d amqp.Delivery) error
+
+// permanentError — ошибка, которую повтор не исправит (битое сообщение и т.п.)
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent помечает ошибку как неисправимую: сообщение уходит в DLQ без повторов
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentError{err}
+}
+
+// IsPermanent — ошибка помечена через Permanent
+func IsPermanent(err error) bool {
+	var p permanentError
+	return errors.As(err, &p)
+}
+
+// JSON оборачивает обработчик типизированного сообщения. Тело, которое не
+// разбирается, сразу уходит в DLQ.
+func JSON[T any](fn func(ctx context.Context, msg T) error) Handler {
+	return func(ctx context.Context, d amqp.Delivery) error {
+		var msg T
+		if err := json.Unmarshal(d.Body, &msg); err != nil {
+			return Permanent(fmt.Errorf("decode message: %w", err))
+		}
+		return fn(ctx, msg)
+	}
+}
+
+// ConsumerConfig — настройки потребителя. Пустые поля получают значения по умолчанию.
+type ConsumerConfig struct {
+	Queue string // очередь (объявляется durable, без аргументов — как раньше)
+
+	// Exchange и RoutingKey — если заданы, очередь привязывается к обменнику
+	Exchange   string
+	RoutingKey string
+
+	Prefetch   int           // сколько сообщений обрабатывается одновременно; по умолчанию 1
+	MaxRetries int           // повторов до DLQ; по умолчанию 5, отрицательное — без повторов
+	MinBackoff time.Duration // задержка первого повтора; по умолчанию 1s
+	MaxBackoff time.Duration // потолок задержки; по умолчанию 1m
+
+	DeadLetterQueue string // по умолчанию Queue + ".dlq"
+	Logger          *log.Logger
+}
+
+func (c *ConsumerConfig) setDefaults() {
+	if c.Prefetch <= 0 {
+		c.Prefetch = 1
+	}
+	if c.MaxRetries == 0 {
+		c.MaxRetries = 5
+	}
+	if c.MinBackoff <= 0 {
+		c.MinBackoff = time.Second
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = time.Minute
+	}
+	if c.MaxBackoff < c.MinBackoff {
+		c.MaxBackoff = c.MinBackoff
+	}
+	if c.DeadLetterQueue == "" {
+		c.DeadLetterQueue = c.Queue + ".dlq"
+	}
+	if c.Logger == nil {
+		c.Logger = log.Default()
+	}
+}
+
+// retryQueue — очередь ожидания повторов: сообщение лежит в ней свой TTL
+// и по истечении возвращается в основную очередь
+func (c *ConsumerConfig) retryQueue() string { return c.Queue + ".retry" }
+
+// backoff — задержка перед повтором номер attempt (с 1): удваивается до MaxBackoff
+func (c *ConsumerConfig) backoff(attempt int) time.Duration {
+	d := c.MinBackoff
+	for i := 1; i < attempt && d < c.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > c.MaxBackoff {
+		d = c.MaxBackoff
+	}
+	return d
+}
+
+// Declare объявляет основную очередь, очередь повторов и DLQ
+func Declare(ch *amqp.Channel, cfg ConsumerConfig) error {
+	cfg.setDefaults()
+	if _, err := ch.QueueDeclare(cfg.Queue, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("declare %s: %w", cfg.Queue, err)
+	}
+	if cfg.Exchange != "" {
+		key := cfg.RoutingKey
+		if key == "" {
+			key = cfg.Queue
+		}
+		if err := ch.QueueBind(cfg.Queue, key, cfg.Exchange, false, nil); err != nil {
+			return fmt.Errorf("bind %s to %s: %w", cfg.Queue, cfg.Exchange, err)
+		}
+	}
+	if _, err := ch.QueueDeclare(cfg.retryQueue(), true, false, false, false, amqp.Table{
+		"x-dead-letter-exchange":    "",
+		"x-dead-letter-routing-key": cfg.Queue,
+	}); err != nil {
+		return fmt.Errorf("declare %s: %w", cfg.retryQueue(), err)
+	}
+	if _, err := ch.QueueDeclare(cfg.DeadLetterQueue, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("declare %s: %w", cfg.DeadLetterQueue, err)
+	}
+	return nil
+}
+
+// Consume объявляет очереди и обрабатывает сообщения, пока не отменён ctx
+// или не закрылся канал. На отмену ctx перестаёт брать новые сообщения и
+// дожидается уже начатых: обработчики получают контекст без отмены, чтобы
+// не бросать работу на середине. При штатной остановке возвращает nil.
+func Consume(ctx context.Context, ch *amqp.Channel, cfg ConsumerConfig, h Handler) error {
+	cfg.setDefaults()
+	if err := Declare(ch, cfg); err != nil {
+		return err
+	}
+	if err := ch.Qos(cfg.Prefetch, 0, false); err != nil {
+		return fmt.Errorf("qos: %w", err)
+	}
+	tag := fmt.Sprintf("%s-%d", cfg.Queue, time.Now().UnixNano())
+	deliveries, err := ch.Consume(cfg.Queue, tag, false, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("consume %s: %w", cfg.Queue, err)
+	}
+	cfg.Logger.Printf("rabbitmq: consuming %s (prefetch %d, retries %d)", cfg.Queue, cfg.Prefetch, cfg.MaxRetries)
+
+	c := &consumer{ch: ch, cfg: cfg, handler: h, ctx: context.WithoutCancel(ctx)}
+	var (
+		wg     sync.WaitGroup
+		closed = make(chan struct{}, cfg.Prefetch)
+	)
+	for i := 0; i < cfg.Prefetch; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for d := range deliveries {
+				c.handle(d)
+			}
+			closed <- struct{}{}
+		}()
+	}
+
+	select {
+	case <-ctx.Done():
+		// брокер перестаёт слать новые; неподтверждённые из буфера вернутся в очередь
+		if err := ch.Cancel(tag, false); err != nil {
+			cfg.Logger.Printf("rabbitmq: cancel %s: %v", cfg.Queue, err)
+		}
+		wg.Wait()
+		cfg.Logger.Printf("rabbitmq: %s consumer stopped", cfg.Queue)
+		return nil
+	case <-closed:
+		wg.Wait()
+		if ctx.Err() != nil {
+			return nil
+		}
+		return fmt.Errorf("%s: %w", cfg.Queue, ErrDeliveriesClosed)
+	}
+}
+
+type consumer struct {
+	ch      *amqp.Channel
+	cfg     ConsumerConfig
+	handler Handler
+	ctx     context.Context
+}
+
+func (c *consumer) handle(d amqp.Delivery) {
+	err := c.safeHandle(d)
+	if err == nil {
+		c.ack(d)
+		return
+	}
+
+	attempt := retryCount(d) + 1
+	if IsPermanent(err) || c.cfg.MaxRetries < 0 || attempt > c.cfg.MaxRetries {
+		c.cfg.Logger.Printf("rabbitmq: %s: giving up after %d attempt(s): %v", c.cfg.Queue, attempt, err)
+		c.forward(d, c.cfg.DeadLetterQueue, "", attempt-1, err)
+		return
+	}
+	delay := c.cfg.backoff(attempt)
+	c.cfg.Logger.Printf("rabbitmq: %s: attempt %d failed, retry in %s: %v", c.cfg.Queue, attempt, delay, err)
+	c.forward(d, c.cfg.retryQueue(), strconv.FormatInt(delay.Milliseconds(), 10), attempt, err)
+}
+
+// safeHandle не даёт панике в обработчике уронить воркер
+func (c *consumer) safeHandle(d amqp.Delivery) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = Permanent(fmt.Errorf("panic: %v", r))
+		}
+	}()
+	return c.handler(c.ctx, d)
+}
+
+// forward публикует копию сообщения в очередь повторов или DLQ и подтверждает
+// оригинал. Если публикация не удалась, оригинал возвращается в очередь.
+func (c *consumer) forward(d amqp.Delivery, queue, expiration string, retries int, cause error) {
+	headers := amqp.Table{}
+	for k, v := range d.Headers {
+		headers[k] = v
+	}
+	headers[HeaderRetryCount] = int32(retries)
+	headers[HeaderLastError] = cause.Error()
+	if queue == c.cfg.DeadLetterQueue {
+		headers[HeaderFailedAt] = time.Now().UTC().Format(time.RFC3339)
+	}
+
+	err := c.ch.Publish("", queue, false, false, amqp.Publishing{
+		Headers:       headers,
+		ContentType:   d.ContentType,
+		Body:          d.Body,
+		DeliveryMode:  amqp.Persistent,
+		MessageId:     d.MessageId,
+		CorrelationId: d.CorrelationId,
+		Timestamp:     d.Timestamp,
+		Type:          d.Type,
+		Expiration:    expiration,
+	})
+	if err != nil {
+		c.cfg.Logger.Printf("rabbitmq: %s: publish to %s: %v; requeue", c.cfg.Queue, queue, err)
+		if err := d.Nack(false, true); err != nil {
+			c.cfg.Logger.Printf("rabbitmq: %s: nack: %v", c.cfg.Queue, err)
+		}
+		return
+	}
+	c.ack(d)
+}
+
+func (c *consumer) ack(d amqp.Delivery) {
+	if err := d.Ack(false); err != nil {
+		c.cfg.Logger.Printf("rabbitmq: %s: ack: %v", c.cfg.Queue, err)
+	}
+}
+
+// retryCount читает x-retry-count; числа из заголовков приходят разных типов
+func retryCount(d amqp.Delivery) int {
+	switch v := d.Headers[HeaderRetryCount].(type) {
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	case int:
+		return v
+	case int16:
+		return int(v)
+	case int8:
+		return int(v)
+	}
+	return 0
+}
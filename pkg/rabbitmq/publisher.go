@@ -0,0 +1,188 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+// Ошибки публикации
+var (
+	ErrPublishBufferFull = errors.New("rabbitmq: publish buffer is full")
+	ErrPublishNacked     = errors.New("rabbitmq: broker rejected the message")
+	ErrPublishTimeout    = errors.New("rabbitmq: publish confirm timeout")
+)
+
+// PublisherConfig — настройки издателя. Пустые поля получают значения по умолчанию.
+type PublisherConfig struct {
+	Buffer  int           // сколько сообщений ждут отправки; по умолчанию 1000
+	Timeout time.Duration // сколько Publish ждёт подтверждения брокера; по умолчанию 10s
+	Logger  *log.Logger
+}
+
+// Publisher публикует сообщения в режиме publisher confirms: Publish
+// возвращает nil, только когда брокер принял сообщение. Пока соединения нет,
+// сообщения копятся в ограниченном буфере и уходят после переподключения.
+type Publisher struct {
+	conn   *Conn
+	cfg    PublisherConfig
+	queue  chan *outgoing
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+type outgoing struct {
+	exchange, key string
+	msg           amqp.Publishing
+	done          chan error // буфер 1: отправитель не ждёт, если Publish уже вышел по таймауту
+}
+
+// NewPublisher запускает издателя поверх соединения; остановить — Close
+func NewPublisher(conn *Conn, cfg PublisherConfig) *Publisher {
+	if cfg.Buffer <= 0 {
+		cfg.Buffer = 1000
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = log.Default()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Publisher{conn: conn, cfg: cfg, queue: make(chan *outgoing, cfg.Buffer), cancel: cancel, done: make(chan struct{})}
+	go func() {
+		defer close(p.done)
+		p.loop(ctx)
+	}()
+	return p
+}
+
+// Close останавливает издателя. Вызывать после потребителей, которые через
+// него публикуют: неотправленные сообщения получат ErrClosed.
+func (p *Publisher) Close() {
+	p.cancel()
+	<-p.done
+	for {
+		select {
+		case out := <-p.queue:
+			out.done <- ErrClosed
+		default:
+			return
+		}
+	}
+}
+
+// Publish ставит сообщение в буфер и ждёт подтверждения брокера. При таймауте
+// сообщение остаётся в буфере и может быть доставлено позже.
+func (p *Publisher) Publish(ctx context.Context, exchange, key string, msg amqp.Publishing) error {
+	out := &outgoing{exchange: exchange, key: key, msg: msg, done: make(chan error, 1)}
+	select {
+	case p.queue <- out:
+	default:
+		return ErrPublishBufferFull
+	}
+
+	timer := time.NewTimer(p.cfg.Timeout)
+	defer timer.Stop()
+	select {
+	case err := <-out.done:
+		return err
+	case <-timer.C:
+		return ErrPublishTimeout
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// loop отправляет сообщения по одному и ждёт подтверждения каждого;
+// при обрыве канала открывает новый и повторяет то же сообщение
+func (p *Publisher) loop(ctx context.Context) {
+	var (
+		ch       *amqp.Channel
+		confirms chan amqp.Confirmation
+		pending  *outgoing
+	)
+	defer func() {
+		if ch != nil {
+			ch.Close()
+		}
+	}()
+
+	for {
+		if pending == nil {
+			select {
+			case <-ctx.Done():
+				return
+			case pending = <-p.queue:
+			}
+		}
+		if ctx.Err() != nil {
+			pending.done <- ErrClosed
+			return
+		}
+
+		if ch == nil {
+			var err error
+			if ch, confirms, err = p.open(ctx); err != nil {
+				if ctx.Err() != nil || errors.Is(err, ErrClosed) {
+					pending.done <- ErrClosed
+					return
+				}
+				p.cfg.Logger.Printf("rabbitmq: publisher channel: %v", err)
+				sleep(ctx, reconnectMinDelay)
+				continue
+			}
+		}
+
+		if err := ch.Publish(pending.exchange, pending.key, false, false, pending.msg); err != nil {
+			p.cfg.Logger.Printf("rabbitmq: publish to %s/%s, retrying: %v", pending.exchange, pending.key, err)
+			ch.Close()
+			ch = nil
+			continue
+		}
+		var (
+			c  amqp.Confirmation
+			ok bool
+		)
+		select {
+		case c, ok = <-confirms:
+		case <-ctx.Done():
+			pending.done <- ErrClosed
+			return
+		}
+		if !ok {
+			// канал закрылся до подтверждения — отправим заново в новом
+			ch = nil
+			continue
+		}
+		if c.Ack {
+			pending.done <- nil
+		} else {
+			pending.done <- fmt.Errorf("%w: %s/%s", ErrPublishNacked, pending.exchange, pending.key)
+		}
+		pending = nil
+	}
+}
+
+func (p *Publisher) open(ctx context.Context) (*amqp.Channel, chan amqp.Confirmation, error) {
+	ch, err := p.conn.Channel(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := ch.Confirm(false); err != nil {
+		ch.Close()
+		return nil, nil, fmt.Errorf("confirm mode: %w", err)
+	}
+	return ch, ch.NotifyPublish(make(chan amqp.Confirmation, 1)), nil
+}
+
+func sleep(ctx context.Context, d time.Duration) {
+	select {
+	case <-ctx.Done():
+	case <-time.After(d):
+	}
+}